@@ -0,0 +1,434 @@
+package iot
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"github.com/Azure/go-amqp"
+	"net"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 开启ReceiverSettleSecond时，处理函数返回该错误的消息会被拒绝（rejected），平台不会再次投递；
+// 返回其他错误时消息会被释放（released）以便重新投递
+var ErrAmqpMessageRejected = errors.New("amqp message rejected by handler")
+
+type AmqpConsumerOptions struct {
+	// AMQP接入地址，例如：amqps://xxx.iot-amqps.cn-north-4.myhuaweicloud.com:5671，本地测试时可以使用amqp://127.0.0.1:5672
+	Address    string
+	AccessKey  string
+	AccessCode string
+	InstanceId string
+	QueueName  string
+
+	// 接收端授予平台的credit，也就是预取消息的最大数量，开启ReceiverSettleSecond时为未确认消息的最大数量
+	Credit uint32
+	// 并发处理消息的goroutine数量
+	Concurrency int
+
+	// 使用second模式确认消息，消息在处理函数返回后才被接受、拒绝或者释放，需要服务端支持该模式，否则无法建立链路。
+	// 默认使用first模式，消息在接收时即被接受，处理函数返回的错误只记录日志，消息不会重新投递
+	ReceiverSettleSecond bool
+
+	TLSConfig   *tls.Config
+	IdleTimeout time.Duration
+
+	// 连接断开后重连的初始等待时间和最大等待时间，等待时间按照指数增长
+	ReconnectDelay    time.Duration
+	MaxReconnectDelay time.Duration
+
+	// 自定义建立底层连接的方式，为空时根据Address的scheme使用TCP或者TLS建立连接
+	DialConn func(ctx context.Context, network, address string) (net.Conn, error)
+
+	OnConnected      func()
+	OnConnectionLost func(err error)
+
+	// 为空时不输出日志
+	Logger Logger
+}
+
+func NewAmqpConsumerOptions() *AmqpConsumerOptions {
+	return &AmqpConsumerOptions{
+		Credit:            100,
+		Concurrency:       1,
+		ReconnectDelay:    time.Second,
+		MaxReconnectDelay: 30 * time.Second,
+	}
+}
+
+func (o *AmqpConsumerOptions) SetAddress(address string) *AmqpConsumerOptions {
+	o.Address = address
+	return o
+}
+
+func (o *AmqpConsumerOptions) SetAccessCode(accessCode *CreateAccessCodeResponse) *AmqpConsumerOptions {
+	o.AccessKey = accessCode.AccessKey
+	o.AccessCode = accessCode.AccessCode
+	return o
+}
+
+func (o *AmqpConsumerOptions) SetInstanceId(instanceId string) *AmqpConsumerOptions {
+	o.InstanceId = instanceId
+	return o
+}
+
+func (o *AmqpConsumerOptions) SetQueueName(queueName string) *AmqpConsumerOptions {
+	o.QueueName = queueName
+	return o
+}
+
+func (o *AmqpConsumerOptions) SetCredit(credit uint32) *AmqpConsumerOptions {
+	o.Credit = credit
+	return o
+}
+
+func (o *AmqpConsumerOptions) SetConcurrency(concurrency int) *AmqpConsumerOptions {
+	o.Concurrency = concurrency
+	return o
+}
+
+func (o *AmqpConsumerOptions) SetReceiverSettleSecond(settleSecond bool) *AmqpConsumerOptions {
+	o.ReceiverSettleSecond = settleSecond
+	return o
+}
+
+func (o *AmqpConsumerOptions) SetLogger(logger Logger) *AmqpConsumerOptions {
+	o.Logger = logger
+	return o
+}
+
+// 平台推送的AMQP消息
+type AmqpMessage struct {
+	MessageID             string
+	CorrelationID         string
+	Subject               string
+	ContentType           string
+	CreationTime          time.Time
+	ApplicationProperties map[string]interface{}
+	Body                  []byte
+}
+
+type AmqpMessageHandler func(ctx context.Context, message *AmqpMessage) error
+
+type AmqpConsumer struct {
+	options AmqpConsumerOptions
+	handler AmqpMessageHandler
+
+	lock   sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewAmqpConsumer(options AmqpConsumerOptions, handler AmqpMessageHandler) (*AmqpConsumer, error) {
+	if len(options.Address) == 0 {
+		return nil, errors.New("amqp address is empty")
+	}
+	if len(options.AccessKey) == 0 || len(options.AccessCode) == 0 {
+		return nil, errors.New("amqp access key or access code is empty")
+	}
+	if len(options.QueueName) == 0 {
+		return nil, errors.New("amqp queue name is empty")
+	}
+	if handler == nil {
+		return nil, errors.New("amqp message handler is nil")
+	}
+
+	if options.Credit == 0 {
+		options.Credit = 100
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = 1
+	}
+	if options.ReconnectDelay <= 0 {
+		options.ReconnectDelay = time.Second
+	}
+	if options.MaxReconnectDelay < options.ReconnectDelay {
+		options.MaxReconnectDelay = options.ReconnectDelay
+	}
+
+	options.Logger = newRedactingLogger(options.Logger)
+
+	return &AmqpConsumer{
+		options: options,
+		handler: handler,
+	}, nil
+}
+
+// Run 连接平台并持续消费队列中的消息，连接断开后自动重连，直到ctx结束或者调用Close。
+func (c *AmqpConsumer) Run(ctx context.Context) error {
+	c.lock.Lock()
+	if c.done != nil {
+		c.lock.Unlock()
+		return errors.New("amqp consumer is already running")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	c.cancel = cancel
+	c.done = make(chan struct{})
+	done := c.done
+	c.lock.Unlock()
+
+	defer func() {
+		cancel()
+		close(done)
+		c.lock.Lock()
+		c.done = nil
+		c.lock.Unlock()
+	}()
+
+	delay := c.options.ReconnectDelay
+	for {
+		connected, err := c.consume(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if c.options.OnConnectionLost != nil {
+			c.options.OnConnectionLost(err)
+		}
+		c.options.Logger.Warn("amqp consumer disconnected", "queue", c.options.QueueName, "error", err)
+
+		if connected {
+			delay = c.options.ReconnectDelay
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+		if delay > c.options.MaxReconnectDelay {
+			delay = c.options.MaxReconnectDelay
+		}
+	}
+}
+
+// Close 停止消费并等待Run返回
+func (c *AmqpConsumer) Close() error {
+	c.lock.Lock()
+	cancel, done := c.cancel, c.done
+	c.lock.Unlock()
+
+	if cancel == nil || done == nil {
+		return nil
+	}
+
+	cancel()
+	<-done
+	return nil
+}
+
+func (c *AmqpConsumer) consume(ctx context.Context) (bool, error) {
+	client, err := c.dial(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return false, err
+	}
+
+	linkOptions := []amqp.LinkOption{
+		amqp.LinkSourceAddress(c.options.QueueName),
+		amqp.LinkCredit(c.options.Credit),
+	}
+	if c.options.ReceiverSettleSecond {
+		linkOptions = append(linkOptions, amqp.LinkReceiverSettle(amqp.ModeSecond))
+	}
+
+	receiver, err := session.NewReceiver(linkOptions...)
+	if err != nil {
+		return false, err
+	}
+
+	c.options.Logger.Info("amqp consumer connected", "queue", c.options.QueueName)
+	if c.options.OnConnected != nil {
+		c.options.OnConnected()
+	}
+
+	workerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make(chan error, c.options.Concurrency)
+	wg := sync.WaitGroup{}
+	for i := 0; i < c.options.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- c.receiveLoop(workerCtx, receiver)
+		}()
+	}
+
+	err = <-errs
+	cancel()
+	wg.Wait()
+
+	return true, err
+}
+
+func (c *AmqpConsumer) receiveLoop(ctx context.Context, receiver *amqp.Receiver) error {
+	for {
+		msg, err := receiver.Receive(ctx)
+		if err != nil {
+			return err
+		}
+
+		handleErr := c.handle(ctx, msg)
+
+		// first模式下消息在Receive时已经被接受
+		if !c.options.ReceiverSettleSecond {
+			if handleErr != nil {
+				c.options.Logger.Warn("handle amqp message failed", "queue", c.options.QueueName, "properties", msg.Properties, "error", handleErr)
+			}
+			continue
+		}
+
+		// 使用独立的ctx确认消息，避免消费停止时已经处理完成的消息得不到确认
+		settleCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		switch {
+		case handleErr == nil:
+			err = receiver.AcceptMessage(settleCtx, msg)
+		case errors.Is(handleErr, ErrAmqpMessageRejected):
+			err = receiver.RejectMessage(settleCtx, msg, &amqp.Error{
+				Condition:   amqp.ErrorCondition("amqp:internal-error"),
+				Description: handleErr.Error(),
+			})
+		default:
+			c.options.Logger.Warn("handle amqp message failed", "queue", c.options.QueueName, "properties", msg.Properties, "error", handleErr)
+			err = receiver.ReleaseMessage(settleCtx, msg)
+		}
+		cancel()
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (c *AmqpConsumer) handle(ctx context.Context, msg *amqp.Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("amqp message handler panic: %v", r)
+		}
+	}()
+
+	return c.handler(ctx, convertAmqpMessage(msg))
+}
+
+func (c *AmqpConsumer) dial(ctx context.Context) (*amqp.Client, error) {
+	address, err := url.Parse(c.options.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	useTLS := address.Scheme == "amqps"
+	host := address.Hostname()
+	port := address.Port()
+	if len(port) == 0 {
+		if useTLS {
+			port = "5671"
+		} else {
+			port = "5672"
+		}
+	}
+
+	dialConn := c.options.DialConn
+	if dialConn == nil {
+		dialConn = (&net.Dialer{Timeout: 30 * time.Second}).DialContext
+	}
+
+	conn, err := dialConn(ctx, "tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return nil, err
+	}
+
+	if useTLS {
+		tlsConfig := &tls.Config{}
+		if c.options.TLSConfig != nil {
+			tlsConfig = c.options.TLSConfig.Clone()
+		}
+		if len(tlsConfig.ServerName) == 0 {
+			tlsConfig.ServerName = host
+		}
+
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.Handshake(); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	opts := []amqp.ConnOption{
+		amqp.ConnServerHostname(host),
+		amqp.ConnSASLPlain(c.userName(), c.options.AccessCode),
+	}
+	if c.options.IdleTimeout > 0 {
+		opts = append(opts, amqp.ConnIdleTimeout(c.options.IdleTimeout))
+	}
+
+	client, err := amqp.New(conn, opts...)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// 平台要求的用户名格式：accessKey=${accessKey}|timestamp=${timestamp}|instanceId=${instanceId}
+func (c *AmqpConsumer) userName() string {
+	var buffer bytes.Buffer
+	buffer.WriteString("accessKey=")
+	buffer.WriteString(c.options.AccessKey)
+	buffer.WriteString("|timestamp=")
+	buffer.WriteString(strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10))
+	if len(c.options.InstanceId) != 0 {
+		buffer.WriteString("|instanceId=")
+		buffer.WriteString(c.options.InstanceId)
+	}
+
+	return buffer.String()
+}
+
+func convertAmqpMessage(msg *amqp.Message) *AmqpMessage {
+	message := &AmqpMessage{
+		ApplicationProperties: msg.ApplicationProperties,
+	}
+
+	if msg.Properties != nil {
+		if msg.Properties.MessageID != nil {
+			message.MessageID = fmt.Sprint(msg.Properties.MessageID)
+		}
+		if msg.Properties.CorrelationID != nil {
+			message.CorrelationID = fmt.Sprint(msg.Properties.CorrelationID)
+		}
+		message.Subject = msg.Properties.Subject
+		message.ContentType = msg.Properties.ContentType
+		message.CreationTime = msg.Properties.CreationTime
+	}
+
+	switch {
+	case len(msg.Data) > 0:
+		message.Body = bytes.Join(msg.Data, nil)
+	case msg.Value != nil:
+		switch value := msg.Value.(type) {
+		case []byte:
+			message.Body = value
+		case string:
+			message.Body = []byte(value)
+		default:
+			message.Body = []byte(fmt.Sprint(value))
+		}
+	}
+
+	return message
+}
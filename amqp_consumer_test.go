@@ -0,0 +1,1082 @@
+package iot
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// AMQP 1.0 performative和消息段的描述符
+const (
+	amqpSaslMechanisms = 0x40
+	amqpSaslInit       = 0x41
+	amqpSaslOutcome    = 0x44
+	amqpOpen           = 0x10
+	amqpBegin          = 0x11
+	amqpAttach         = 0x12
+	amqpFlow           = 0x13
+	amqpTransfer       = 0x14
+	amqpDisposition    = 0x15
+	amqpDetach         = 0x16
+	amqpEnd            = 0x17
+	amqpClose          = 0x18
+	amqpAccepted       = 0x24
+	amqpRejected       = 0x25
+	amqpReleased       = 0x26
+	amqpModified       = 0x27
+	amqpSource         = 0x28
+	amqpTarget         = 0x29
+	amqpError          = 0x1d
+	amqpProperties     = 0x73
+	amqpAppProperties  = 0x74
+	amqpData           = 0x75
+)
+
+var (
+	amqpSaslHeader = []byte("AMQP\x03\x01\x00\x00")
+	amqpHeader     = []byte("AMQP\x00\x01\x00\x00")
+)
+
+type testAmqpMessage struct {
+	id         string
+	properties map[string]string
+	body       []byte
+}
+
+type testAmqpDisposition struct {
+	messageId string
+	state     string
+	condition string
+	settled   bool
+}
+
+// 进程内的AMQP 1.0服务端，只实现消费者用到的部分：SASL PLAIN、open、begin、attach、flow、transfer和disposition
+type testAmqpBroker struct {
+	t *testing.T
+
+	messages     chan testAmqpMessage
+	dispositions chan testAmqpDisposition
+
+	// 是否支持rcv-settle-mode为second的链路，不支持时attach总是回复first
+	supportSettleSecond bool
+
+	lock sync.Mutex
+	// 前dialFailures次DialConn返回错误
+	dialFailures   int
+	dialTimes      []time.Time
+	conns          []net.Conn
+	userNames      []string
+	passwords      []string
+	sourceAddress  []string
+	rcvSettleModes []interface{}
+	linkCredits    []uint32
+}
+
+func newTestAmqpBroker(t *testing.T) *testAmqpBroker {
+	return &testAmqpBroker{
+		t:            t,
+		messages:     make(chan testAmqpMessage, 100),
+		dispositions: make(chan testAmqpDisposition, 100),
+	}
+}
+
+func (b *testAmqpBroker) dialConn(ctx context.Context, network, address string) (net.Conn, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.dialTimes = append(b.dialTimes, time.Now())
+	if b.dialFailures > 0 {
+		b.dialFailures--
+		return nil, errors.New("connection refused")
+	}
+
+	client, server := net.Pipe()
+	b.conns = append(b.conns, server)
+	go newTestAmqpConn(b, server).serve()
+
+	return client, nil
+}
+
+// 断开所有连接，返回断开的时间
+func (b *testAmqpBroker) dropConnections() time.Time {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := time.Now()
+	for _, conn := range b.conns {
+		_ = conn.Close()
+	}
+	b.conns = nil
+
+	return now
+}
+
+func (b *testAmqpBroker) publish(messages ...testAmqpMessage) {
+	for _, message := range messages {
+		b.messages <- message
+	}
+}
+
+func (b *testAmqpBroker) nextDisposition() testAmqpDisposition {
+	b.t.Helper()
+
+	select {
+	case disposition := <-b.dispositions:
+		return disposition
+	case <-time.After(5 * time.Second):
+		b.t.Fatal("timeout waiting for disposition")
+		return testAmqpDisposition{}
+	}
+}
+
+func (b *testAmqpBroker) snapshot() (dialTimes []time.Time, linkCredits []uint32) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return append([]time.Time(nil), b.dialTimes...), append([]uint32(nil), b.linkCredits...)
+}
+
+type testAmqpConn struct {
+	broker *testAmqpBroker
+	conn   net.Conn
+	out    chan []byte
+	closed chan struct{}
+
+	lock         sync.Mutex
+	settleSecond bool
+	handle       uint32
+	credit       uint32
+	sent         uint32
+	deliveries   map[uint32]string
+	creditChan   chan struct{}
+}
+
+func newTestAmqpConn(broker *testAmqpBroker, conn net.Conn) *testAmqpConn {
+	return &testAmqpConn{
+		broker:     broker,
+		conn:       conn,
+		out:        make(chan []byte, 1000),
+		closed:     make(chan struct{}),
+		deliveries: map[uint32]string{},
+		creditChan: make(chan struct{}, 1),
+	}
+}
+
+// 写入放在单独的goroutine中，net.Pipe没有缓冲，读写在同一个goroutine中可能与客户端互相等待
+func (c *testAmqpConn) writeLoop() {
+	for frame := range c.out {
+		if frame == nil {
+			break
+		}
+		if _, err := c.conn.Write(frame); err != nil {
+			break
+		}
+	}
+	_ = c.conn.Close()
+}
+
+func (c *testAmqpConn) send(frameType byte, channel uint16, body ...[]byte) {
+	select {
+	case <-c.closed:
+	case c.out <- encodeAmqpFrame(frameType, channel, bytes.Join(body, nil)):
+	}
+}
+
+func (c *testAmqpConn) serve() {
+	go c.writeLoop()
+	defer func() {
+		close(c.closed)
+		_ = c.conn.Close()
+	}()
+
+	if !c.expectHeader(amqpSaslHeader) {
+		return
+	}
+	c.out <- amqpSaslHeader
+	c.send(1, 0, amqpDescribed(amqpSaslMechanisms, amqpList(amqpSymbolArray("PLAIN"))))
+
+	_, _, init, err := c.readFrame()
+	if err != nil || init.code != amqpSaslInit {
+		return
+	}
+	response, _ := amqpField(init, 1).([]byte)
+	parts := strings.Split(string(response), "\x00")
+	if len(parts) == 3 {
+		c.broker.lock.Lock()
+		c.broker.userNames = append(c.broker.userNames, parts[1])
+		c.broker.passwords = append(c.broker.passwords, parts[2])
+		c.broker.lock.Unlock()
+	}
+	c.send(1, 0, amqpDescribed(amqpSaslOutcome, amqpList(amqpUbyte(0))))
+
+	if !c.expectHeader(amqpHeader) {
+		return
+	}
+	c.out <- amqpHeader
+
+	for {
+		_, channel, performative, err := c.readFrame()
+		if err != nil {
+			return
+		}
+		if performative == nil {
+			continue
+		}
+
+		switch performative.code {
+		case amqpOpen:
+			c.send(0, 0, amqpDescribed(amqpOpen, amqpList(amqpString("test-broker"), amqpNull(), amqpUint(65536), amqpUshort(65535))))
+		case amqpBegin:
+			c.send(0, channel, amqpDescribed(amqpBegin, amqpList(amqpUshort(channel), amqpUint(0), amqpUint(5000), amqpUint(5000), amqpUint(1024))))
+		case amqpAttach:
+			c.attach(channel, performative)
+		case amqpFlow:
+			c.flow(performative)
+		case amqpDisposition:
+			c.disposition(channel, performative)
+		case amqpDetach:
+			c.send(0, channel, amqpDescribed(amqpDetach, amqpList(amqpUint(amqpUintField(performative, 0)), amqpBool(true))))
+		case amqpEnd:
+			c.send(0, channel, amqpDescribed(amqpEnd, amqpList()))
+		case amqpClose:
+			c.send(0, 0, amqpDescribed(amqpClose, amqpList()))
+			c.out <- nil
+			return
+		}
+	}
+}
+
+func (c *testAmqpConn) expectHeader(expected []byte) bool {
+	header := make([]byte, len(expected))
+	if _, err := io.ReadFull(c.conn, header); err != nil {
+		return false
+	}
+	if !bytes.Equal(header, expected) {
+		c.broker.t.Errorf("amqp protocol header = %q, want %q", header, expected)
+		return false
+	}
+
+	return true
+}
+
+func (c *testAmqpConn) attach(channel uint16, attach *amqpDescribedValue) {
+	name, _ := amqpField(attach, 0).(string)
+	handle := amqpUintField(attach, 1)
+
+	var address string
+	if source, ok := amqpField(attach, 5).(*amqpDescribedValue); ok {
+		address, _ = amqpField(source, 0).(string)
+	}
+
+	c.broker.lock.Lock()
+	c.broker.sourceAddress = append(c.broker.sourceAddress, address)
+	c.broker.rcvSettleModes = append(c.broker.rcvSettleModes, amqpField(attach, 4))
+	c.broker.lock.Unlock()
+
+	rcvSettleMode := byte(0)
+	if mode, _ := amqpField(attach, 4).(uint64); mode == 1 && c.broker.supportSettleSecond {
+		rcvSettleMode = 1
+	}
+
+	c.lock.Lock()
+	c.handle = handle
+	c.settleSecond = rcvSettleMode == 1
+	c.lock.Unlock()
+
+	c.send(0, channel, amqpDescribed(amqpAttach, amqpList(
+		amqpString(name),
+		amqpUint(handle),
+		amqpBool(false),
+		amqpUbyte(0),
+		amqpUbyte(rcvSettleMode),
+		amqpDescribed(amqpSource, amqpList(amqpString(address))),
+		amqpDescribed(amqpTarget, amqpList()),
+		amqpNull(),
+		amqpBool(false),
+		amqpUint(0),
+	)))
+
+	go c.deliver(channel)
+}
+
+// 链路级别的flow更新可用的credit：delivery-count + link-credit - 已发送的消息数量
+func (c *testAmqpConn) flow(flow *amqpDescribedValue) {
+	if amqpField(flow, 4) == nil {
+		return
+	}
+	linkCredit := amqpUintField(flow, 6)
+
+	c.broker.lock.Lock()
+	c.broker.linkCredits = append(c.broker.linkCredits, linkCredit)
+	c.broker.lock.Unlock()
+
+	c.lock.Lock()
+	c.credit = amqpUintField(flow, 5) + linkCredit - c.sent
+	c.lock.Unlock()
+
+	select {
+	case c.creditChan <- struct{}{}:
+	default:
+	}
+}
+
+func (c *testAmqpConn) deliver(channel uint16) {
+	for {
+		c.lock.Lock()
+		credit := c.credit
+		c.lock.Unlock()
+
+		if credit == 0 {
+			select {
+			case <-c.creditChan:
+				continue
+			case <-c.closed:
+				return
+			}
+		}
+
+		select {
+		case <-c.creditChan:
+			continue
+		case <-c.closed:
+			return
+		case message := <-c.broker.messages:
+			c.lock.Lock()
+			deliveryId := c.sent
+			c.sent++
+			c.credit--
+			c.deliveries[deliveryId] = message.id
+			handle := c.handle
+			c.lock.Unlock()
+
+			var properties [][]byte
+			for key, value := range message.properties {
+				properties = append(properties, amqpString(key), amqpString(value))
+			}
+
+			c.send(0, channel,
+				amqpDescribed(amqpTransfer, amqpList(
+					amqpUint(handle),
+					amqpUint(deliveryId),
+					amqpBinary([]byte(fmt.Sprint(deliveryId))),
+					amqpUint(0),
+					amqpBool(false),
+				)),
+				amqpDescribed(amqpProperties, amqpList(amqpString(message.id))),
+				amqpDescribed(amqpAppProperties, amqpMap(properties...)),
+				amqpDescribed(amqpData, amqpBinary(message.body)),
+			)
+		}
+	}
+}
+
+// second模式下客户端发送未结算的disposition，服务端回复结算后客户端的AcceptMessage等方法才会返回
+func (c *testAmqpConn) disposition(channel uint16, disposition *amqpDescribedValue) {
+	first := amqpUintField(disposition, 1)
+	last := first
+	if amqpField(disposition, 2) != nil {
+		last = amqpUintField(disposition, 2)
+	}
+	settled, _ := amqpField(disposition, 3).(bool)
+
+	result := testAmqpDisposition{settled: settled}
+	state, ok := amqpField(disposition, 4).(*amqpDescribedValue)
+	if ok {
+		switch state.code {
+		case amqpAccepted:
+			result.state = "accepted"
+		case amqpRejected:
+			result.state = "rejected"
+			if condition, ok := amqpField(state, 0).(*amqpDescribedValue); ok {
+				result.condition, _ = amqpField(condition, 0).(string)
+			}
+		case amqpReleased:
+			result.state = "released"
+		case amqpModified:
+			result.state = "modified"
+		}
+	}
+
+	c.lock.Lock()
+	settleSecond := c.settleSecond
+	for id := first; id <= last; id++ {
+		result.messageId = c.deliveries[id]
+		c.broker.dispositions <- result
+	}
+	c.lock.Unlock()
+
+	if settleSecond && !settled && state != nil {
+		c.send(0, channel, amqpDescribed(amqpDisposition, amqpList(
+			amqpBool(false),
+			amqpUint(first),
+			amqpUint(last),
+			amqpBool(true),
+			amqpDescribed(byte(state.code), amqpList()),
+		)))
+	}
+}
+
+// 读取一个帧，返回帧类型、通道和performative，空帧（心跳）的performative为nil
+func (c *testAmqpConn) readFrame() (byte, uint16, *amqpDescribedValue, error) {
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(c.conn, header); err != nil {
+		return 0, 0, nil, err
+	}
+
+	size := binary.BigEndian.Uint32(header)
+	frame := make([]byte, size-8)
+	if _, err := io.ReadFull(c.conn, frame); err != nil {
+		return 0, 0, nil, err
+	}
+
+	body := frame[int(header[4])*4-8:]
+	if len(body) == 0 {
+		return header[5], binary.BigEndian.Uint16(header[6:]), nil, nil
+	}
+
+	reader := &amqpReader{data: body}
+	performative, ok := reader.value().(*amqpDescribedValue)
+	if !ok || reader.err != nil {
+		return 0, 0, nil, fmt.Errorf("malformed amqp frame: %x", body)
+	}
+
+	return header[5], binary.BigEndian.Uint16(header[6:]), performative, nil
+}
+
+func encodeAmqpFrame(frameType byte, channel uint16, body []byte) []byte {
+	frame := make([]byte, 8, 8+len(body))
+	binary.BigEndian.PutUint32(frame, uint32(8+len(body)))
+	frame[4] = 2
+	frame[5] = frameType
+	binary.BigEndian.PutUint16(frame[6:], channel)
+
+	return append(frame, body...)
+}
+
+func amqpNull() []byte {
+	return []byte{0x40}
+}
+
+func amqpBool(value bool) []byte {
+	if value {
+		return []byte{0x41}
+	}
+	return []byte{0x42}
+}
+
+func amqpUbyte(value byte) []byte {
+	return []byte{0x50, value}
+}
+
+func amqpUshort(value uint16) []byte {
+	b := []byte{0x60, 0, 0}
+	binary.BigEndian.PutUint16(b[1:], value)
+	return b
+}
+
+func amqpUint(value uint32) []byte {
+	b := []byte{0x70, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(b[1:], value)
+	return b
+}
+
+func amqpVariable(code byte, value []byte) []byte {
+	b := []byte{code, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(b[1:], uint32(len(value)))
+	return append(b, value...)
+}
+
+func amqpBinary(value []byte) []byte {
+	return amqpVariable(0xb0, value)
+}
+
+func amqpString(value string) []byte {
+	return amqpVariable(0xb1, []byte(value))
+}
+
+func amqpCompound(code byte, count int, items [][]byte) []byte {
+	b := []byte{code, 0, 0, 0, 0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(b[5:], uint32(count))
+	b = append(b, bytes.Join(items, nil)...)
+	binary.BigEndian.PutUint32(b[1:], uint32(len(b)-5))
+	return b
+}
+
+func amqpList(items ...[]byte) []byte {
+	return amqpCompound(0xd0, len(items), items)
+}
+
+func amqpMap(pairs ...[]byte) []byte {
+	return amqpCompound(0xd1, len(pairs), pairs)
+}
+
+func amqpSymbolArray(symbols ...string) []byte {
+	items := [][]byte{{0xb3}}
+	for _, symbol := range symbols {
+		items = append(items, amqpVariable(0xb3, []byte(symbol))[1:])
+	}
+	return amqpCompound(0xf0, len(symbols), items)
+}
+
+func amqpDescribed(code byte, value []byte) []byte {
+	return append([]byte{0x00, 0x53, code}, value...)
+}
+
+type amqpDescribedValue struct {
+	code  uint64
+	value interface{}
+}
+
+func amqpField(described *amqpDescribedValue, i int) interface{} {
+	fields, _ := described.value.([]interface{})
+	if i >= len(fields) {
+		return nil
+	}
+	return fields[i]
+}
+
+func amqpUintField(described *amqpDescribedValue, i int) uint32 {
+	value, _ := amqpField(described, i).(uint64)
+	return uint32(value)
+}
+
+// 解码客户端发送的AMQP类型，整数统一解码为uint64，list和map解码为[]interface{}
+type amqpReader struct {
+	data []byte
+	err  error
+}
+
+func (r *amqpReader) next(n int) []byte {
+	if r.err != nil || n > len(r.data) {
+		r.err = io.ErrUnexpectedEOF
+		return make([]byte, n)
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *amqpReader) value() interface{} {
+	code := r.next(1)[0]
+	if code != 0x00 {
+		return r.typed(code)
+	}
+
+	descriptor, _ := r.value().(uint64)
+	return &amqpDescribedValue{code: descriptor, value: r.value()}
+}
+
+func (r *amqpReader) typed(code byte) interface{} {
+	switch code {
+	case 0x40:
+		return nil
+	case 0x41:
+		return true
+	case 0x42:
+		return false
+	case 0x56:
+		return r.next(1)[0] == 1
+	case 0x43, 0x44:
+		return uint64(0)
+	case 0x50, 0x51, 0x52, 0x53, 0x54, 0x55:
+		return uint64(r.next(1)[0])
+	case 0x60, 0x61:
+		return uint64(binary.BigEndian.Uint16(r.next(2)))
+	case 0x70, 0x71, 0x72:
+		return uint64(binary.BigEndian.Uint32(r.next(4)))
+	case 0x80, 0x81, 0x82, 0x83:
+		return binary.BigEndian.Uint64(r.next(8))
+	case 0x98:
+		return r.next(16)
+	case 0xa0:
+		return append([]byte(nil), r.next(int(r.next(1)[0]))...)
+	case 0xb0:
+		return append([]byte(nil), r.next(int(binary.BigEndian.Uint32(r.next(4))))...)
+	case 0xa1, 0xa3:
+		return string(r.next(int(r.next(1)[0])))
+	case 0xb1, 0xb3:
+		return string(r.next(int(binary.BigEndian.Uint32(r.next(4)))))
+	case 0x45:
+		return []interface{}{}
+	case 0xc0, 0xc1:
+		r.next(1)
+		return r.items(int(r.next(1)[0]), 0)
+	case 0xd0, 0xd1:
+		r.next(4)
+		return r.items(int(binary.BigEndian.Uint32(r.next(4))), 0)
+	case 0xe0:
+		r.next(1)
+		count := int(r.next(1)[0])
+		return r.items(count, r.next(1)[0])
+	case 0xf0:
+		r.next(4)
+		count := int(binary.BigEndian.Uint32(r.next(4)))
+		return r.items(count, r.next(1)[0])
+	}
+
+	r.err = fmt.Errorf("unsupported amqp type 0x%02x", code)
+	return nil
+}
+
+// elementCode不为0时为数组，元素共用一个类型码
+func (r *amqpReader) items(count int, elementCode byte) []interface{} {
+	items := make([]interface{}, 0, count)
+	for i := 0; i < count && r.err == nil; i++ {
+		if elementCode != 0 {
+			items = append(items, r.typed(elementCode))
+		} else {
+			items = append(items, r.value())
+		}
+	}
+	return items
+}
+
+func newTestAmqpConsumer(t *testing.T, broker *testAmqpBroker, options *AmqpConsumerOptions, handler AmqpMessageHandler) *AmqpConsumer {
+	t.Helper()
+
+	options.SetAddress("amqp://127.0.0.1:5672").
+		SetAccessCode(&CreateAccessCodeResponse{AccessKey: "access-key", AccessCode: "access-code"}).
+		SetInstanceId("instance-id").
+		SetQueueName("test-queue")
+	options.DialConn = broker.dialConn
+
+	consumer, err := NewAmqpConsumer(*options, handler)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return consumer
+}
+
+func runTestAmqpConsumer(t *testing.T, consumer *AmqpConsumer) func() {
+	t.Helper()
+
+	result := make(chan error, 1)
+	go func() {
+		result <- consumer.Run(context.Background())
+	}()
+
+	return func() {
+		_ = consumer.Close()
+		select {
+		case err := <-result:
+			if err != nil {
+				t.Errorf("Run() error = %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Error("Run() did not return after Close")
+		}
+	}
+}
+
+func waitForCondition(t *testing.T, message string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", message)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestNewAmqpConsumerInvalidOptions(t *testing.T) {
+	handler := func(ctx context.Context, message *AmqpMessage) error { return nil }
+	valid := func() *AmqpConsumerOptions {
+		return NewAmqpConsumerOptions().SetAddress("amqps://127.0.0.1").
+			SetAccessCode(&CreateAccessCodeResponse{AccessKey: "ak", AccessCode: "code"}).
+			SetQueueName("queue")
+	}
+
+	tests := []struct {
+		name    string
+		options *AmqpConsumerOptions
+		handler AmqpMessageHandler
+	}{
+		{"empty address", valid().SetAddress(""), handler},
+		{"empty access code", valid().SetAccessCode(&CreateAccessCodeResponse{AccessKey: "ak"}), handler},
+		{"empty queue", valid().SetQueueName(""), handler},
+		{"nil handler", valid(), nil},
+	}
+	for _, tt := range tests {
+		if _, err := NewAmqpConsumer(*tt.options, tt.handler); err == nil {
+			t.Errorf("%s: NewAmqpConsumer() error = nil", tt.name)
+		}
+	}
+
+	consumer, err := NewAmqpConsumer(AmqpConsumerOptions{
+		Address: "amqps://127.0.0.1", AccessKey: "ak", AccessCode: "code", QueueName: "queue",
+	}, handler)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if consumer.options.Credit != 100 || consumer.options.Concurrency != 1 ||
+		consumer.options.ReconnectDelay != time.Second || consumer.options.MaxReconnectDelay != time.Second {
+		t.Errorf("default options = %+v", consumer.options)
+	}
+}
+
+// 根据消息ID返回不同结果的处理函数
+func newOutcomeAmqpHandler(received chan<- *AmqpMessage) AmqpMessageHandler {
+	return func(ctx context.Context, message *AmqpMessage) error {
+		received <- message
+		switch message.MessageID {
+		case "panic":
+			panic("handler panic")
+		case "reject":
+			return fmt.Errorf("%w: malformed", ErrAmqpMessageRejected)
+		case "release":
+			return errors.New("database unavailable")
+		}
+		return nil
+	}
+}
+
+// 处理函数panic后消费者继续处理后面的消息
+var outcomeAmqpMessages = []testAmqpMessage{
+	{id: "panic"},
+	{id: "accept", properties: map[string]string{"resource": "device.message"}, body: []byte(`{"notify_data":{}}`)},
+	{id: "reject"},
+	{id: "release"},
+}
+
+func TestAmqpConsumerSettleSecond(t *testing.T) {
+	broker := newTestAmqpBroker(t)
+	broker.supportSettleSecond = true
+
+	received := make(chan *AmqpMessage, 10)
+	consumer := newTestAmqpConsumer(t, broker, NewAmqpConsumerOptions().SetReceiverSettleSecond(true), newOutcomeAmqpHandler(received))
+	stop := runTestAmqpConsumer(t, consumer)
+	defer stop()
+
+	broker.publish(outcomeAmqpMessages...)
+
+	expected := map[string]string{
+		"panic":   "released",
+		"accept":  "accepted",
+		"reject":  "rejected",
+		"release": "released",
+	}
+	for range expected {
+		disposition := broker.nextDisposition()
+		if disposition.state != expected[disposition.messageId] {
+			t.Errorf("message %s state = %s, want %s", disposition.messageId, disposition.state, expected[disposition.messageId])
+		}
+		if disposition.settled {
+			t.Errorf("message %s disposition is settled in second mode", disposition.messageId)
+		}
+		if disposition.state == "rejected" && disposition.condition != "amqp:internal-error" {
+			t.Errorf("rejected condition = %s", disposition.condition)
+		}
+	}
+
+	for i := 0; i < len(expected); i++ {
+		message := <-received
+		if message.MessageID != "accept" {
+			continue
+		}
+		if message.ApplicationProperties["resource"] != "device.message" || string(message.Body) != `{"notify_data":{}}` {
+			t.Errorf("message = %+v", message)
+		}
+	}
+
+	broker.lock.Lock()
+	defer broker.lock.Unlock()
+	if mode := broker.rcvSettleModes[0]; mode != uint64(1) {
+		t.Errorf("rcv-settle-mode = %v, want 1", mode)
+	}
+}
+
+func TestAmqpConsumerSettleFirst(t *testing.T) {
+	broker := newTestAmqpBroker(t)
+
+	logger := &captureLogger{}
+	received := make(chan *AmqpMessage, 10)
+	consumer := newTestAmqpConsumer(t, broker, NewAmqpConsumerOptions().SetLogger(logger), newOutcomeAmqpHandler(received))
+	stop := runTestAmqpConsumer(t, consumer)
+	defer stop()
+
+	broker.publish(outcomeAmqpMessages...)
+
+	// first模式下消息在接收时即被接受，处理函数的结果不再发送disposition
+	for range outcomeAmqpMessages {
+		disposition := broker.nextDisposition()
+		if disposition.state != "accepted" || !disposition.settled {
+			t.Errorf("message %s disposition = %+v, want settled accepted", disposition.messageId, disposition)
+		}
+	}
+	for range outcomeAmqpMessages {
+		<-received
+	}
+	select {
+	case disposition := <-broker.dispositions:
+		t.Errorf("unexpected disposition %+v", disposition)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	waitForCondition(t, "3 logged handle failures", func() bool { return logger.count("handle amqp message failed") == 3 })
+
+	broker.lock.Lock()
+	defer broker.lock.Unlock()
+	if len(broker.sourceAddress) != 1 || broker.sourceAddress[0] != "test-queue" {
+		t.Errorf("source address = %v", broker.sourceAddress)
+	}
+	if mode := broker.rcvSettleModes[0]; mode != nil {
+		t.Errorf("rcv-settle-mode = %v, want default", mode)
+	}
+	if !strings.HasPrefix(broker.userNames[0], "accessKey=access-key|timestamp=") ||
+		!strings.HasSuffix(broker.userNames[0], "|instanceId=instance-id") || broker.passwords[0] != "access-code" {
+		t.Errorf("sasl user name = %s, password = %s", broker.userNames[0], broker.passwords[0])
+	}
+}
+
+func TestAmqpConsumerSettleSecondUnsupported(t *testing.T) {
+	broker := newTestAmqpBroker(t)
+
+	lost := make(chan error, 10)
+	options := NewAmqpConsumerOptions().SetReceiverSettleSecond(true)
+	options.ReconnectDelay = time.Hour
+	options.OnConnected = func() { t.Error("connected with an unsupported settle mode") }
+	options.OnConnectionLost = func(err error) { lost <- err }
+
+	consumer := newTestAmqpConsumer(t, broker, options, func(ctx context.Context, message *AmqpMessage) error { return nil })
+	stop := runTestAmqpConsumer(t, consumer)
+	defer stop()
+
+	select {
+	case err := <-lost:
+		if err == nil || !strings.Contains(err.Error(), "settlement mode") {
+			t.Errorf("OnConnectionLost error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for attach failure")
+	}
+}
+
+// 阻塞处理函数，记录同时执行的最大数量
+type blockingAmqpHandler struct {
+	active    int32
+	maxActive int32
+	handled   int32
+	release   chan struct{}
+	once      sync.Once
+}
+
+func newBlockingAmqpHandler() *blockingAmqpHandler {
+	return &blockingAmqpHandler{release: make(chan struct{})}
+}
+
+func (h *blockingAmqpHandler) unblock() {
+	h.once.Do(func() { close(h.release) })
+}
+
+func (h *blockingAmqpHandler) handle(ctx context.Context, message *AmqpMessage) error {
+	active := atomic.AddInt32(&h.active, 1)
+	for {
+		max := atomic.LoadInt32(&h.maxActive)
+		if active <= max || atomic.CompareAndSwapInt32(&h.maxActive, max, active) {
+			break
+		}
+	}
+
+	<-h.release
+	atomic.AddInt32(&h.active, -1)
+	atomic.AddInt32(&h.handled, 1)
+	return nil
+}
+
+func TestAmqpConsumerConcurrency(t *testing.T) {
+	broker := newTestAmqpBroker(t)
+	handler := newBlockingAmqpHandler()
+
+	consumer := newTestAmqpConsumer(t, broker, NewAmqpConsumerOptions().SetConcurrency(3).SetCredit(10), handler.handle)
+	stop := runTestAmqpConsumer(t, consumer)
+	defer stop()
+	defer handler.unblock()
+
+	for i := 0; i < 5; i++ {
+		broker.publish(testAmqpMessage{id: fmt.Sprint(i)})
+	}
+
+	waitForCondition(t, "3 concurrent handlers", func() bool { return atomic.LoadInt32(&handler.active) == 3 })
+	time.Sleep(50 * time.Millisecond)
+	if max := atomic.LoadInt32(&handler.maxActive); max != 3 {
+		t.Errorf("max concurrent handlers = %d, want 3", max)
+	}
+
+	handler.unblock()
+	for i := 0; i < 5; i++ {
+		if disposition := broker.nextDisposition(); disposition.state != "accepted" {
+			t.Errorf("message %s state = %s", disposition.messageId, disposition.state)
+		}
+	}
+}
+
+func TestAmqpConsumerCredit(t *testing.T) {
+	broker := newTestAmqpBroker(t)
+	broker.supportSettleSecond = true
+	handler := newBlockingAmqpHandler()
+
+	options := NewAmqpConsumerOptions().SetConcurrency(5).SetCredit(2).SetReceiverSettleSecond(true)
+	consumer := newTestAmqpConsumer(t, broker, options, handler.handle)
+	stop := runTestAmqpConsumer(t, consumer)
+	defer stop()
+	defer handler.unblock()
+
+	for i := 0; i < 5; i++ {
+		broker.publish(testAmqpMessage{id: fmt.Sprint(i)})
+	}
+
+	// second模式下未确认的消息不超过credit，即使处理函数的goroutine更多
+	waitForCondition(t, "2 concurrent handlers", func() bool { return atomic.LoadInt32(&handler.active) == 2 })
+	time.Sleep(50 * time.Millisecond)
+	if max := atomic.LoadInt32(&handler.maxActive); max != 2 {
+		t.Errorf("max concurrent handlers = %d, want 2", max)
+	}
+
+	_, linkCredits := broker.snapshot()
+	if len(linkCredits) == 0 || linkCredits[0] != 2 {
+		t.Errorf("link credits = %v, want first flow with credit 2", linkCredits)
+	}
+	for _, credit := range linkCredits {
+		if credit > 2 {
+			t.Errorf("link credit = %d, exceeds 2", credit)
+		}
+	}
+
+	handler.unblock()
+	for i := 0; i < 5; i++ {
+		broker.nextDisposition()
+	}
+	if handled := atomic.LoadInt32(&handler.handled); handled != 5 {
+		t.Errorf("handled = %d, want 5", handled)
+	}
+}
+
+func TestAmqpConsumerReconnectBackoff(t *testing.T) {
+	broker := newTestAmqpBroker(t)
+	broker.dialFailures = 5
+
+	connected := make(chan struct{}, 10)
+	lost := int32(0)
+	options := NewAmqpConsumerOptions()
+	options.ReconnectDelay = 30 * time.Millisecond
+	options.MaxReconnectDelay = 300 * time.Millisecond
+	options.OnConnected = func() { connected <- struct{}{} }
+	options.OnConnectionLost = func(err error) { atomic.AddInt32(&lost, 1) }
+
+	consumer := newTestAmqpConsumer(t, broker, options, func(ctx context.Context, message *AmqpMessage) error { return nil })
+	stop := runTestAmqpConsumer(t, consumer)
+	defer stop()
+
+	select {
+	case <-connected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for connection")
+	}
+
+	// 等待时间按照指数增长，不超过MaxReconnectDelay
+	dialTimes, _ := broker.snapshot()
+	if len(dialTimes) != 6 {
+		t.Fatalf("dials = %d, want 6", len(dialTimes))
+	}
+	expected := []time.Duration{30, 60, 120, 240, 300}
+	for i, delay := range expected {
+		gap := dialTimes[i+1].Sub(dialTimes[i])
+		if gap < delay*time.Millisecond || gap > delay*time.Millisecond+150*time.Millisecond {
+			t.Errorf("delay before dial %d = %v, want about %v", i+2, gap, delay*time.Millisecond)
+		}
+	}
+
+	// 连接成功后等待时间重置为ReconnectDelay
+	dropped := broker.dropConnections()
+	select {
+	case <-connected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for reconnection")
+	}
+
+	dialTimes, _ = broker.snapshot()
+	if gap := dialTimes[len(dialTimes)-1].Sub(dropped); gap < 30*time.Millisecond || gap > 200*time.Millisecond {
+		t.Errorf("delay after a successful connection = %v, want about 30ms", gap)
+	}
+	if n := atomic.LoadInt32(&lost); n != 6 {
+		t.Errorf("OnConnectionLost called %d times, want 6", n)
+	}
+}
+
+func TestAmqpConsumerCloseWaitsForRun(t *testing.T) {
+	broker := newTestAmqpBroker(t)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	finished := int32(0)
+	consumer := newTestAmqpConsumer(t, broker, NewAmqpConsumerOptions(), func(ctx context.Context, message *AmqpMessage) error {
+		close(started)
+		<-release
+		atomic.StoreInt32(&finished, 1)
+		return nil
+	})
+
+	runResult := make(chan error, 1)
+	go func() {
+		runResult <- consumer.Run(context.Background())
+	}()
+
+	broker.publish(testAmqpMessage{id: "slow"})
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for handler")
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		_ = consumer.Close()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+		t.Fatal("Close() returned while the handler is running")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close() did not return")
+	}
+
+	if atomic.LoadInt32(&finished) != 1 {
+		t.Error("Close() returned before the handler finished")
+	}
+	select {
+	case err := <-runResult:
+		if err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("Run() did not return after Close")
+	}
+
+	// 停止消费时已经处理完成的消息仍然会被确认
+	if disposition := broker.nextDisposition(); disposition.messageId != "slow" || disposition.state != "accepted" {
+		t.Errorf("disposition = %+v", disposition)
+	}
+
+	if err := consumer.Close(); err != nil {
+		t.Errorf("Close() after Run returned error = %v", err)
+	}
+}
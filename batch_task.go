@@ -0,0 +1,238 @@
+package iot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 批量任务类型
+const (
+	BatchTaskCreateDevices   = "createDevices"
+	BatchTaskDeleteDevices   = "deleteDevices"
+	BatchTaskFreezeDevices   = "freezeDevices"
+	BatchTaskUnfreezeDevices = "unfreezeDevices"
+	BatchTaskFirmwareUpgrade = "firmwareUpgrade"
+	BatchTaskSoftwareUpgrade = "softwareUpgrade"
+)
+
+// 批量任务状态
+const (
+	BatchTaskStatusInitializing   = "Initializing"
+	BatchTaskStatusWaiting        = "Waitting"
+	BatchTaskStatusProcessing     = "Processing"
+	BatchTaskStatusSuccess        = "Success"
+	BatchTaskStatusFail           = "Fail"
+	BatchTaskStatusPartialSuccess = "PartialSuccess"
+	BatchTaskStatusStopped        = "Stopped"
+	BatchTaskStatusStopping       = "Stopping"
+)
+
+// 批量任务管理-创建批量任务
+// 创建、删除、冻结、解冻设备等基于文件的任务需要先通过UploadBatchTaskFile上传文件，并将返回的文件ID设置到TargetsFile
+type CreateBatchTaskRequest struct {
+	AppID         string            `json:"app_id,omitempty"`
+	TaskName      string            `json:"task_name"`
+	TaskType      string            `json:"task_type"`
+	Targets       []string          `json:"targets,omitempty"`
+	TargetsFilter map[string]string `json:"targets_filter,omitempty"`
+	Document      interface{}       `json:"document,omitempty"`
+	TargetsFile   string            `json:"targets_file,omitempty"`
+	TaskPolicy    *TaskPolicy       `json:"task_policy,omitempty"`
+}
+
+// 固件或软件升级任务的Document
+type UpgradeDocument struct {
+	PackageID string `json:"package_id"`
+}
+
+type TaskPolicy struct {
+	ScheduleTime  string `json:"schedule_time,omitempty"`
+	RetryCount    int    `json:"retry_count,omitempty"`
+	RetryInterval int    `json:"retry_interval,omitempty"`
+}
+
+type BatchTask struct {
+	TaskID        string            `json:"task_id"`
+	TaskName      string            `json:"task_name"`
+	TaskType      string            `json:"task_type"`
+	Targets       []string          `json:"targets"`
+	TargetsFilter map[string]string `json:"targets_filter"`
+	Document      interface{}       `json:"document"`
+	TaskPolicy    TaskPolicy        `json:"task_policy"`
+	Status        string            `json:"status"`
+	StatusDesc    string            `json:"status_desc"`
+	TaskProgress  TaskProgress      `json:"task_progress"`
+	CreatedTime   string            `json:"created_time"`
+}
+
+// 任务是否已经结束
+func (t *BatchTask) Finished() bool {
+	switch t.Status {
+	case BatchTaskStatusSuccess, BatchTaskStatusFail, BatchTaskStatusPartialSuccess, BatchTaskStatusStopped:
+		return true
+	default:
+		return false
+	}
+}
+
+type TaskProgress struct {
+	Total         int `json:"total"`
+	Processing    int `json:"processing"`
+	Success       int `json:"success"`
+	Fail          int `json:"fail"`
+	Waiting       int `json:"waitting"`
+	FailWaitRetry int `json:"fail_wait_retry"`
+	Stopped       int `json:"stopped"`
+	Removed       int `json:"removed"`
+}
+
+type ListBatchTasksRequest struct {
+	AppId    string `json:"app_id,omitempty"`
+	TaskType string `json:"task_type"`
+	Status   string `json:"status,omitempty"`
+	Limit    int    `json:"limit,omitempty"`
+	Marker   string `json:"marker,omitempty"`
+	Offset   int    `json:"offset,omitempty"`
+}
+
+type ListBatchTasksResponse struct {
+	BatchTasks []BatchTask `json:"batchtasks"`
+	Page       Page        `json:"page"`
+}
+
+// 查询批量任务时子任务的分页参数
+type ShowBatchTaskRequest struct {
+	Limit  int    `json:"limit,omitempty"`
+	Marker string `json:"marker,omitempty"`
+	Offset int    `json:"offset,omitempty"`
+}
+
+type ShowBatchTaskResponse struct {
+	BatchTask   BatchTask    `json:"batchtask"`
+	TaskDetails []TaskDetail `json:"task_details"`
+	Page        Page         `json:"page"`
+}
+
+// 子任务，一个子任务对应一个设备
+type TaskDetail struct {
+	Target string          `json:"target"`
+	Status string          `json:"status"`
+	Output string          `json:"output"`
+	Error  TaskDetailError `json:"error"`
+}
+
+type TaskDetailError struct {
+	ErrorCode string `json:"error_code"`
+	ErrorMsg  string `json:"error_msg"`
+}
+
+// 批量任务文件管理
+type UploadBatchTaskFileResponse struct {
+	FileID string `json:"file_id"`
+}
+
+type ListBatchTaskFilesResponse struct {
+	Files []BatchTaskFile `json:"files"`
+}
+
+type BatchTaskFile struct {
+	FileID     string `json:"file_id"`
+	FileName   string `json:"file_name"`
+	UploadTime string `json:"upload_time"`
+}
+
+// 批量任务执行的最终结果
+type BatchTaskResult struct {
+	Task BatchTask
+	// 状态不是Success的子任务。任务结束后子任务不会再执行，除了Fail以外，Stopped、Removed等状态的子任务也没有执行成功
+	Failures []TaskDetail
+}
+
+// 批量任务没有全部执行成功时WaitBatchTask返回的错误
+type BatchTaskError struct {
+	TaskID   string
+	Status   string
+	Failures []TaskDetail
+}
+
+func (e *BatchTaskError) Error() string {
+	targets := make([]string, 0, len(e.Failures))
+	for _, failure := range e.Failures {
+		targets = append(targets, failure.Target+":"+failure.Error.ErrorCode)
+	}
+
+	return fmt.Sprintf("batch task %s finished with status %s, failed targets [%s]",
+		e.TaskID, e.Status, strings.Join(targets, ","))
+}
+
+// WaitBatchTask 按照interval轮询批量任务，直到任务结束或者ctx结束。
+// 任务结束后会查询所有子任务并收集没有执行成功的子任务，如果任务状态不是Success则同时返回*BatchTaskError。
+func WaitBatchTask(ctx context.Context, client ApplicationClient, taskId string, interval time.Duration) (*BatchTaskResult, error) {
+	if interval <= 0 {
+		interval = 3 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		response, err := client.ShowBatchTaskCtx(ctx, taskId, ShowBatchTaskRequest{Limit: 1})
+		if err != nil {
+			return nil, err
+		}
+
+		if response.BatchTask.Finished() {
+			return collectBatchTaskResult(ctx, client, response.BatchTask)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func collectBatchTaskResult(ctx context.Context, client ApplicationClient, task BatchTask) (*BatchTaskResult, error) {
+	result := &BatchTaskResult{
+		Task: task,
+	}
+
+	marker := ""
+	for {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		response, err := client.ShowBatchTaskCtx(ctx, task.TaskID, ShowBatchTaskRequest{
+			Limit:  maxPageLimit,
+			Marker: marker,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, detail := range response.TaskDetails {
+			if detail.Status != BatchTaskStatusSuccess {
+				result.Failures = append(result.Failures, detail)
+			}
+		}
+
+		if len(response.TaskDetails) == 0 || len(response.Page.Marker) == 0 || response.Page.Marker == marker {
+			break
+		}
+		marker = response.Page.Marker
+	}
+
+	if task.Status != BatchTaskStatusSuccess {
+		return result, &BatchTaskError{
+			TaskID:   task.TaskID,
+			Status:   task.Status,
+			Failures: result.Failures,
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,184 @@
+package iot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 模拟批量任务查询接口，limit为1时为轮询任务状态，依次返回statuses中的状态，最后一个状态保持不变
+type batchTaskStub struct {
+	*httptest.Server
+
+	lock     sync.Mutex
+	statuses []string
+	details  []TaskDetail
+	polls    int
+	markers  []string
+	polled   chan struct{}
+}
+
+func newBatchTaskStub(statuses []string, details []TaskDetail) *batchTaskStub {
+	stub := &batchTaskStub{statuses: statuses, details: details, polled: make(chan struct{}, 100)}
+	stub.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v5/iot/project/batchtasks/task-1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		stub.lock.Lock()
+		defer stub.lock.Unlock()
+
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		response := ShowBatchTaskResponse{}
+		if limit == 1 {
+			status := stub.statuses[len(stub.statuses)-1]
+			if stub.polls < len(stub.statuses) {
+				status = stub.statuses[stub.polls]
+			}
+			stub.polls++
+			stub.polled <- struct{}{}
+			response.BatchTask = BatchTask{TaskID: "task-1", Status: status}
+		} else {
+			marker := r.URL.Query().Get("marker")
+			stub.markers = append(stub.markers, marker)
+
+			start, _ := strconv.Atoi(marker)
+			end := start + limit
+			if end > len(stub.details) {
+				end = len(stub.details)
+			}
+			response.BatchTask = BatchTask{TaskID: "task-1", Status: stub.statuses[len(stub.statuses)-1]}
+			response.TaskDetails = stub.details[start:end]
+			response.Page = Page{Count: len(stub.details), Marker: strconv.Itoa(end)}
+		}
+
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+
+	return stub
+}
+
+func (s *batchTaskStub) client() ApplicationClient {
+	return CreateSyncIotApplicationClient(*NewApplicationOptions().WithEndpoint(s.URL).SetProjectId("project").SetToken("token"))
+}
+
+func TestWaitBatchTaskSuccess(t *testing.T) {
+	details := []TaskDetail{{Target: "device-1", Status: BatchTaskStatusSuccess}, {Target: "device-2", Status: BatchTaskStatusSuccess}}
+	stub := newBatchTaskStub([]string{BatchTaskStatusWaiting, BatchTaskStatusProcessing, BatchTaskStatusSuccess}, details)
+	defer stub.Close()
+
+	result, err := WaitBatchTask(context.Background(), stub.client(), "task-1", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Task.Status != BatchTaskStatusSuccess || len(result.Failures) != 0 {
+		t.Errorf("result = %+v", result)
+	}
+	stub.lock.Lock()
+	defer stub.lock.Unlock()
+	if stub.polls != 3 {
+		t.Errorf("polls = %d, want 3", stub.polls)
+	}
+}
+
+func TestWaitBatchTaskFailures(t *testing.T) {
+	// 120个子任务分3页查询
+	var details []TaskDetail
+	for i := 0; i < 120; i++ {
+		details = append(details, TaskDetail{Target: fmt.Sprintf("device-%d", i), Status: BatchTaskStatusSuccess})
+	}
+	details[1] = TaskDetail{Target: "device-1", Status: BatchTaskStatusFail, Error: TaskDetailError{ErrorCode: "IOTDA.014000"}}
+	details[60].Status = BatchTaskStatusStopped
+	details[119].Status = "Removed"
+
+	stub := newBatchTaskStub([]string{BatchTaskStatusProcessing, BatchTaskStatusPartialSuccess}, details)
+	defer stub.Close()
+
+	result, err := WaitBatchTask(context.Background(), stub.client(), "task-1", time.Millisecond)
+
+	var taskErr *BatchTaskError
+	if !errors.As(err, &taskErr) || taskErr.TaskID != "task-1" || taskErr.Status != BatchTaskStatusPartialSuccess {
+		t.Fatalf("WaitBatchTask() error = %v, want *BatchTaskError", err)
+	}
+	if result == nil || len(result.Failures) != 3 || len(taskErr.Failures) != 3 {
+		t.Fatalf("result = %+v, failures = %v", result, taskErr.Failures)
+	}
+	for i, target := range []string{"device-1", "device-60", "device-119"} {
+		if result.Failures[i].Target != target {
+			t.Errorf("failures = %v, want %s at %d", result.Failures, target, i)
+		}
+	}
+	if expected := "batch task task-1 finished with status PartialSuccess, failed targets [device-1:IOTDA.014000,device-60:,device-119:]"; err.Error() != expected {
+		t.Errorf("error = %s, want %s", err, expected)
+	}
+
+	// 最后一页之后还会查询一次空页
+	stub.lock.Lock()
+	defer stub.lock.Unlock()
+	if fmt.Sprint(stub.markers) != "[ 50 100 120]" {
+		t.Errorf("subtask markers = %q", stub.markers)
+	}
+}
+
+func TestWaitBatchTaskFailStatus(t *testing.T) {
+	stub := newBatchTaskStub([]string{BatchTaskStatusFail}, nil)
+	defer stub.Close()
+
+	result, err := WaitBatchTask(context.Background(), stub.client(), "task-1", time.Millisecond)
+
+	var taskErr *BatchTaskError
+	if !errors.As(err, &taskErr) || taskErr.Status != BatchTaskStatusFail || result == nil || len(result.Failures) != 0 {
+		t.Errorf("WaitBatchTask() = %+v, %v", result, err)
+	}
+}
+
+func TestWaitBatchTaskCanceled(t *testing.T) {
+	stub := newBatchTaskStub([]string{BatchTaskStatusProcessing}, nil)
+	defer stub.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		_, err := WaitBatchTask(ctx, stub.client(), "task-1", time.Hour)
+		errs <- err
+	}()
+
+	// 第一次查询之后在等待下一次轮询时取消
+	<-stub.polled
+	cancel()
+	select {
+	case err := <-errs:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("WaitBatchTask() error = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("WaitBatchTask() does not return after cancel")
+	}
+
+	stub.lock.Lock()
+	defer stub.lock.Unlock()
+	if stub.polls != 1 {
+		t.Errorf("polls = %d, want 1", stub.polls)
+	}
+}
+
+func TestWaitBatchTaskError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error_code":"IOTDA.000002","error_msg":"task not found"}`))
+	}))
+	defer server.Close()
+
+	client := CreateSyncIotApplicationClient(*NewApplicationOptions().WithEndpoint(server.URL).SetProjectId("project").SetToken("token"))
+	if _, err := WaitBatchTask(context.Background(), client, "task-1", time.Millisecond); !IsNotFound(err) {
+		t.Errorf("WaitBatchTask() error = %v, want not found", err)
+	}
+}
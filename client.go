@@ -0,0 +1,253 @@
+package iot
+
+import (
+	"crypto/tls"
+	"fmt"
+	"github.com/go-resty/resty/v2"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Option 配置NewClient创建的Client，参数不合法时返回错误
+type Option func(options *ApplicationOptions) error
+
+// NewClient 创建Client，创建之前检查接入地址、项目ID和凭证，配置不合法时返回ErrInvalidConfig
+func NewClient(opts ...Option) (ApplicationClient, error) {
+	options := NewApplicationOptions()
+	for _, opt := range opts {
+		if err := opt(options); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	c := CreateSyncIotApplicationClient(*options)
+	if c.configErr != nil {
+		return nil, c.configErr
+	}
+
+	return c, nil
+}
+
+// WithOptions 使用已有的ApplicationOptions作为基础配置，需要在其他Option之前使用
+func WithOptions(options ApplicationOptions) Option {
+	return func(o *ApplicationOptions) error {
+		*o = options
+		// 避免后续的Option修改调用方的配置
+		o.RateLimits = make(map[ApiGroup]RateLimit, len(options.RateLimits))
+		for group, limit := range options.RateLimits {
+			o.RateLimits[group] = limit
+		}
+		o.Interceptors = append([]Interceptor(nil), options.Interceptors...)
+		return nil
+	}
+}
+
+func WithRegion(region string) Option {
+	return func(o *ApplicationOptions) error {
+		if len(region) == 0 {
+			return fmt.Errorf("%w: region is empty", ErrInvalidConfig)
+		}
+		o.Region = region
+		return nil
+	}
+}
+
+func WithEndpoint(endpoint string) Option {
+	return func(o *ApplicationOptions) error {
+		if _, err := normalizeEndpoint(endpoint, 0); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+		}
+		o.Endpoint = endpoint
+		return nil
+	}
+}
+
+func WithProjectId(projectId string) Option {
+	return func(o *ApplicationOptions) error {
+		if len(projectId) == 0 {
+			return fmt.Errorf("%w: project id is empty", ErrInvalidConfig)
+		}
+		o.ProjectId = projectId
+		return nil
+	}
+}
+
+func WithInstanceId(instanceId string) Option {
+	return func(o *ApplicationOptions) error {
+		o.InstanceId = instanceId
+		return nil
+	}
+}
+
+// WithAkSk 使用AK/SK签名
+func WithAkSk(ak, sk string) Option {
+	return func(o *ApplicationOptions) error {
+		if len(ak) == 0 || len(sk) == 0 {
+			return fmt.Errorf("%w: ak and sk are required", ErrInvalidConfig)
+		}
+		o.Credential = &Credentials{Ak: ak, Sk: sk, UseAkSk: true}
+		return nil
+	}
+}
+
+// WithToken 使用固定的X-Auth-Token，Token过期后需要重新创建Client，推荐使用WithCredentialsProvider
+func WithToken(token string) Option {
+	return func(o *ApplicationOptions) error {
+		if len(token) == 0 {
+			return fmt.Errorf("%w: token is empty", ErrInvalidConfig)
+		}
+		o.Credential = &Credentials{Token: token}
+		return nil
+	}
+}
+
+func WithCredentialsProvider(provider CredentialsProvider) Option {
+	return func(o *ApplicationOptions) error {
+		if provider == nil {
+			return fmt.Errorf("%w: credentials provider is nil", ErrInvalidConfig)
+		}
+		o.CredentialsProvider = provider
+		return nil
+	}
+}
+
+func WithHttpClient(client *http.Client) Option {
+	return func(o *ApplicationOptions) error {
+		if client == nil {
+			return fmt.Errorf("%w: http client is nil", ErrInvalidConfig)
+		}
+		o.HttpClient = client
+		return nil
+	}
+}
+
+func WithTlsConfig(config *tls.Config) Option {
+	return func(o *ApplicationOptions) error {
+		o.TlsConfig = config
+		return nil
+	}
+}
+
+func WithProxy(proxy string) Option {
+	return func(o *ApplicationOptions) error {
+		if _, err := parseProxy(proxy); err != nil {
+			return err
+		}
+		o.Proxy = proxy
+		return nil
+	}
+}
+
+// WithTimeout 单次请求的超时时间，connectTimeout为建立TCP连接的超时时间，为0时使用默认值
+func WithTimeout(timeout, connectTimeout time.Duration) Option {
+	return func(o *ApplicationOptions) error {
+		if timeout < 0 || connectTimeout < 0 {
+			return fmt.Errorf("%w: timeout must not be negative", ErrInvalidConfig)
+		}
+		o.Timeout = timeout
+		o.ConnectTimeout = connectTimeout
+		return nil
+	}
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(o *ApplicationOptions) error {
+		o.UserAgent = userAgent
+		return nil
+	}
+}
+
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return func(o *ApplicationOptions) error {
+		o.RetryPolicy = policy
+		return nil
+	}
+}
+
+func WithRateLimit(group ApiGroup, rate float64, burst int) Option {
+	return func(o *ApplicationOptions) error {
+		if rate <= 0 || burst <= 0 {
+			return fmt.Errorf("%w: rate and burst of %s must be positive", ErrInvalidConfig, group)
+		}
+		o.SetRateLimit(group, rate, burst)
+		return nil
+	}
+}
+
+func WithRateLimitMode(mode RateLimitMode) Option {
+	return func(o *ApplicationOptions) error {
+		o.RateLimitMode = mode
+		return nil
+	}
+}
+
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(o *ApplicationOptions) error {
+		o.AddInterceptor(interceptors...)
+		return nil
+	}
+}
+
+func WithLogger(logger Logger) Option {
+	return func(o *ApplicationOptions) error {
+		o.Logger = logger
+		return nil
+	}
+}
+
+func WithMetricsRecorder(recorder MetricsRecorder) Option {
+	return func(o *ApplicationOptions) error {
+		o.MetricsRecorder = recorder
+		return nil
+	}
+}
+
+// 根据HttpClient、TLS、代理、超时和User-Agent创建resty.Client，不修改调用方传入的http.Client
+func newRestyClient(options ApplicationOptions) (*resty.Client, error) {
+	client := resty.New()
+	if options.HttpClient != nil {
+		httpClient := *options.HttpClient
+		client = resty.NewWithClient(&httpClient)
+	}
+
+	if options.TlsConfig != nil || len(options.Proxy) != 0 || options.ConnectTimeout > 0 {
+		transport, ok := client.GetClient().Transport.(*http.Transport)
+		if !ok {
+			return nil, fmt.Errorf("%w: tls config, proxy and connect timeout require *http.Transport", ErrInvalidConfig)
+		}
+
+		transport = transport.Clone()
+		if options.TlsConfig != nil {
+			transport.TLSClientConfig = options.TlsConfig
+		}
+		if len(options.Proxy) != 0 {
+			proxy, err := parseProxy(options.Proxy)
+			if err != nil {
+				return nil, err
+			}
+			transport.Proxy = http.ProxyURL(proxy)
+		}
+		if options.ConnectTimeout > 0 {
+			transport.DialContext = (&net.Dialer{
+				Timeout:   options.ConnectTimeout,
+				KeepAlive: 30 * time.Second,
+			}).DialContext
+		}
+		client.SetTransport(transport)
+	}
+
+	if options.Timeout > 0 {
+		client.SetTimeout(options.Timeout)
+	}
+
+	if len(options.UserAgent) != 0 {
+		client.SetHeader("User-Agent", options.UserAgent)
+	}
+
+	return client, nil
+}
@@ -1,22 +1,110 @@
 package iot
 
-import "encoding/json"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
 
+// ApplicationError 调用平台API失败时返回的错误，除了平台返回的错误码和错误信息之外还包含HTTP状态码和请求ID等定位信息
 type ApplicationError struct {
-	ErrorCode string `json:"error_code"`
-	ErrorMsg  string `json:"error_msg"`
+	ErrorCode  string `json:"error_code"`
+	ErrorMsg   string `json:"error_msg"`
+	StatusCode int    `json:"status_code,omitempty"`
+	RequestID  string `json:"request_id,omitempty"`
+	Method     string `json:"method,omitempty"`
+	Endpoint   string `json:"endpoint,omitempty"`
 }
 
+// 平台常见的错误码，可以通过errors.Is(err, iot.ErrDeviceNotFound)判断
+var (
+	ErrInvalidInput   = &ApplicationError{ErrorCode: "IOTDA.000006", ErrorMsg: "invalid input data"}
+	ErrDeviceNotFound = &ApplicationError{ErrorCode: "IOTDA.014000", ErrorMsg: "device not found"}
+	ErrIamAuthFailed  = &ApplicationError{ErrorCode: "APIGW.0301", ErrorMsg: "incorrect IAM authentication information"}
+	ErrApiThrottled   = &ApplicationError{ErrorCode: "APIGW.0308", ErrorMsg: "the throttling threshold has been reached"}
+)
+
+// 客户端配置不合法或者无法解析时返回的错误，与平台返回的ErrInvalidInput不同，不是ApplicationError
+var ErrInvalidConfig = errors.New("invalid client configuration")
 
 func (e *ApplicationError) Error() string {
 	if e == nil {
 		return ""
 	}
 
-	jsonString, err := json.Marshal(e)
-	if err != nil {
-		return "marshal error"
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("iotda error: error_code=%s, error_msg=%s", e.ErrorCode, e.ErrorMsg)
+	}
+
+	return fmt.Sprintf("iotda error: %s %s, status_code=%d, error_code=%s, error_msg=%s, request_id=%s",
+		e.Method, e.Endpoint, e.StatusCode, e.ErrorCode, e.ErrorMsg, e.RequestID)
+}
+
+// Is 错误码相同时认为是同一个错误
+func (e *ApplicationError) Is(target error) bool {
+	t, ok := target.(*ApplicationError)
+	if !ok || e == nil || t == nil {
+		return false
+	}
+
+	return len(t.ErrorCode) != 0 && e.ErrorCode == t.ErrorCode
+}
+
+func asApplicationError(err error) (*ApplicationError, bool) {
+	var ae *ApplicationError
+	if errors.As(err, &ae) && ae != nil {
+		return ae, true
+	}
+
+	return nil, false
+}
+
+func IsNotFound(err error) bool {
+	ae, ok := asApplicationError(err)
+	if !ok {
+		return false
+	}
+
+	return ae.StatusCode == http.StatusNotFound || errors.Is(ae, ErrDeviceNotFound)
+}
+
+func IsConflict(err error) bool {
+	ae, ok := asApplicationError(err)
+	if !ok {
+		return false
+	}
+
+	return ae.StatusCode == http.StatusConflict
+}
+
+func IsThrottled(err error) bool {
+	if errors.Is(err, ErrRateLimited) {
+		return true
+	}
+
+	ae, ok := asApplicationError(err)
+	if !ok {
+		return false
+	}
+
+	return ae.StatusCode == http.StatusTooManyRequests || errors.Is(ae, ErrApiThrottled)
+}
+
+func IsAuthFailure(err error) bool {
+	ae, ok := asApplicationError(err)
+	if !ok {
+		return false
+	}
+
+	return ae.StatusCode == http.StatusUnauthorized || ae.StatusCode == http.StatusForbidden ||
+		errors.Is(ae, ErrIamAuthFailed)
+}
+
+func IsServerError(err error) bool {
+	ae, ok := asApplicationError(err)
+	if !ok {
+		return false
 	}
 
-	return string(jsonString)
+	return ae.StatusCode >= http.StatusInternalServerError
 }
@@ -0,0 +1,79 @@
+package iot
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestApplicationErrorHelpers(t *testing.T) {
+	notFound := &ApplicationError{StatusCode: http.StatusNotFound, ErrorCode: "IOTDA.014000"}
+	throttled := &ApplicationError{StatusCode: http.StatusTooManyRequests}
+	unauthorized := &ApplicationError{StatusCode: http.StatusUnauthorized}
+
+	tests := []struct {
+		name        string
+		err         error
+		notFound    bool
+		throttled   bool
+		authFailure bool
+		conflict    bool
+		serverError bool
+	}{
+		{name: "nil"},
+		{name: "not ApplicationError", err: errors.New("connection refused")},
+		{name: "typed nil", err: (*ApplicationError)(nil)},
+		{name: "bad request", err: &ApplicationError{StatusCode: http.StatusBadRequest, ErrorCode: "IOTDA.000006"}},
+		{name: "404", err: notFound, notFound: true},
+		{name: "wrapped 404", err: fmt.Errorf("show device: %w", notFound), notFound: true},
+		{name: "device not found code", err: &ApplicationError{StatusCode: http.StatusBadRequest, ErrorCode: "IOTDA.014000"}, notFound: true},
+		{name: "429", err: throttled, throttled: true},
+		{name: "wrapped 429", err: fmt.Errorf("list devices: %w", throttled), throttled: true},
+		{name: "throttled code", err: &ApplicationError{StatusCode: http.StatusBadRequest, ErrorCode: "APIGW.0308"}, throttled: true},
+		{name: "client rate limit", err: ErrRateLimited, throttled: true},
+		{name: "wrapped client rate limit", err: fmt.Errorf("wait: %w", ErrRateLimited), throttled: true},
+		{name: "401", err: unauthorized, authFailure: true},
+		{name: "wrapped 401", err: fmt.Errorf("create device: %w", unauthorized), authFailure: true},
+		{name: "403", err: &ApplicationError{StatusCode: http.StatusForbidden}, authFailure: true},
+		{name: "auth failed code", err: &ApplicationError{StatusCode: http.StatusBadRequest, ErrorCode: "APIGW.0301"}, authFailure: true},
+		{name: "409", err: fmt.Errorf("create product: %w", &ApplicationError{StatusCode: http.StatusConflict}), conflict: true},
+		{name: "500", err: fmt.Errorf("update device: %w", &ApplicationError{StatusCode: http.StatusInternalServerError}), serverError: true},
+		{name: "503", err: &ApplicationError{StatusCode: http.StatusServiceUnavailable}, serverError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsNotFound(tt.err); got != tt.notFound {
+				t.Errorf("IsNotFound() = %v, want %v", got, tt.notFound)
+			}
+			if got := IsThrottled(tt.err); got != tt.throttled {
+				t.Errorf("IsThrottled() = %v, want %v", got, tt.throttled)
+			}
+			if got := IsAuthFailure(tt.err); got != tt.authFailure {
+				t.Errorf("IsAuthFailure() = %v, want %v", got, tt.authFailure)
+			}
+			if got := IsConflict(tt.err); got != tt.conflict {
+				t.Errorf("IsConflict() = %v, want %v", got, tt.conflict)
+			}
+			if got := IsServerError(tt.err); got != tt.serverError {
+				t.Errorf("IsServerError() = %v, want %v", got, tt.serverError)
+			}
+		})
+	}
+}
+
+func TestApplicationErrorIs(t *testing.T) {
+	err := fmt.Errorf("show device: %w", &ApplicationError{StatusCode: http.StatusNotFound, ErrorCode: "IOTDA.014000"})
+	if !errors.Is(err, ErrDeviceNotFound) {
+		t.Error("errors.Is(err, ErrDeviceNotFound) = false, want true")
+	}
+	if errors.Is(err, ErrInvalidInput) {
+		t.Error("errors.Is(err, ErrInvalidInput) = true, want false")
+	}
+
+	// 没有错误码的错误不与任何错误相同
+	if errors.Is(&ApplicationError{StatusCode: http.StatusBadGateway}, &ApplicationError{}) {
+		t.Error("errors without error code should not match")
+	}
+}
@@ -1,7 +1,12 @@
 package iot
 
 import (
+	"crypto/tls"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
 )
 
 type Credentials struct {
@@ -9,20 +14,70 @@ type Credentials struct {
 	Sk      string
 	Token   string
 	UseAkSk bool
+
+	// 临时AK/SK对应的SecurityToken，不为空时请求携带X-Security-Token消息头
+	SecurityToken string
+
+	// 不使用AK/SK鉴权时，如果设置了TokenProvider则从IAM获取Token并自动刷新，忽略Token
+	TokenProvider *IamTokenProvider
 }
 
 type ApplicationOptions struct {
+	// 接入地址，可以包含协议，没有协议时使用https
 	ServerAddress string
-	ServerPort    int
-	InstanceId    string
-	ProjectId     string
-	Credential    *Credentials
+	// 只用于https接入地址；http接入地址只在通过AddServerPort或者以非默认值设置时使用
+	ServerPort int
+	InstanceId string
+	// 为空时从IamTokenProvider或者IAM获取Region对应的项目ID
+	ProjectId  string
+	Credential *Credentials
+
+	// 区域ID，例如cn-north-4，没有设置Endpoint和ServerAddress时使用区域内基础版实例的接入地址
+	Region string
+	// 完整的接入地址，例如标准版、企业版实例的应用侧接入地址或者http://127.0.0.1:8080，优先级最高
+	Endpoint string
+
+	// 获取凭证的方式，为空时使用Credential
+	CredentialsProvider CredentialsProvider
+
+	// 请求失败后的重试策略，为空时使用DefaultRetryPolicy
+	RetryPolicy *RetryPolicy
+
+	// 按照API分组配置客户端流控，没有配置的分组不限制；RateLimitMode决定超过配额时等待还是立即失败
+	RateLimits    map[ApiGroup]RateLimit
+	RateLimitMode RateLimitMode
+
+	// 拦截每一次API调用，先添加的拦截器在外层
+	Interceptors []Interceptor
+
+	// 为空时不输出日志，Authorization、X-Auth-Token、设备密钥、接入凭证等敏感信息会被脱敏
+	Logger Logger
+
+	// 为空时不记录指标
+	MetricsRecorder MetricsRecorder
+
+	// 发送请求使用的http.Client，为空时使用默认配置；SDK会复制该Client，不会修改调用方的配置
+	HttpClient *http.Client
+	// TLS、代理和建立连接的超时时间只对*http.Transport生效
+	TlsConfig *tls.Config
+	// 代理地址，例如http://proxy.example.com:8080，为空时使用环境变量HTTP_PROXY、HTTPS_PROXY
+	Proxy string
+	// 单次请求的超时时间，包括建立连接和读取响应，为0时不超时，重试时每次请求单独计时
+	Timeout time.Duration
+	// 建立TCP连接的超时时间，为0时使用默认值
+	ConnectTimeout time.Duration
+	UserAgent      string
+
+	// 是否通过AddServerPort显式设置了端口
+	serverPortSet bool
 }
 
+const defaultServerPort = 443
+
 func NewApplicationOptions() *ApplicationOptions {
 	o := &ApplicationOptions{
 		ServerAddress: "",
-		ServerPort:    443,
+		ServerPort:    defaultServerPort,
 		InstanceId:    "",
 		ProjectId:     "",
 		Credential:    nil,
@@ -31,55 +86,98 @@ func NewApplicationOptions() *ApplicationOptions {
 }
 
 func (o *ApplicationOptions) AddServer(server string) *ApplicationOptions {
-	if len(server) == 0 {
-		fmt.Println("server is empty")
-		o.ServerAddress = "https://iotda.cn-north-4.myhuaweicloud.com:443"
-	} else {
+	if len(server) != 0 {
 		o.ServerAddress = server
 	}
 
 	return o
 }
 
+func (o *ApplicationOptions) WithRegion(region string) *ApplicationOptions {
+	o.Region = region
+	return o
+}
+
+func (o *ApplicationOptions) WithEndpoint(endpoint string) *ApplicationOptions {
+	o.Endpoint = endpoint
+	return o
+}
+
 func (o *ApplicationOptions) AddServerPort(port int) *ApplicationOptions {
 	o.ServerPort = port
+	o.serverPortSet = true
 	return o
 }
 
 func (o *ApplicationOptions) AddAk(ak string) *ApplicationOptions {
-	if len(ak) == 0 {
-		fmt.Println("ak is empty")
-	} else {
-		o.Credential.Ak = ak
+	if len(ak) != 0 {
+		o.credential().Ak = ak
 	}
 
 	return o
 }
 
 func (o *ApplicationOptions) AddSk(sk string) *ApplicationOptions {
-	if len(sk) == 0 {
-		fmt.Println("ak is empty")
-	} else {
-		o.Credential.Sk = sk
+	if len(sk) != 0 {
+		o.credential().Sk = sk
 	}
 
 	return o
 }
 
 func (o *ApplicationOptions) SetToken(token string) *ApplicationOptions {
-	if len(token) == 0 {
-		fmt.Println("Token is empty")
-	} else {
-		o.Credential.Token = token
+	if len(token) != 0 {
+		o.credential().Token = token
+	}
+
+	return o
+}
+
+func (o *ApplicationOptions) SetTokenProvider(provider *IamTokenProvider) *ApplicationOptions {
+	o.credential().TokenProvider = provider
+	return o
+}
+
+func (o *ApplicationOptions) SetCredentialsProvider(provider CredentialsProvider) *ApplicationOptions {
+	o.CredentialsProvider = provider
+	return o
+}
+
+func (o *ApplicationOptions) SetRetryPolicy(policy *RetryPolicy) *ApplicationOptions {
+	o.RetryPolicy = policy
+	return o
+}
+
+func (o *ApplicationOptions) SetRateLimit(group ApiGroup, rate float64, burst int) *ApplicationOptions {
+	if o.RateLimits == nil {
+		o.RateLimits = map[ApiGroup]RateLimit{}
 	}
+	o.RateLimits[group] = RateLimit{Rate: rate, Burst: burst}
+	return o
+}
+
+func (o *ApplicationOptions) SetRateLimitMode(mode RateLimitMode) *ApplicationOptions {
+	o.RateLimitMode = mode
+	return o
+}
+
+func (o *ApplicationOptions) AddInterceptor(interceptors ...Interceptor) *ApplicationOptions {
+	o.Interceptors = append(o.Interceptors, interceptors...)
+	return o
+}
+
+func (o *ApplicationOptions) SetLogger(logger Logger) *ApplicationOptions {
+	o.Logger = logger
+	return o
+}
 
+func (o *ApplicationOptions) SetMetricsRecorder(recorder MetricsRecorder) *ApplicationOptions {
+	o.MetricsRecorder = recorder
 	return o
 }
 
 func (o *ApplicationOptions) AddInstanceId(instanceId string) *ApplicationOptions {
-	if len(instanceId) == 0 {
-		fmt.Println("Instance is is empty")
-	} else {
+	if len(instanceId) != 0 {
 		o.InstanceId = instanceId
 	}
 
@@ -87,7 +185,7 @@ func (o *ApplicationOptions) AddInstanceId(instanceId string) *ApplicationOption
 }
 
 func (o *ApplicationOptions) IsUseAkSk(useAkSk bool) *ApplicationOptions {
-	o.Credential.UseAkSk = useAkSk
+	o.credential().UseAkSk = useAkSk
 	return o
 }
 
@@ -95,3 +193,90 @@ func (o *ApplicationOptions) SetProjectId(projectId string) *ApplicationOptions
 	o.ProjectId = projectId
 	return o
 }
+
+// Credential为空时创建
+func (o *ApplicationOptions) credential() *Credentials {
+	if o.Credential == nil {
+		o.Credential = &Credentials{}
+	}
+
+	return o.Credential
+}
+
+// Validate 检查接入地址、项目ID、凭证和HTTP配置，NewClient在创建Client之前会调用
+func (o *ApplicationOptions) Validate() error {
+	if _, err := resolveEndpoint(*o); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+
+	if len(o.ProjectId) == 0 {
+		if len(o.Region) == 0 && iamTokenProviderOf(o.credentialsProvider()) == nil {
+			return fmt.Errorf("%w: project id is required when region and iam token provider are not configured", ErrInvalidConfig)
+		}
+	} else if strings.ContainsAny(o.ProjectId, "/?#% ") {
+		return fmt.Errorf("%w: invalid project id %s", ErrInvalidConfig, o.ProjectId)
+	}
+
+	if err := o.validateCredentials(); err != nil {
+		return err
+	}
+
+	if o.Timeout < 0 || o.ConnectTimeout < 0 {
+		return fmt.Errorf("%w: timeout must not be negative", ErrInvalidConfig)
+	}
+
+	if len(o.Proxy) != 0 {
+		if _, err := parseProxy(o.Proxy); err != nil {
+			return err
+		}
+	}
+
+	if o.TlsConfig != nil || len(o.Proxy) != 0 || o.ConnectTimeout > 0 {
+		if o.HttpClient != nil && o.HttpClient.Transport != nil {
+			if _, ok := o.HttpClient.Transport.(*http.Transport); !ok {
+				return fmt.Errorf("%w: tls config, proxy and connect timeout require *http.Transport", ErrInvalidConfig)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (o *ApplicationOptions) validateCredentials() error {
+	if o.CredentialsProvider != nil {
+		return nil
+	}
+
+	c := o.Credential
+	switch {
+	case c == nil:
+		return fmt.Errorf("%w: credentials are not configured", ErrInvalidConfig)
+	case c.UseAkSk && (len(c.Ak) == 0 || len(c.Sk) == 0):
+		return fmt.Errorf("%w: ak and sk are required", ErrInvalidConfig)
+	case !c.UseAkSk && len(c.Token) == 0 && c.TokenProvider == nil:
+		return fmt.Errorf("%w: token or token provider is required", ErrInvalidConfig)
+	}
+
+	return nil
+}
+
+// CredentialsProvider优先于Credential
+func (o *ApplicationOptions) credentialsProvider() CredentialsProvider {
+	if o.CredentialsProvider != nil {
+		return o.CredentialsProvider
+	}
+	if o.Credential != nil {
+		return o.Credential
+	}
+
+	return nil
+}
+
+func parseProxy(proxy string) (*url.URL, error) {
+	u, err := url.Parse(proxy)
+	if err != nil || len(u.Scheme) == 0 || len(u.Host) == 0 {
+		return nil, fmt.Errorf("%w: invalid proxy %s", ErrInvalidConfig, proxy)
+	}
+
+	return u, nil
+}
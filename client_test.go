@@ -0,0 +1,67 @@
+package iot
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+type stubRoundTripper struct{}
+
+func (stubRoundTripper) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestNewClientInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"no project id", []Option{WithAkSk("ak", "sk")}},
+		{"no credentials", []Option{WithProjectId("project")}},
+		{"empty ak", []Option{WithProjectId("project"), WithAkSk("", "sk")}},
+		{"empty token", []Option{WithProjectId("project"), WithToken("")}},
+		{"bad project id", []Option{WithProjectId("a/b"), WithAkSk("ak", "sk")}},
+		{"bad endpoint", []Option{WithEndpoint("http://"), WithProjectId("project"), WithAkSk("ak", "sk")}},
+		{"bad proxy", []Option{WithProjectId("project"), WithAkSk("ak", "sk"), WithProxy("::bad")}},
+		{"negative timeout", []Option{WithProjectId("project"), WithAkSk("ak", "sk"), WithTimeout(-1, 0)}},
+		{"bad rate limit", []Option{WithRateLimit(ApiGroupDevice, 0, 1)}},
+		{"proxy with custom transport", []Option{WithProjectId("project"), WithAkSk("ak", "sk"),
+			WithHttpClient(&http.Client{Transport: stubRoundTripper{}}), WithProxy("http://127.0.0.1:3128")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(tt.opts...)
+			if client != nil || !errors.Is(err, ErrInvalidConfig) {
+				t.Fatalf("NewClient() = %v, %v, want ErrInvalidConfig", client, err)
+			}
+			if _, ok := asApplicationError(err); ok {
+				t.Errorf("configuration error %v must not be an ApplicationError", err)
+			}
+			if IsNotFound(err) || IsConflict(err) || IsThrottled(err) {
+				t.Errorf("configuration error %v matched a platform error helper", err)
+			}
+		})
+	}
+}
+
+func TestNewClientDoesNotModifyHttpClient(t *testing.T) {
+	httpClient := &http.Client{}
+	_, err := NewClient(WithProjectId("project"), WithAkSk("ak", "sk"), WithHttpClient(httpClient),
+		WithProxy("http://127.0.0.1:3128"), WithTimeout(5e9, 1e9))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if httpClient.Transport != nil || httpClient.Timeout != 0 {
+		t.Errorf("http client was modified: %+v", httpClient)
+	}
+}
+
+func TestBuildersCreateCredential(t *testing.T) {
+	options := NewApplicationOptions().AddAk("ak").AddSk("sk").IsUseAkSk(true)
+	if options.Credential == nil || options.Credential.Ak != "ak" || options.Credential.Sk != "sk" || !options.Credential.UseAkSk {
+		t.Errorf("Credential = %+v", options.Credential)
+	}
+}
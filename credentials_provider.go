@@ -0,0 +1,233 @@
+package iot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 读取凭证的环境变量
+const (
+	EnvAccessKey       = "HUAWEICLOUD_SDK_AK"
+	EnvSecretKey       = "HUAWEICLOUD_SDK_SK"
+	EnvSecurityToken   = "HUAWEICLOUD_SDK_SECURITY_TOKEN"
+	EnvAuthToken       = "HUAWEICLOUD_SDK_TOKEN"
+	EnvCredentialsFile = "HUAWEICLOUD_SDK_CREDENTIALS_FILE"
+)
+
+// CredentialsProvider 没有找到凭证时返回的错误，ChainCredentialsProvider遇到该错误时会尝试下一个CredentialsProvider
+var ErrNoCredentials = errors.New("no credentials found")
+
+// CredentialsProvider 每次请求之前都会调用Retrieve获取凭证，因此可以在不重新创建Client的情况下轮换凭证。
+// 返回的凭证中UseAkSk为true时使用AK/SK签名（SecurityToken不为空时为临时AK/SK），否则使用Token鉴权。
+type CredentialsProvider interface {
+	Retrieve(ctx context.Context) (*Credentials, error)
+}
+
+// 鉴权失败后可以丢弃缓存凭证的CredentialsProvider，平台返回401时会调用Invalidate并重试一次
+type credentialsInvalidator interface {
+	Invalidate()
+}
+
+// Retrieve 使Credentials本身也是一个CredentialsProvider，设置了TokenProvider时从TokenProvider获取Token
+func (c *Credentials) Retrieve(ctx context.Context) (*Credentials, error) {
+	if !c.UseAkSk && c.TokenProvider != nil {
+		return c.TokenProvider.Retrieve(ctx)
+	}
+
+	return c, nil
+}
+
+func (p *IamTokenProvider) Retrieve(ctx context.Context) (*Credentials, error) {
+	token, err := p.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Credentials{Token: token}, nil
+}
+
+func NewStaticAkSkProvider(ak, sk string) CredentialsProvider {
+	return &Credentials{Ak: ak, Sk: sk, UseAkSk: true}
+}
+
+func NewStaticTokenProvider(token string) CredentialsProvider {
+	return &Credentials{Token: token}
+}
+
+// NewTemporaryAkSkProvider 使用临时AK/SK和SecurityToken，请求会携带X-Security-Token消息头
+func NewTemporaryAkSkProvider(ak, sk, securityToken string) CredentialsProvider {
+	return &Credentials{Ak: ak, Sk: sk, SecurityToken: securityToken, UseAkSk: true}
+}
+
+// EnvCredentialsProvider 每次从环境变量读取凭证，AK/SK优先于Token
+type EnvCredentialsProvider struct {
+}
+
+func NewEnvCredentialsProvider() *EnvCredentialsProvider {
+	return &EnvCredentialsProvider{}
+}
+
+func (p *EnvCredentialsProvider) Retrieve(ctx context.Context) (*Credentials, error) {
+	ak, sk := os.Getenv(EnvAccessKey), os.Getenv(EnvSecretKey)
+	if len(ak) != 0 && len(sk) != 0 {
+		return &Credentials{
+			Ak:            ak,
+			Sk:            sk,
+			SecurityToken: os.Getenv(EnvSecurityToken),
+			UseAkSk:       true,
+		}, nil
+	}
+
+	if token := os.Getenv(EnvAuthToken); len(token) != 0 {
+		return &Credentials{Token: token}, nil
+	}
+
+	return nil, fmt.Errorf("%w in environment variables", ErrNoCredentials)
+}
+
+// FileCredentialsProvider 从JSON格式的配置文件中读取凭证，文件修改后自动重新加载，文件格式为：
+//
+//	{"ak": "xxx", "sk": "xxx", "security_token": "xxx", "token": "xxx"}
+type FileCredentialsProvider struct {
+	path string
+
+	lock        sync.Mutex
+	modTime     time.Time
+	credentials *Credentials
+}
+
+// NewFileCredentialsProvider path为空时依次使用环境变量HUAWEICLOUD_SDK_CREDENTIALS_FILE和~/.huaweicloud/credentials.json
+func NewFileCredentialsProvider(path string) *FileCredentialsProvider {
+	if len(path) == 0 {
+		path = os.Getenv(EnvCredentialsFile)
+	}
+	if len(path) == 0 {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, ".huaweicloud", "credentials.json")
+		}
+	}
+
+	return &FileCredentialsProvider{
+		path: path,
+	}
+}
+
+func (p *FileCredentialsProvider) Retrieve(ctx context.Context) (*Credentials, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if len(p.path) == 0 {
+		return nil, fmt.Errorf("%w: credentials file path is empty", ErrNoCredentials)
+	}
+
+	info, err := os.Stat(p.path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s does not exist", ErrNoCredentials, p.path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if p.credentials != nil && info.ModTime().Equal(p.modTime) {
+		return p.credentials, nil
+	}
+
+	content, err := ioutil.ReadFile(p.path)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &struct {
+		Ak            string `json:"ak"`
+		Sk            string `json:"sk"`
+		SecurityToken string `json:"security_token"`
+		Token         string `json:"token"`
+	}{}
+	err = json.Unmarshal(content, file)
+	if err != nil {
+		return nil, fmt.Errorf("parse credentials file %s failed: %w", p.path, err)
+	}
+
+	switch {
+	case len(file.Ak) != 0 && len(file.Sk) != 0:
+		p.credentials = &Credentials{Ak: file.Ak, Sk: file.Sk, SecurityToken: file.SecurityToken, UseAkSk: true}
+	case len(file.Token) != 0:
+		p.credentials = &Credentials{Token: file.Token}
+	default:
+		return nil, fmt.Errorf("%w in %s", ErrNoCredentials, p.path)
+	}
+	p.modTime = info.ModTime()
+
+	return p.credentials, nil
+}
+
+// Invalidate 丢弃缓存的凭证，下次调用Retrieve时重新读取文件
+func (p *FileCredentialsProvider) Invalidate() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.credentials = nil
+}
+
+// ChainCredentialsProvider 按顺序尝试每个CredentialsProvider，返回第一个成功获取的凭证。
+// 只有返回ErrNoCredentials时才会尝试下一个CredentialsProvider，其他错误（例如配置文件格式错误）直接返回
+type ChainCredentialsProvider struct {
+	providers []CredentialsProvider
+}
+
+func NewChainCredentialsProvider(providers ...CredentialsProvider) *ChainCredentialsProvider {
+	return &ChainCredentialsProvider{
+		providers: providers,
+	}
+}
+
+// NewDefaultCredentialsProvider 依次从环境变量和配置文件中读取凭证
+func NewDefaultCredentialsProvider() *ChainCredentialsProvider {
+	return NewChainCredentialsProvider(NewEnvCredentialsProvider(), NewFileCredentialsProvider(""))
+}
+
+func (p *ChainCredentialsProvider) Retrieve(ctx context.Context) (*Credentials, error) {
+	messages := make([]string, 0, len(p.providers))
+	for _, provider := range p.providers {
+		credentials, err := provider.Retrieve(ctx)
+		if err == nil {
+			return credentials, nil
+		}
+		if !errors.Is(err, ErrNoCredentials) {
+			return nil, err
+		}
+
+		messages = append(messages, err.Error())
+	}
+
+	return nil, fmt.Errorf("%w in chain: [%s]", ErrNoCredentials, strings.Join(messages, "; "))
+}
+
+func (p *ChainCredentialsProvider) Invalidate() {
+	for _, provider := range p.providers {
+		if invalidator := asCredentialsInvalidator(provider); invalidator != nil {
+			invalidator.Invalidate()
+		}
+	}
+}
+
+// 静态凭证不需要丢弃，只有使用TokenProvider的Credentials才需要重新获取Token
+func asCredentialsInvalidator(provider CredentialsProvider) credentialsInvalidator {
+	if credentials, ok := provider.(*Credentials); ok {
+		if credentials.UseAkSk || credentials.TokenProvider == nil {
+			return nil
+		}
+		return credentials.TokenProvider
+	}
+
+	invalidator, _ := provider.(credentialsInvalidator)
+	return invalidator
+}
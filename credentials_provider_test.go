@@ -0,0 +1,252 @@
+package iot
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 设置环境变量，测试结束后恢复原来的值
+func setTestEnv(t *testing.T, values map[string]string) {
+	t.Helper()
+
+	for _, key := range []string{EnvAccessKey, EnvSecretKey, EnvSecurityToken, EnvAuthToken} {
+		old, exists := os.LookupEnv(key)
+		if value, ok := values[key]; ok {
+			_ = os.Setenv(key, value)
+		} else {
+			_ = os.Unsetenv(key)
+		}
+
+		key := key
+		t.Cleanup(func() {
+			if exists {
+				_ = os.Setenv(key, old)
+			} else {
+				_ = os.Unsetenv(key)
+			}
+		})
+	}
+}
+
+func writeCredentialsFile(t *testing.T, path, content string, modTime time.Time) {
+	t.Helper()
+
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type stubCredentialsProvider struct {
+	credentials *Credentials
+	err         error
+	calls       int
+	invalidated int
+}
+
+func (p *stubCredentialsProvider) Retrieve(ctx context.Context) (*Credentials, error) {
+	p.calls++
+	return p.credentials, p.err
+}
+
+func (p *stubCredentialsProvider) Invalidate() {
+	p.invalidated++
+}
+
+func TestEnvCredentialsProvider(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected *Credentials
+	}{
+		{
+			name:     "ak/sk",
+			env:      map[string]string{EnvAccessKey: "ak", EnvSecretKey: "sk"},
+			expected: &Credentials{Ak: "ak", Sk: "sk", UseAkSk: true},
+		},
+		{
+			name:     "temporary ak/sk",
+			env:      map[string]string{EnvAccessKey: "ak", EnvSecretKey: "sk", EnvSecurityToken: "security-token"},
+			expected: &Credentials{Ak: "ak", Sk: "sk", SecurityToken: "security-token", UseAkSk: true},
+		},
+		{
+			name:     "ak/sk before token",
+			env:      map[string]string{EnvAccessKey: "ak", EnvSecretKey: "sk", EnvAuthToken: "token"},
+			expected: &Credentials{Ak: "ak", Sk: "sk", UseAkSk: true},
+		},
+		{
+			name:     "token",
+			env:      map[string]string{EnvAuthToken: "token"},
+			expected: &Credentials{Token: "token"},
+		},
+		{
+			name:     "ak without sk",
+			env:      map[string]string{EnvAccessKey: "ak", EnvAuthToken: "token"},
+			expected: &Credentials{Token: "token"},
+		},
+		{
+			name: "nothing",
+			env:  map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestEnv(t, tt.env)
+
+			credentials, err := NewEnvCredentialsProvider().Retrieve(context.Background())
+			if tt.expected == nil {
+				if !errors.Is(err, ErrNoCredentials) {
+					t.Errorf("Retrieve() = %+v, %v, want ErrNoCredentials", credentials, err)
+				}
+				return
+			}
+			if err != nil || *credentials != *tt.expected {
+				t.Errorf("Retrieve() = %+v, %v, want %+v", credentials, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFileCredentialsProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	provider := NewFileCredentialsProvider(path)
+
+	if _, err := provider.Retrieve(context.Background()); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("missing file error = %v, want ErrNoCredentials", err)
+	}
+
+	modTime := time.Now().Add(-time.Hour)
+	writeCredentialsFile(t, path, `{"ak": "ak-1", "sk": "sk-1", "security_token": "security-token"}`, modTime)
+	credentials, err := provider.Retrieve(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *credentials != (Credentials{Ak: "ak-1", Sk: "sk-1", SecurityToken: "security-token", UseAkSk: true}) {
+		t.Errorf("credentials = %+v", credentials)
+	}
+
+	// 修改时间不变时使用缓存的凭证
+	writeCredentialsFile(t, path, `{"ak": "ak-2", "sk": "sk-2"}`, modTime)
+	if credentials, _ := provider.Retrieve(context.Background()); credentials.Ak != "ak-1" {
+		t.Errorf("credentials = %+v, want cached ak-1", credentials)
+	}
+
+	// Invalidate之后重新读取文件
+	provider.Invalidate()
+	if credentials, _ := provider.Retrieve(context.Background()); credentials.Ak != "ak-2" || len(credentials.SecurityToken) != 0 {
+		t.Errorf("credentials after Invalidate = %+v", credentials)
+	}
+
+	// 文件修改后重新加载
+	writeCredentialsFile(t, path, `{"token": "token"}`, modTime.Add(time.Minute))
+	if credentials, _ := provider.Retrieve(context.Background()); *credentials != (Credentials{Token: "token"}) {
+		t.Errorf("credentials after reload = %+v", credentials)
+	}
+
+	writeCredentialsFile(t, path, `{}`, modTime.Add(2*time.Minute))
+	if _, err := provider.Retrieve(context.Background()); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("empty file error = %v, want ErrNoCredentials", err)
+	}
+
+	writeCredentialsFile(t, path, `{"ak": `, modTime.Add(3*time.Minute))
+	if _, err := provider.Retrieve(context.Background()); err == nil || errors.Is(err, ErrNoCredentials) {
+		t.Errorf("malformed file error = %v, want a parse error", err)
+	}
+}
+
+func TestTemporaryAkSkProvider(t *testing.T) {
+	headers := make(chan http.Header, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(WithEndpoint(server.URL), WithProjectId("project"),
+		WithCredentialsProvider(NewTemporaryAkSkProvider("ak", "sk", "security-token")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.ShowDevice("device"); err != nil {
+		t.Fatal(err)
+	}
+
+	header := <-headers
+	if header.Get("X-Security-Token") != "security-token" {
+		t.Errorf("X-Security-Token = %s", header.Get("X-Security-Token"))
+	}
+	if !strings.Contains(header.Get("Authorization"), "Access=ak") || len(header.Get("X-Auth-Token")) != 0 {
+		t.Errorf("Authorization = %s, X-Auth-Token = %s", header.Get("Authorization"), header.Get("X-Auth-Token"))
+	}
+}
+
+func TestChainCredentialsProviderFallThrough(t *testing.T) {
+	first := &stubCredentialsProvider{err: ErrNoCredentials}
+	second := &stubCredentialsProvider{credentials: &Credentials{Token: "token"}}
+	third := &stubCredentialsProvider{credentials: &Credentials{Token: "unused"}}
+	chain := NewChainCredentialsProvider(first, second, third)
+
+	credentials, err := chain.Retrieve(context.Background())
+	if err != nil || credentials.Token != "token" {
+		t.Errorf("Retrieve() = %+v, %v", credentials, err)
+	}
+	if first.calls != 1 || second.calls != 1 || third.calls != 0 {
+		t.Errorf("calls = %d, %d, %d", first.calls, second.calls, third.calls)
+	}
+
+	chain.Invalidate()
+	if first.invalidated != 1 || second.invalidated != 1 || third.invalidated != 1 {
+		t.Errorf("invalidated = %d, %d, %d", first.invalidated, second.invalidated, third.invalidated)
+	}
+
+	empty := NewChainCredentialsProvider(&stubCredentialsProvider{err: ErrNoCredentials}, NewFileCredentialsProvider(filepath.Join(t.TempDir(), "missing.json")))
+	if _, err := empty.Retrieve(context.Background()); !errors.Is(err, ErrNoCredentials) || !strings.Contains(err.Error(), "missing.json") {
+		t.Errorf("empty chain error = %v", err)
+	}
+}
+
+func TestChainCredentialsProviderPropagatesErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	writeCredentialsFile(t, path, `not json`, time.Now())
+
+	iamErr := &ApplicationError{ErrorCode: "IAM.0001", StatusCode: http.StatusUnauthorized}
+	tests := []struct {
+		name     string
+		provider CredentialsProvider
+		expected error
+	}{
+		{"iam failure", &stubCredentialsProvider{err: iamErr}, iamErr},
+		{"canceled", &stubCredentialsProvider{err: context.Canceled}, context.Canceled},
+		{"malformed file", NewFileCredentialsProvider(path), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &stubCredentialsProvider{credentials: &Credentials{Token: "token"}}
+			chain := NewChainCredentialsProvider(&stubCredentialsProvider{err: ErrNoCredentials}, tt.provider, next)
+
+			credentials, err := chain.Retrieve(context.Background())
+			if credentials != nil || err == nil || errors.Is(err, ErrNoCredentials) {
+				t.Fatalf("Retrieve() = %+v, %v, want the provider error", credentials, err)
+			}
+			if tt.expected != nil && err != tt.expected {
+				t.Errorf("error = %v, want %v", err, tt.expected)
+			}
+			if next.calls != 0 {
+				t.Error("the next provider is called after a non ErrNoCredentials error")
+			}
+		})
+	}
+}
@@ -45,6 +45,63 @@ type DeviceSyncCommandResponse struct {
 	Response  interface{} `json:"response"`
 }
 
+// 异步命令下发策略
+const (
+	SendStrategyImmediately = "immediately"
+	SendStrategyDelay       = "delay"
+)
+
+// 异步命令状态
+const (
+	AsyncCommandPending    = "PENDING"
+	AsyncCommandExpired    = "EXPIRED"
+	AsyncCommandSuccessful = "SUCCESSFUL"
+	AsyncCommandFailed     = "FAILED"
+	AsyncCommandTimeout    = "TIMEOUT"
+	AsyncCommandDelivered  = "DELIVERED"
+	AsyncCommandSent       = "SENT"
+)
+
+// 异步命令，ExpireTime为命令在平台缓存的时长（秒），为0时不缓存，设备不在线时直接下发失败
+type DeviceAsyncCommandRequest struct {
+	ServiceId    string      `json:"service_id,omitempty"`
+	CommandName  string      `json:"command_name,omitempty"`
+	Paras        interface{} `json:"paras"`
+	ExpireTime   int         `json:"expire_time,omitempty"`
+	SendStrategy string      `json:"send_strategy"`
+}
+
+type DeviceAsyncCommand struct {
+	DeviceId      string      `json:"device_id"`
+	CommandId     string      `json:"command_id"`
+	ServiceId     string      `json:"service_id"`
+	CommandName   string      `json:"command_name"`
+	Paras         interface{} `json:"paras"`
+	ExpireTime    int         `json:"expire_time"`
+	Status        string      `json:"status"`
+	Result        interface{} `json:"result"`
+	CreatedTime   string      `json:"created_time"`
+	SentTime      string      `json:"sent_time"`
+	DeliveredTime string      `json:"delivered_time"`
+	ResponseTime  string      `json:"response_time"`
+	SendStrategy  string      `json:"send_strategy"`
+}
+
+type ListDeviceAsyncCommandsRequest struct {
+	StartTime   string `json:"start_time,omitempty"`
+	EndTime     string `json:"end_time,omitempty"`
+	Status      string `json:"status,omitempty"`
+	CommandName string `json:"command_name,omitempty"`
+	Limit       int    `json:"limit,omitempty"`
+	Marker      string `json:"marker,omitempty"`
+	Offset      int    `json:"offset,omitempty"`
+}
+
+type ListDeviceAsyncCommandsResponse struct {
+	Commands []DeviceAsyncCommand `json:"commands"`
+	Page     Page                 `json:"page"`
+}
+
 // 设备管理
 
 type ListDeviceResponse struct {
@@ -3,6 +3,6 @@ module huaweicloud-iot-application-sdk-go
 go 1.15
 
 require (
+	github.com/Azure/go-amqp v0.16.4
 	github.com/go-resty/resty/v2 v2.4.0
-	github.com/golang/glog v0.0.0-20160126235308-23def4e6c14b
 )
@@ -0,0 +1,167 @@
+package iot
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+var errHttpPushUnauthorized = errors.New("http push request unauthorized")
+
+type HttpPushReceiverOptions struct {
+	// 推送请求需要携带的Token，为空时不校验。Token从TokenHeader指定的消息头中读取，消息头不存在时从查询参数token中读取，
+	// 可以在数据转发规则的推送地址中携带token查询参数
+	Token       string
+	TokenHeader string
+
+	// 自定义校验，例如校验来源地址或者网关添加的消息头，返回错误时拒绝请求
+	Verify func(request *http.Request, body []byte) error
+
+	// 消息体的最大字节数，默认1MB
+	MaxBodySize int64
+
+	// 为空时不输出日志
+	Logger Logger
+}
+
+func NewHttpPushReceiverOptions() *HttpPushReceiverOptions {
+	return &HttpPushReceiverOptions{
+		TokenHeader: "X-Auth-Token",
+		MaxBodySize: 1 << 20,
+	}
+}
+
+func (o *HttpPushReceiverOptions) SetToken(token string) *HttpPushReceiverOptions {
+	o.Token = token
+	return o
+}
+
+func (o *HttpPushReceiverOptions) SetVerify(verify func(request *http.Request, body []byte) error) *HttpPushReceiverOptions {
+	o.Verify = verify
+	return o
+}
+
+func (o *HttpPushReceiverOptions) SetLogger(logger Logger) *HttpPushReceiverOptions {
+	o.Logger = logger
+	return o
+}
+
+// HttpPushReceiver 接收平台HTTP推送的http.Handler。
+// 处理成功时返回200，平台收到非2xx的响应后会重新推送：鉴权失败返回401，handler返回错误时返回500。
+// 消息格式错误时重新推送也无法处理，记录Warn日志后返回200。
+type HttpPushReceiver struct {
+	options HttpPushReceiverOptions
+	handler PushMessageHandler
+}
+
+func NewHttpPushReceiver(options HttpPushReceiverOptions, handler PushMessageHandler) (*HttpPushReceiver, error) {
+	if handler == nil {
+		return nil, errors.New("push message handler is nil")
+	}
+
+	if len(options.TokenHeader) == 0 {
+		options.TokenHeader = "X-Auth-Token"
+	}
+	if options.MaxBodySize <= 0 {
+		options.MaxBodySize = 1 << 20
+	}
+
+	options.Logger = newRedactingLogger(options.Logger)
+
+	return &HttpPushReceiver{
+		options: options,
+		handler: handler,
+	}, nil
+}
+
+func (r *HttpPushReceiver) ServeHTTP(w http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(request.Body, r.options.MaxBodySize+1))
+	if err != nil {
+		http.Error(w, "read body failed", http.StatusBadRequest)
+		return
+	}
+	if int64(len(body)) > r.options.MaxBodySize {
+		http.Error(w, "body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if err := r.verify(request, body); err != nil {
+		r.options.Logger.Warn("reject http push", "remote_addr", request.RemoteAddr, "error", err)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	err = DispatchPushMessage(request.Context(), body, r.handler)
+	if errors.Is(err, ErrInvalidPushMessage) {
+		r.options.Logger.Warn("drop invalid http push message", "error", err)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if err != nil {
+		r.options.Logger.Warn("handle http push message failed", "error", err)
+		http.Error(w, "handle push message failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (r *HttpPushReceiver) verify(request *http.Request, body []byte) error {
+	if len(r.options.Token) != 0 {
+		token := request.Header.Get(r.options.TokenHeader)
+		if len(token) == 0 {
+			token = request.URL.Query().Get("token")
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(r.options.Token)) != 1 {
+			return fmt.Errorf("%w: invalid token", errHttpPushUnauthorized)
+		}
+	}
+
+	if r.options.Verify != nil {
+		return r.options.Verify(request, body)
+	}
+
+	return nil
+}
+
+// NewHttpPushTLSConfig 构造接收HTTPS推送的服务端TLS配置。
+// 服务端证书需要由在平台上传的CA证书签发；clientCAFile不为空时要求并校验客户端证书。
+func NewHttpPushTLSConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if len(clientCAFile) != 0 {
+		caBytes, err := ioutil.ReadFile(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caBytes) {
+			return nil, fmt.Errorf("no certificate found in %s", clientCAFile)
+		}
+
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return config, nil
+}
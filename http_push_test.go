@@ -0,0 +1,105 @@
+package iot
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testDeviceMessage = `{"resource":"device.message","event":"report","event_time":"20151212T121212Z",
+"notify_data":{"header":{"device_id":"product_node","product_id":"product","node_id":"node"},
+"body":{"content":"hello"}}}`
+
+type recordingPushHandler struct {
+	BasePushMessageHandler
+	err      error
+	messages []*DeviceMessageReport
+}
+
+func (h *recordingPushHandler) OnDeviceMessageReport(ctx context.Context, message *PushMessage, body *DeviceMessageReport) error {
+	h.messages = append(h.messages, body)
+	return h.err
+}
+
+func TestNewHttpPushReceiverNilHandler(t *testing.T) {
+	if _, err := NewHttpPushReceiver(*NewHttpPushReceiverOptions(), nil); err == nil {
+		t.Error("NewHttpPushReceiver() with nil handler should return error")
+	}
+}
+
+func TestHttpPushReceiverServeHTTP(t *testing.T) {
+	errVerify := errors.New("unknown source")
+
+	tests := []struct {
+		name       string
+		options    *HttpPushReceiverOptions
+		handlerErr error
+		method     string
+		target     string
+		header     map[string]string
+		body       string
+		wantStatus int
+		wantCalls  int
+	}{
+		{name: "ok", method: http.MethodPost, body: testDeviceMessage, wantStatus: http.StatusOK, wantCalls: 1},
+		{name: "method not allowed", method: http.MethodGet, wantStatus: http.StatusMethodNotAllowed},
+		{name: "body too large", options: NewHttpPushReceiverOptions(), method: http.MethodPost,
+			body: strings.Repeat(" ", 1<<20+1), wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "missing token", options: NewHttpPushReceiverOptions().SetToken("token"), method: http.MethodPost,
+			body: testDeviceMessage, wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", options: NewHttpPushReceiverOptions().SetToken("token"), method: http.MethodPost,
+			header: map[string]string{"X-Auth-Token": "wrong"}, body: testDeviceMessage, wantStatus: http.StatusUnauthorized},
+		{name: "token in header", options: NewHttpPushReceiverOptions().SetToken("token"), method: http.MethodPost,
+			header: map[string]string{"X-Auth-Token": "token"}, body: testDeviceMessage, wantStatus: http.StatusOK, wantCalls: 1},
+		{name: "token in query", options: NewHttpPushReceiverOptions().SetToken("token"), method: http.MethodPost,
+			target: "/push?token=token", body: testDeviceMessage, wantStatus: http.StatusOK, wantCalls: 1},
+		{name: "verify failed", method: http.MethodPost, body: testDeviceMessage, wantStatus: http.StatusUnauthorized,
+			options: NewHttpPushReceiverOptions().SetVerify(func(request *http.Request, body []byte) error {
+				return errVerify
+			})},
+		{name: "malformed body is acknowledged", method: http.MethodPost, body: "{", wantStatus: http.StatusOK},
+		{name: "empty resource is acknowledged", method: http.MethodPost, body: `{"event":"report"}`, wantStatus: http.StatusOK},
+		{name: "malformed notify body is acknowledged", method: http.MethodPost,
+			body: `{"resource":"device.message","event":"report","notify_data":{"body":[]}}`, wantStatus: http.StatusOK},
+		{name: "handler error", method: http.MethodPost, body: testDeviceMessage, handlerErr: errors.New("db down"),
+			wantStatus: http.StatusInternalServerError, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := tt.options
+			if options == nil {
+				options = NewHttpPushReceiverOptions()
+			}
+			handler := &recordingPushHandler{err: tt.handlerErr}
+			receiver, err := NewHttpPushReceiver(*options, handler)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			target := tt.target
+			if len(target) == 0 {
+				target = "/push"
+			}
+			request := httptest.NewRequest(tt.method, target, strings.NewReader(tt.body))
+			for k, v := range tt.header {
+				request.Header.Set(k, v)
+			}
+			recorder := httptest.NewRecorder()
+			receiver.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body %s", recorder.Code, tt.wantStatus, recorder.Body.String())
+			}
+			if len(handler.messages) != tt.wantCalls {
+				t.Errorf("handler called %d times, want %d", len(handler.messages), tt.wantCalls)
+			}
+			if tt.wantCalls > 0 && string(handler.messages[0].Content) != `"hello"` {
+				t.Errorf("content = %s", handler.messages[0].Content)
+			}
+		})
+	}
+}
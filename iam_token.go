@@ -0,0 +1,341 @@
+package iot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/go-resty/resty/v2"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const defaultIamEndpoint = "https://iam.myhuaweicloud.com"
+
+type IamTokenOptions struct {
+	// IAM服务地址，默认为https://iam.myhuaweicloud.com，测试时可以替换为本地的模拟服务
+	Endpoint string
+
+	// 使用用户名和密码获取Token，DomainName为账号名，UserName为IAM用户名
+	DomainName string
+	UserName   string
+	Password   string
+
+	// 使用AK/SK获取Token，设置了Ak时优先使用AK/SK。使用IAM的hw_ak_sk认证方式，需要IAM支持该方式；
+	// 华为云公有云上推荐直接使用AK/SK签名，不需要获取Token
+	Ak string
+	Sk string
+
+	// Token的作用范围，ProjectId和ProjectName（区域名称，例如cn-north-4）二选一，ProjectId优先
+	ProjectId   string
+	ProjectName string
+
+	// 在Token过期之前多久刷新Token，默认1小时
+	RefreshBefore time.Duration
+	Timeout       time.Duration
+}
+
+func NewIamTokenOptions() *IamTokenOptions {
+	return &IamTokenOptions{
+		Endpoint:      defaultIamEndpoint,
+		RefreshBefore: time.Hour,
+		Timeout:       30 * time.Second,
+	}
+}
+
+func (o *IamTokenOptions) SetEndpoint(endpoint string) *IamTokenOptions {
+	o.Endpoint = endpoint
+	return o
+}
+
+func (o *IamTokenOptions) SetPassword(domainName, userName, password string) *IamTokenOptions {
+	o.DomainName = domainName
+	o.UserName = userName
+	o.Password = password
+	return o
+}
+
+func (o *IamTokenOptions) SetAkSk(ak, sk string) *IamTokenOptions {
+	o.Ak = ak
+	o.Sk = sk
+	return o
+}
+
+func (o *IamTokenOptions) SetProjectId(projectId string) *IamTokenOptions {
+	o.ProjectId = projectId
+	return o
+}
+
+func (o *IamTokenOptions) SetProjectName(projectName string) *IamTokenOptions {
+	o.ProjectName = projectName
+	return o
+}
+
+// IamTokenProvider 从IAM获取Token并缓存，Token即将过期时自动刷新，可以被多个goroutine并发使用
+type IamTokenProvider struct {
+	options IamTokenOptions
+	client  *resty.Client
+
+	lock      sync.Mutex
+	token     string
+	expiresAt time.Time
+	projectId string
+	// 正在进行的刷新，不为空时其他goroutine等待它的结果，不会重复请求IAM
+	refreshing *iamTokenRefresh
+}
+
+type iamTokenRefresh struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+func NewIamTokenProvider(options IamTokenOptions) (*IamTokenProvider, error) {
+	if len(options.Ak) == 0 && (len(options.UserName) == 0 || len(options.Password) == 0 || len(options.DomainName) == 0) {
+		return nil, errors.New("iam ak/sk or domain name, user name and password is required")
+	}
+	if len(options.Ak) != 0 && len(options.Sk) == 0 {
+		return nil, errors.New("iam sk is empty")
+	}
+	if len(options.ProjectId) == 0 && len(options.ProjectName) == 0 {
+		return nil, errors.New("iam project id or project name is required")
+	}
+
+	if len(options.Endpoint) == 0 {
+		options.Endpoint = defaultIamEndpoint
+	}
+	if options.RefreshBefore <= 0 {
+		options.RefreshBefore = time.Hour
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = 30 * time.Second
+	}
+
+	client := resty.New().
+		SetHostURL(options.Endpoint).
+		SetTimeout(options.Timeout)
+
+	return &IamTokenProvider{
+		options: options,
+		client:  client,
+	}, nil
+}
+
+// Token 返回缓存的Token，Token进入RefreshBefore时间后在后台刷新，刷新期间和刷新失败时仍然返回未过期的Token；
+// Token不存在或者已经过期时等待刷新完成。
+// 刷新时不持有锁，并发的调用共享同一次刷新，每个调用只等待到自己的ctx结束
+func (p *IamTokenProvider) Token(ctx context.Context) (string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	p.lock.Lock()
+	now := time.Now()
+	valid := len(p.token) != 0 && now.Before(p.expiresAt)
+	if valid && now.Add(p.options.RefreshBefore).Before(p.expiresAt) {
+		token := p.token
+		p.lock.Unlock()
+		return token, nil
+	}
+
+	refresh := p.refreshing
+	if refresh == nil {
+		refresh = &iamTokenRefresh{done: make(chan struct{})}
+		p.refreshing = refresh
+		// 第一个调用者的ctx被取消时不影响其他等待的调用，请求时间由Timeout限制
+		go p.refresh(refresh)
+	}
+
+	if valid {
+		token := p.token
+		p.lock.Unlock()
+		return token, nil
+	}
+	p.lock.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case <-refresh.done:
+		return refresh.token, refresh.err
+	}
+}
+
+// Invalidate 丢弃缓存的Token，下次调用Token时重新获取，平台返回401时使用
+func (p *IamTokenProvider) Invalidate() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.token = ""
+	p.expiresAt = time.Time{}
+}
+
+// ProjectId 返回Token所属项目的ID，获取Token之前为空
+func (p *IamTokenProvider) ProjectId() string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.projectId
+}
+
+func (p *IamTokenProvider) refresh(refresh *iamTokenRefresh) {
+	response, token, err := p.requestToken(context.Background())
+
+	p.lock.Lock()
+	if err == nil {
+		p.token = token
+		p.expiresAt = response.expiresAt
+		p.projectId = response.Token.Project.Id
+	}
+	p.refreshing = nil
+	p.lock.Unlock()
+
+	refresh.token = token
+	refresh.err = err
+	close(refresh.done)
+}
+
+func (p *IamTokenProvider) requestToken(ctx context.Context) (*iamTokenResponse, string, error) {
+	body, err := json.Marshal(p.authRequest())
+	if err != nil {
+		return nil, "", err
+	}
+
+	httpResponse, err := p.client.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", "application/json;charset=utf8").
+		SetBody(body).
+		Post("/v3/auth/tokens")
+	if err != nil {
+		return nil, "", err
+	}
+
+	if httpResponse.StatusCode() != http.StatusCreated {
+		return nil, "", convertIamResponseToApplicationError(httpResponse)
+	}
+
+	token := httpResponse.Header().Get("X-Subject-Token")
+	if len(token) == 0 {
+		return nil, "", errors.New("iam response does not contain X-Subject-Token")
+	}
+
+	response := &iamTokenResponse{}
+	err = json.Unmarshal(httpResponse.Body(), response)
+	if err != nil {
+		return nil, "", err
+	}
+
+	response.expiresAt, err = time.Parse(time.RFC3339, response.Token.ExpiresAt)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return response, token, nil
+}
+
+func (p *IamTokenProvider) authRequest() *iamAuthRequest {
+	request := &iamAuthRequest{}
+	request.Auth.Scope.Project = iamProject{
+		Id: p.options.ProjectId,
+	}
+	if len(p.options.ProjectId) == 0 {
+		request.Auth.Scope.Project.Name = p.options.ProjectName
+	}
+
+	if len(p.options.Ak) != 0 {
+		request.Auth.Identity.Methods = []string{"hw_ak_sk"}
+		request.Auth.Identity.HwAkSk = &iamAkSk{
+			Access: iamKey{Key: p.options.Ak},
+			Secret: iamKey{Key: p.options.Sk},
+		}
+		return request
+	}
+
+	request.Auth.Identity.Methods = []string{"password"}
+	request.Auth.Identity.Password = &iamPassword{
+		User: iamUser{
+			Name:     p.options.UserName,
+			Password: p.options.Password,
+			Domain:   iamDomain{Name: p.options.DomainName},
+		},
+	}
+
+	return request
+}
+
+// IAM的错误响应格式为{"error":{"code":"...","message":"..."}}，部分接口与平台一致
+func convertIamResponseToApplicationError(response *resty.Response) error {
+	err := convertResponseToApplicationError(response)
+
+	ae, ok := err.(*ApplicationError)
+	if !ok || len(ae.ErrorCode) != 0 {
+		return err
+	}
+
+	iamError := &iamErrorResponse{}
+	if json.Unmarshal(response.Body(), iamError) == nil && len(iamError.Error.Code) != 0 {
+		ae.ErrorCode = iamError.Error.Code
+		ae.ErrorMsg = iamError.Error.Message
+	}
+
+	return ae
+}
+
+type iamAuthRequest struct {
+	Auth struct {
+		Identity iamIdentity `json:"identity"`
+		Scope    struct {
+			Project iamProject `json:"project"`
+		} `json:"scope"`
+	} `json:"auth"`
+}
+
+type iamIdentity struct {
+	Methods  []string     `json:"methods"`
+	Password *iamPassword `json:"password,omitempty"`
+	HwAkSk   *iamAkSk     `json:"hw_ak_sk,omitempty"`
+}
+
+type iamPassword struct {
+	User iamUser `json:"user"`
+}
+
+type iamUser struct {
+	Name     string    `json:"name"`
+	Password string    `json:"password"`
+	Domain   iamDomain `json:"domain"`
+}
+
+type iamDomain struct {
+	Name string `json:"name"`
+}
+
+type iamAkSk struct {
+	Access iamKey `json:"access"`
+	Secret iamKey `json:"secret"`
+}
+
+type iamKey struct {
+	Key string `json:"key"`
+}
+
+type iamProject struct {
+	Id   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+type iamTokenResponse struct {
+	Token struct {
+		ExpiresAt string     `json:"expires_at"`
+		Project   iamProject `json:"project"`
+	} `json:"token"`
+
+	expiresAt time.Time
+}
+
+type iamErrorResponse struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
@@ -0,0 +1,321 @@
+package iot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 模拟IAM的/v3/auth/tokens接口，每次返回不同的Token
+type iamStub struct {
+	*httptest.Server
+	count     int32
+	expiresIn time.Duration
+	status    int
+	release   chan struct{}
+	bodies    chan []byte
+}
+
+func newIamStub(expiresIn time.Duration) *iamStub {
+	stub := &iamStub{expiresIn: expiresIn, status: http.StatusCreated, bodies: make(chan []byte, 100)}
+	stub.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&stub.count, 1)
+		body, _ := ioutil.ReadAll(r.Body)
+		stub.bodies <- body
+		if stub.release != nil {
+			<-stub.release
+		}
+
+		if r.Method != http.MethodPost || r.URL.Path != "/v3/auth/tokens" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if stub.status != http.StatusCreated {
+			w.WriteHeader(stub.status)
+			_, _ = w.Write([]byte(`{"error":{"code":"IAM.0001","message":"invalid credentials"}}`))
+			return
+		}
+
+		w.Header().Set("X-Subject-Token", fmt.Sprintf("token-%d", n))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprintf(w, `{"token":{"expires_at":"%s","project":{"id":"project-id","name":"cn-north-4"}}}`,
+			time.Now().Add(stub.expiresIn).UTC().Format(time.RFC3339))
+	}))
+
+	return stub
+}
+
+func (s *iamStub) requests() int32 {
+	return atomic.LoadInt32(&s.count)
+}
+
+func newTestIamTokenProvider(t *testing.T, endpoint string, refreshBefore time.Duration) *IamTokenProvider {
+	t.Helper()
+
+	options := NewIamTokenOptions().SetEndpoint(endpoint).SetPassword("domain", "user", "password").SetProjectName("cn-north-4")
+	options.RefreshBefore = refreshBefore
+	provider, err := NewIamTokenProvider(*options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return provider
+}
+
+func TestIamTokenProviderCache(t *testing.T) {
+	stub := newIamStub(24 * time.Hour)
+	defer stub.Close()
+
+	provider := newTestIamTokenProvider(t, stub.URL, time.Hour)
+	if provider.ProjectId() != "" {
+		t.Errorf("ProjectId() before Token = %s", provider.ProjectId())
+	}
+
+	for i := 0; i < 3; i++ {
+		token, err := provider.Token(context.Background())
+		if err != nil || token != "token-1" {
+			t.Fatalf("Token() = %s, %v, want token-1", token, err)
+		}
+	}
+	if stub.requests() != 1 {
+		t.Errorf("iam requests = %d, want 1", stub.requests())
+	}
+	if provider.ProjectId() != "project-id" {
+		t.Errorf("ProjectId() = %s, want project-id", provider.ProjectId())
+	}
+
+	credentials, err := provider.Retrieve(context.Background())
+	if err != nil || credentials.Token != "token-1" {
+		t.Errorf("Retrieve() = %+v, %v", credentials, err)
+	}
+}
+
+func TestIamTokenProviderRefreshBefore(t *testing.T) {
+	stub := newIamStub(30 * time.Minute)
+	defer stub.Close()
+
+	// Token在30分钟后过期，RefreshBefore为10分钟时使用缓存
+	provider := newTestIamTokenProvider(t, stub.URL, 10*time.Minute)
+	_, _ = provider.Token(context.Background())
+	token, _ := provider.Token(context.Background())
+	if token != "token-1" || stub.requests() != 1 {
+		t.Errorf("Token() = %s after %d requests, want cached token-1", token, stub.requests())
+	}
+
+	// RefreshBefore为1小时时Token已经进入刷新时间，返回未过期的Token并在后台刷新
+	provider = newTestIamTokenProvider(t, stub.URL, time.Hour)
+	_, _ = provider.Token(context.Background())
+	token, _ = provider.Token(context.Background())
+	if token != "token-2" {
+		t.Errorf("Token() = %s, want cached token-2 during background refresh", token)
+	}
+	waitForIamToken(t, provider, "token-3")
+	if stub.requests() != 3 {
+		t.Errorf("iam requests = %d, want 3", stub.requests())
+	}
+}
+
+// 等待后台刷新完成
+func waitForIamToken(t *testing.T, provider *IamTokenProvider, expected string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		provider.lock.Lock()
+		token, refreshing := provider.token, provider.refreshing
+		provider.lock.Unlock()
+		if token == expected && refreshing == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("token = %s, want %s", token, expected)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestIamTokenProviderBackgroundRefreshFailure(t *testing.T) {
+	stub := newIamStub(30 * time.Minute)
+	defer stub.Close()
+
+	provider := newTestIamTokenProvider(t, stub.URL, time.Hour)
+	if token, err := provider.Token(context.Background()); err != nil || token != "token-1" {
+		t.Fatalf("Token() = %s, %v", token, err)
+	}
+
+	// IAM不可用时刷新失败，仍然返回未过期的Token
+	stub.status = http.StatusServiceUnavailable
+	stub.release = make(chan struct{})
+	for i := 0; i < 3; i++ {
+		token, err := provider.Token(context.Background())
+		if err != nil || token != "token-1" {
+			t.Errorf("Token() during refresh = %s, %v, want cached token-1", token, err)
+		}
+	}
+	<-stub.bodies
+	<-stub.bodies
+	close(stub.release)
+	waitForIamToken(t, provider, "token-1")
+
+	if token, err := provider.Token(context.Background()); err != nil || token != "token-1" {
+		t.Errorf("Token() after failed refresh = %s, %v, want cached token-1", token, err)
+	}
+	if stub.requests() < 2 {
+		t.Errorf("iam requests = %d, want a background refresh", stub.requests())
+	}
+
+	// Token过期后等待刷新并返回刷新的错误
+	provider.lock.Lock()
+	provider.expiresAt = time.Now().Add(-time.Second)
+	provider.lock.Unlock()
+	var ae *ApplicationError
+	if _, err := provider.Token(context.Background()); !errors.As(err, &ae) || ae.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Token() after expiration error = %v, want the refresh error", err)
+	}
+}
+
+func TestIamTokenProviderInvalidate(t *testing.T) {
+	stub := newIamStub(24 * time.Hour)
+	defer stub.Close()
+
+	provider := newTestIamTokenProvider(t, stub.URL, time.Hour)
+	_, _ = provider.Token(context.Background())
+	provider.Invalidate()
+
+	token, err := provider.Token(context.Background())
+	if err != nil || token != "token-2" || stub.requests() != 2 {
+		t.Errorf("Token() after Invalidate = %s, %v after %d requests, want token-2", token, err, stub.requests())
+	}
+}
+
+func TestIamTokenProviderConcurrentRefresh(t *testing.T) {
+	stub := newIamStub(24 * time.Hour)
+	stub.release = make(chan struct{})
+	defer stub.Close()
+
+	provider := newTestIamTokenProvider(t, stub.URL, time.Hour)
+
+	var wg sync.WaitGroup
+	tokens := make([]string, 10)
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tokens[i], _ = provider.Token(context.Background())
+		}(i)
+	}
+
+	// 等待刷新请求到达IAM，刷新期间不持有锁
+	<-stub.bodies
+	done := make(chan struct{})
+	go func() {
+		provider.Invalidate()
+		_ = provider.ProjectId()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("provider lock is held during refresh")
+	}
+
+	// 等待中的调用可以被自己的ctx取消
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := provider.Token(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Token() error = %v, want context.DeadlineExceeded", err)
+	}
+
+	close(stub.release)
+	wg.Wait()
+
+	for _, token := range tokens {
+		if token != "token-1" {
+			t.Errorf("tokens = %v, want all token-1", tokens)
+			break
+		}
+	}
+	if stub.requests() != 1 {
+		t.Errorf("iam requests = %d, want 1", stub.requests())
+	}
+}
+
+func TestIamTokenProviderError(t *testing.T) {
+	stub := newIamStub(24 * time.Hour)
+	stub.status = http.StatusUnauthorized
+	defer stub.Close()
+
+	provider := newTestIamTokenProvider(t, stub.URL, time.Hour)
+	_, err := provider.Token(context.Background())
+
+	var ae *ApplicationError
+	if !errors.As(err, &ae) || ae.StatusCode != http.StatusUnauthorized || ae.ErrorCode != "IAM.0001" {
+		t.Fatalf("Token() error = %v, want IAM.0001", err)
+	}
+
+	// 失败的结果不会被缓存
+	stub.status = http.StatusCreated
+	token, err := provider.Token(context.Background())
+	if err != nil || token != "token-2" {
+		t.Errorf("Token() = %s, %v, want token-2", token, err)
+	}
+}
+
+func TestIamTokenAuthRequest(t *testing.T) {
+	stub := newIamStub(24 * time.Hour)
+	defer stub.Close()
+
+	tests := []struct {
+		name    string
+		options *IamTokenOptions
+		want    string
+	}{
+		{"password", NewIamTokenOptions().SetPassword("domain", "user", "password").SetProjectName("cn-north-4"),
+			`{"auth":{"identity":{"methods":["password"],"password":{"user":{"name":"user","password":"password","domain":{"name":"domain"}}}},` +
+				`"scope":{"project":{"name":"cn-north-4"}}}}`},
+		{"ak/sk", NewIamTokenOptions().SetAkSk("ak", "sk").SetProjectId("project-id"),
+			`{"auth":{"identity":{"methods":["hw_ak_sk"],"hw_ak_sk":{"access":{"key":"ak"},"secret":{"key":"sk"}}},` +
+				`"scope":{"project":{"id":"project-id"}}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := NewIamTokenProvider(*tt.options.SetEndpoint(stub.URL))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err = provider.Token(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			body := <-stub.bodies
+			var got, want interface{}
+			_ = json.Unmarshal(body, &got)
+			_ = json.Unmarshal([]byte(tt.want), &want)
+			if fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Errorf("auth request = %s, want %s", body, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewIamTokenProviderInvalid(t *testing.T) {
+	for _, options := range []*IamTokenOptions{
+		NewIamTokenOptions().SetProjectName("cn-north-4"),
+		NewIamTokenOptions().SetAkSk("ak", "").SetProjectName("cn-north-4"),
+		NewIamTokenOptions().SetPassword("domain", "user", "").SetProjectName("cn-north-4"),
+		NewIamTokenOptions().SetAkSk("ak", "sk"),
+	} {
+		if _, err := NewIamTokenProvider(*options); err == nil {
+			t.Errorf("NewIamTokenProvider(%+v) should return error", options)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package iot
+
+import (
+	"context"
+	"github.com/go-resty/resty/v2"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Request 一次API调用的请求，拦截器可以读取或者修改其中的内容
+type Request struct {
+	// 操作名称，例如CreateDevice
+	Operation string
+	Method    string
+	// 路径模板，例如/v5/iot/{project_id}/devices/{device_id}，project_id由Client填充
+	Path string
+	// 项目ID，Client解析之前可能为空，调用next之后总是已经填充
+	ProjectId   string
+	PathParams  map[string]string
+	QueryParams map[string]string
+	// 附加的消息头，会覆盖SDK设置的同名消息头
+	Header http.Header
+	// 请求体，发送时序列化为JSON，[]byte和string原样发送
+	Body interface{}
+
+	fileName string
+	file     io.Reader
+	// 实际发送的次数，包括重试
+	attempts int
+}
+
+// Response 平台返回的响应，拦截器直接返回的Response同样会按照操作期望的状态码校验并解析
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	// 实际请求的地址，拦截器直接返回的响应可以为空
+	URL string
+}
+
+// Invoker 执行请求并返回响应，包括重试、流控和鉴权
+type Invoker func(ctx context.Context, request *Request) (*Response, error)
+
+// Interceptor 拦截每一次API调用，调用next继续执行，不调用next时直接使用返回的响应或者错误
+type Interceptor func(ctx context.Context, request *Request, next Invoker) (*Response, error)
+
+// 先添加的拦截器在外层，最先看到请求、最后看到响应
+func chainInterceptors(interceptors []Interceptor, invoker Invoker) Invoker {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoker
+		invoker = func(ctx context.Context, request *Request) (*Response, error) {
+			return interceptor(ctx, request, next)
+		}
+	}
+
+	return invoker
+}
+
+// 记录每一次API调用的操作名称、状态码、请求ID和耗时，平台返回错误时使用Warn级别
+func loggingInterceptor(logger Logger) Interceptor {
+	return func(ctx context.Context, request *Request, next Invoker) (*Response, error) {
+		start := time.Now()
+		response, err := next(ctx, request)
+		duration := time.Since(start)
+
+		if err != nil {
+			logger.Warn("iotda request failed", "operation", request.Operation, "duration", duration, "error", err)
+			return response, err
+		}
+
+		args := []interface{}{
+			"operation", request.Operation,
+			"status_code", response.StatusCode,
+			"request_id", response.Header.Get("X-Request-Id"),
+			"duration", duration,
+		}
+		if response.StatusCode >= http.StatusBadRequest {
+			logger.Warn("iotda request returned error", append(args, "body", response.Body)...)
+		} else {
+			logger.Debug("iotda request completed", append(args, "body", response.Body)...)
+		}
+
+		return response, err
+	}
+}
+
+func newResponse(response *resty.Response) *Response {
+	r := &Response{
+		StatusCode: response.StatusCode(),
+		Header:     response.Header(),
+		Body:       response.Body(),
+	}
+	if response.Request != nil && response.Request.RawRequest != nil {
+		r.URL = response.Request.RawRequest.URL.String()
+	}
+
+	return r
+}
@@ -0,0 +1,178 @@
+package iot
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestChainInterceptors(t *testing.T) {
+	var calls []string
+	interceptor := func(name string) Interceptor {
+		return func(ctx context.Context, request *Request, next Invoker) (*Response, error) {
+			calls = append(calls, name+" before")
+			response, err := next(ctx, request)
+			calls = append(calls, name+" after")
+			return response, err
+		}
+	}
+
+	invoker := chainInterceptors([]Interceptor{interceptor("first"), interceptor("second"), interceptor("third")},
+		func(ctx context.Context, request *Request) (*Response, error) {
+			calls = append(calls, "invoke")
+			return &Response{StatusCode: http.StatusOK}, nil
+		})
+	if _, err := invoker(context.Background(), &Request{}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"first before", "second before", "third before", "invoke", "third after", "second after", "first after"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("calls = %v, want %v", calls, expected)
+	}
+
+	// 没有拦截器时直接调用invoker
+	calls = nil
+	if _, err := chainInterceptors(nil, invoker)(context.Background(), &Request{}); err != nil || len(calls) != 7 {
+		t.Errorf("calls = %v, error = %v", calls, err)
+	}
+}
+
+func newInterceptedClient(url string, interceptors ...Interceptor) *syncClient {
+	return CreateSyncIotApplicationClient(*NewApplicationOptions().WithEndpoint(url).SetProjectId("project").
+		SetToken("token").SetRetryPolicy(NoRetryPolicy()).AddInterceptor(interceptors...))
+}
+
+// 不调用next的拦截器直接返回响应，响应同样按照操作期望的状态码校验并解析
+func TestInterceptorShortCircuit(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer server.Close()
+
+	response := &Response{StatusCode: http.StatusOK, Body: []byte(`{"device_id":"cached"}`)}
+	var interceptorErr error
+	client := newInterceptedClient(server.URL, func(ctx context.Context, request *Request, next Invoker) (*Response, error) {
+		return response, interceptorErr
+	})
+
+	device, err := client.ShowDevice("device-1")
+	if err != nil || device.DeviceID != "cached" {
+		t.Errorf("ShowDevice() = %+v, %v", device, err)
+	}
+
+	response = &Response{StatusCode: http.StatusNotFound, Body: []byte(`{"error_code":"IOTDA.014000","error_msg":"device not found"}`)}
+	_, err = client.ShowDevice("device-1")
+	if ae, ok := asApplicationError(err); !ok || !IsNotFound(err) || ae.Endpoint != "/v5/iot/{project_id}/devices/{device_id}" {
+		t.Errorf("ShowDevice() error = %v, want not found", err)
+	}
+
+	interceptorErr = errors.New("rejected")
+	if _, err = client.ShowDevice("device-1"); err != interceptorErr {
+		t.Errorf("ShowDevice() error = %v, want the interceptor error", err)
+	}
+
+	if n := atomic.LoadInt32(&requests); n != 0 {
+		t.Errorf("requests = %d, want 0", n)
+	}
+}
+
+// 拦截器对请求和ctx的修改对实际发送的请求生效
+func TestInterceptorModifiesRequest(t *testing.T) {
+	type received struct {
+		header string
+		query  string
+	}
+	requests := make(chan received, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- received{header: r.Header.Get("X-Trace-Id"), query: r.URL.Query().Get("app_id")}
+		_, _ = w.Write([]byte(`{"device_id":"device-1"}`))
+	}))
+	defer server.Close()
+
+	var projectId string
+	client := newInterceptedClient(server.URL,
+		func(ctx context.Context, request *Request, next Invoker) (*Response, error) {
+			request.Header.Set("X-Trace-Id", "trace-1")
+			return next(ctx, request)
+		},
+		func(ctx context.Context, request *Request, next Invoker) (*Response, error) {
+			if request.QueryParams == nil {
+				request.QueryParams = map[string]string{}
+			}
+			request.QueryParams["app_id"] = "app"
+			response, err := next(ctx, request)
+			projectId = request.ProjectId
+			return response, err
+		})
+
+	if _, err := client.ShowDevice("device-1"); err != nil {
+		t.Fatal(err)
+	}
+	if r := <-requests; r.header != "trace-1" || r.query != "app" {
+		t.Errorf("request = %+v", r)
+	}
+	if projectId != "project" {
+		t.Errorf("project id = %s, want project", projectId)
+	}
+
+	// 拦截器替换的ctx传递给实际发送的请求
+	client = newInterceptedClient(server.URL, func(ctx context.Context, request *Request, next Invoker) (*Response, error) {
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		return next(canceled, request)
+	})
+	if _, err := client.ShowDevice("device-1"); !errors.Is(err, context.Canceled) {
+		t.Errorf("ShowDevice() error = %v, want context.Canceled", err)
+	}
+	select {
+	case r := <-requests:
+		t.Errorf("canceled request is sent: %+v", r)
+	default:
+	}
+}
+
+func TestLoggingInterceptor(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-Request-Id", "request-1")
+
+	tests := []struct {
+		name     string
+		response *Response
+		err      error
+		msg      string
+	}{
+		{"success", &Response{StatusCode: http.StatusOK, Header: header}, nil, "iotda request completed"},
+		{"platform error", &Response{StatusCode: http.StatusNotFound, Header: header}, nil, "iotda request returned error"},
+		{"transport error", nil, errors.New("connection refused"), "iotda request failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &captureLogger{}
+			response, err := loggingInterceptor(logger)(context.Background(), &Request{Operation: "ShowDevice"},
+				func(ctx context.Context, request *Request) (*Response, error) {
+					return tt.response, tt.err
+				})
+			if response != tt.response || err != tt.err {
+				t.Errorf("response = %+v, error = %v", response, err)
+			}
+
+			if len(logger.entries) != 1 || logger.entries[0].msg != tt.msg {
+				t.Fatalf("logs = %s", logger)
+			}
+			args := logger.entries[0].args
+			if len(args) < 2 || args[0] != "operation" || args[1] != "ShowDevice" {
+				t.Errorf("args = %v", args)
+			}
+			if tt.response != nil && !reflect.DeepEqual(args[2:6], []interface{}{"status_code", tt.response.StatusCode, "request_id", "request-1"}) {
+				t.Errorf("args = %v", args)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+module huaweicloud-iot-application-sdk-go/iototel
+
+go 1.20
+
+require (
+	go.opentelemetry.io/otel v1.24.0
+	go.opentelemetry.io/otel/metric v1.24.0
+	go.opentelemetry.io/otel/sdk v1.24.0
+	go.opentelemetry.io/otel/sdk/metric v1.24.0
+	go.opentelemetry.io/otel/trace v1.24.0
+	huaweicloud-iot-application-sdk-go v0.0.0
+)
+
+require (
+	github.com/Azure/go-amqp v0.16.4 // indirect
+	github.com/go-logr/logr v1.4.1 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/go-resty/resty/v2 v2.4.0 // indirect
+	golang.org/x/net v0.0.0-20201224014010-6772e930b67b // indirect
+	golang.org/x/sys v0.17.0 // indirect
+)
+
+replace huaweicloud-iot-application-sdk-go => ../
@@ -0,0 +1,78 @@
+// Package iototel 使用OpenTelemetry记录SDK的API调用指标和链路
+package iototel
+
+import (
+	"context"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	iot "huaweicloud-iot-application-sdk-go"
+)
+
+const instrumentationName = "huaweicloud-iot-application-sdk-go/iototel"
+
+// 指标和Span共用的属性
+const (
+	AttributeOperation  = attribute.Key("iotda.operation")
+	AttributeApiGroup   = attribute.Key("iotda.api_group")
+	AttributeProjectId  = attribute.Key("iotda.project_id")
+	AttributeErrorCode  = attribute.Key("iotda.error_code")
+	AttributeStatusCode = attribute.Key("http.response.status_code")
+)
+
+// MetricsRecorder 实现了iot.MetricsRecorder
+type MetricsRecorder struct {
+	requests metric.Int64Counter
+	duration metric.Float64Histogram
+	retries  metric.Int64Counter
+	errors   metric.Int64Counter
+}
+
+// NewMetricsRecorder provider为空时使用otel.GetMeterProvider()
+func NewMetricsRecorder(provider metric.MeterProvider) (*MetricsRecorder, error) {
+	if provider == nil {
+		provider = otel.GetMeterProvider()
+	}
+	meter := provider.Meter(instrumentationName)
+
+	r := &MetricsRecorder{}
+	var err error
+	if r.requests, err = meter.Int64Counter("iotda.client.requests",
+		metric.WithDescription("Total number of IoTDA API calls.")); err != nil {
+		return nil, err
+	}
+	if r.duration, err = meter.Float64Histogram("iotda.client.request.duration",
+		metric.WithDescription("Latency of IoTDA API calls including retries."), metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	if r.retries, err = meter.Int64Counter("iotda.client.retries",
+		metric.WithDescription("Total number of retried IoTDA requests.")); err != nil {
+		return nil, err
+	}
+	if r.errors, err = meter.Int64Counter("iotda.client.errors",
+		metric.WithDescription("Total number of failed IoTDA API calls.")); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *MetricsRecorder) RecordCall(ctx context.Context, call iot.CallMetrics) {
+	common := metric.WithAttributes(
+		AttributeOperation.String(call.Operation),
+		AttributeApiGroup.String(string(call.ApiGroup)),
+		AttributeProjectId.String(call.ProjectId),
+	)
+
+	r.requests.Add(ctx, 1, common, metric.WithAttributes(AttributeStatusCode.Int(call.StatusCode)))
+	r.duration.Record(ctx, call.Duration.Seconds(), common)
+	if call.Retries > 0 {
+		r.retries.Add(ctx, int64(call.Retries), common)
+	}
+	if call.Err != nil {
+		r.errors.Add(ctx, 1, common, metric.WithAttributes(
+			AttributeStatusCode.Int(call.StatusCode),
+			AttributeErrorCode.String(call.ErrorCode),
+		))
+	}
+}
@@ -0,0 +1,110 @@
+package iototel
+
+import (
+	"context"
+	"errors"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	iot "huaweicloud-iot-application-sdk-go"
+	"testing"
+	"time"
+)
+
+func collectMetrics(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.Aggregation {
+	t.Helper()
+
+	data := metricdata.ResourceMetrics{}
+	if err := reader.Collect(context.Background(), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	metrics := map[string]metricdata.Aggregation{}
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+
+	return metrics
+}
+
+func int64Points(t *testing.T, aggregation metricdata.Aggregation) map[attribute.Distinct]metricdata.DataPoint[int64] {
+	t.Helper()
+
+	sum, ok := aggregation.(metricdata.Sum[int64])
+	if !ok {
+		t.Fatalf("aggregation = %T, want metricdata.Sum[int64]", aggregation)
+	}
+
+	points := map[attribute.Distinct]metricdata.DataPoint[int64]{}
+	for _, point := range sum.DataPoints {
+		points[point.Attributes.Equivalent()] = point
+	}
+
+	return points
+}
+
+func TestMetricsRecorder(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	recorder, err := NewMetricsRecorder(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	recorder.RecordCall(ctx, iot.CallMetrics{
+		Operation:  "ShowDevice",
+		ApiGroup:   iot.ApiGroupDevice,
+		ProjectId:  "project",
+		StatusCode: 200,
+		Duration:   300 * time.Millisecond,
+		Retries:    2,
+	})
+	recorder.RecordCall(ctx, iot.CallMetrics{
+		Operation:  "ShowDevice",
+		ApiGroup:   iot.ApiGroupDevice,
+		ProjectId:  "project",
+		StatusCode: 404,
+		ErrorCode:  "IOTDA.014000",
+		Err:        errors.New("device not found"),
+		Duration:   20 * time.Millisecond,
+	})
+
+	common := []attribute.KeyValue{
+		AttributeOperation.String("ShowDevice"),
+		AttributeApiGroup.String("device"),
+		AttributeProjectId.String("project"),
+	}
+	with := func(kvs ...attribute.KeyValue) attribute.Distinct {
+		set := attribute.NewSet(append(append([]attribute.KeyValue{}, common...), kvs...)...)
+		return set.Equivalent()
+	}
+
+	metrics := collectMetrics(t, reader)
+
+	requests := int64Points(t, metrics["iotda.client.requests"])
+	if len(requests) != 2 || requests[with(AttributeStatusCode.Int(200))].Value != 1 ||
+		requests[with(AttributeStatusCode.Int(404))].Value != 1 {
+		t.Errorf("requests = %+v", requests)
+	}
+
+	retries := int64Points(t, metrics["iotda.client.retries"])
+	if len(retries) != 1 || retries[with()].Value != 2 {
+		t.Errorf("retries = %+v", retries)
+	}
+
+	errorPoints := int64Points(t, metrics["iotda.client.errors"])
+	if len(errorPoints) != 1 || errorPoints[with(AttributeStatusCode.Int(404), AttributeErrorCode.String("IOTDA.014000"))].Value != 1 {
+		t.Errorf("errors = %+v", errorPoints)
+	}
+
+	histogram, ok := metrics["iotda.client.request.duration"].(metricdata.Histogram[float64])
+	if !ok || len(histogram.DataPoints) != 1 {
+		t.Fatalf("duration = %+v", metrics["iotda.client.request.duration"])
+	}
+	point := histogram.DataPoints[0]
+	if point.Attributes.Equivalent() != with() || point.Count != 2 || point.Sum < 0.319 || point.Sum > 0.321 {
+		t.Errorf("duration = %+v", point)
+	}
+}
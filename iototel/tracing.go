@@ -0,0 +1,167 @@
+package iototel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	iot "huaweicloud-iot-application-sdk-go"
+	"net/http"
+)
+
+// Span使用的其他属性
+const (
+	AttributeDeviceId      = attribute.Key("iotda.device_id")
+	AttributeRequestId     = attribute.Key("iotda.request_id")
+	AttributeRequestMethod = attribute.Key("http.request.method")
+	AttributeMessageId     = attribute.Key("messaging.message.id")
+	AttributeMessaging     = attribute.Key("messaging.system")
+)
+
+// TracingOptions 为空的字段使用otel的全局配置
+type TracingOptions struct {
+	TracerProvider trace.TracerProvider
+	// 向请求注入、从AMQP消息中提取trace context，默认为otel.GetTextMapPropagator()，
+	// 全局没有设置时使用W3C Trace Context
+	Propagator propagation.TextMapPropagator
+}
+
+func (o TracingOptions) tracer() trace.Tracer {
+	provider := o.TracerProvider
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+
+	return provider.Tracer(instrumentationName)
+}
+
+func (o TracingOptions) propagator() propagation.TextMapPropagator {
+	if o.Propagator != nil {
+		return o.Propagator
+	}
+
+	propagator := otel.GetTextMapPropagator()
+	if len(propagator.Fields()) == 0 {
+		return propagation.TraceContext{}
+	}
+
+	return propagator
+}
+
+// NewTracingInterceptor 为每一次API调用创建一个Client类型的Span，并通过消息头传播trace context
+func NewTracingInterceptor(options TracingOptions) iot.Interceptor {
+	tracer := options.tracer()
+	propagator := options.propagator()
+
+	return func(ctx context.Context, request *iot.Request, next iot.Invoker) (*iot.Response, error) {
+		attributes := []attribute.KeyValue{
+			AttributeOperation.String(request.Operation),
+			AttributeRequestMethod.String(request.Method),
+		}
+		if deviceId, ok := request.PathParams["device_id"]; ok {
+			attributes = append(attributes, AttributeDeviceId.String(deviceId))
+		}
+
+		ctx, span := tracer.Start(ctx, "iotda."+request.Operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attributes...))
+		defer span.End()
+
+		propagator.Inject(ctx, propagation.HeaderCarrier(request.Header))
+
+		response, err := next(ctx, request)
+		span.SetAttributes(AttributeProjectId.String(request.ProjectId))
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return response, err
+		}
+
+		span.SetAttributes(
+			AttributeStatusCode.Int(response.StatusCode),
+			AttributeRequestId.String(response.Header.Get("X-Request-Id")),
+		)
+		if response.StatusCode >= http.StatusBadRequest {
+			errorCode := responseErrorCode(response.Body)
+			if len(errorCode) != 0 {
+				span.SetAttributes(AttributeErrorCode.String(errorCode))
+			}
+			span.SetStatus(codes.Error, fmt.Sprintf("status_code=%d, error_code=%s", response.StatusCode, errorCode))
+		}
+
+		return response, err
+	}
+}
+
+func responseErrorCode(body []byte) string {
+	response := &iot.ApplicationResponseError{}
+	if json.Unmarshal(body, response) != nil {
+		return ""
+	}
+
+	return response.ErrorCode
+}
+
+// NewTracingAmqpHandler 从消息的ApplicationProperties中提取trace context，为每一条消息创建一个Consumer类型的Span，
+// 消息中没有trace context时创建新的trace
+func NewTracingAmqpHandler(handler iot.AmqpMessageHandler, options TracingOptions) iot.AmqpMessageHandler {
+	tracer := options.tracer()
+	propagator := options.propagator()
+
+	return func(ctx context.Context, message *iot.AmqpMessage) error {
+		ctx = propagator.Extract(ctx, amqpPropertiesCarrier(message.ApplicationProperties))
+
+		ctx, span := tracer.Start(ctx, "iotda.amqp.receive",
+			trace.WithSpanKind(trace.SpanKindConsumer),
+			trace.WithAttributes(
+				AttributeMessaging.String("amqp"),
+				AttributeMessageId.String(message.MessageID),
+			))
+		defer span.End()
+
+		err := handler(ctx, message)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+
+		return err
+	}
+}
+
+// amqpPropertiesCarrier 将AMQP消息的ApplicationProperties适配为propagation.TextMapCarrier
+type amqpPropertiesCarrier map[string]interface{}
+
+func (c amqpPropertiesCarrier) Get(key string) string {
+	value, ok := c[key]
+	if !ok {
+		return ""
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (c amqpPropertiesCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+func (c amqpPropertiesCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
@@ -0,0 +1,243 @@
+package iototel
+
+import (
+	"context"
+	"errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	iot "huaweicloud-iot-application-sdk-go"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestTracing() (TracingOptions, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	return TracingOptions{TracerProvider: provider, Propagator: propagation.TraceContext{}}, recorder
+}
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attributes := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		attributes[kv.Key] = kv.Value
+	}
+
+	return attributes
+}
+
+func onlySpan(t *testing.T, recorder *tracetest.SpanRecorder) sdktrace.ReadOnlySpan {
+	t.Helper()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("ended spans = %d, want 1", len(spans))
+	}
+
+	return spans[0]
+}
+
+func TestTracingInterceptor(t *testing.T) {
+	tracing, recorder := newTestTracing()
+	interceptor := NewTracingInterceptor(tracing)
+
+	request := &iot.Request{
+		Operation:  "ShowDevice",
+		Method:     http.MethodGet,
+		PathParams: map[string]string{"device_id": "device-1"},
+		Header:     http.Header{},
+	}
+	var spanContext trace.SpanContext
+	_, err := interceptor(context.Background(), request, func(ctx context.Context, request *iot.Request) (*iot.Response, error) {
+		spanContext = trace.SpanContextFromContext(ctx)
+		request.ProjectId = "project-1"
+		return &iot.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"X-Request-Id": []string{"request-1"}},
+			Body:       []byte(`{}`),
+		}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	span := onlySpan(t, recorder)
+	if span.Name() != "iotda.ShowDevice" || span.SpanKind() != trace.SpanKindClient {
+		t.Errorf("span name = %s, kind = %v", span.Name(), span.SpanKind())
+	}
+	if span.Status().Code != codes.Unset {
+		t.Errorf("span status = %v", span.Status())
+	}
+
+	attributes := spanAttributes(span)
+	expected := map[attribute.Key]attribute.Value{
+		AttributeOperation:     attribute.StringValue("ShowDevice"),
+		AttributeRequestMethod: attribute.StringValue(http.MethodGet),
+		AttributeDeviceId:      attribute.StringValue("device-1"),
+		AttributeProjectId:     attribute.StringValue("project-1"),
+		AttributeStatusCode:    attribute.IntValue(http.StatusOK),
+		AttributeRequestId:     attribute.StringValue("request-1"),
+	}
+	for key, value := range expected {
+		if attributes[key] != value {
+			t.Errorf("attribute %s = %v, want %v", key, attributes[key].Emit(), value.Emit())
+		}
+	}
+
+	// traceparent: 00-${trace_id}-${span_id}-01
+	traceparent := request.Header.Get("traceparent")
+	if traceparent != "00-"+span.SpanContext().TraceID().String()+"-"+span.SpanContext().SpanID().String()+"-01" {
+		t.Errorf("traceparent = %s, span = %v", traceparent, span.SpanContext())
+	}
+	if spanContext.SpanID() != span.SpanContext().SpanID() {
+		t.Error("next is not called with the span context")
+	}
+}
+
+func TestTracingInterceptorErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		response    *iot.Response
+		err         error
+		description string
+		errorCode   string
+	}{
+		{
+			name:        "transport error",
+			err:         errors.New("connection reset"),
+			description: "connection reset",
+		},
+		{
+			name: "error response",
+			response: &iot.Response{
+				StatusCode: http.StatusNotFound,
+				Header:     http.Header{},
+				Body:       []byte(`{"error_code":"IOTDA.014000","error_msg":"device not found"}`),
+			},
+			description: "status_code=404, error_code=IOTDA.014000",
+			errorCode:   "IOTDA.014000",
+		},
+	}
+
+	for _, tt := range tests {
+		tracing, recorder := newTestTracing()
+		interceptor := NewTracingInterceptor(tracing)
+
+		request := &iot.Request{Operation: "ShowDevice", Method: http.MethodGet, Header: http.Header{}}
+		_, err := interceptor(context.Background(), request, func(ctx context.Context, request *iot.Request) (*iot.Response, error) {
+			return tt.response, tt.err
+		})
+		if err != tt.err {
+			t.Errorf("%s: error = %v", tt.name, err)
+		}
+
+		span := onlySpan(t, recorder)
+		if span.Status().Code != codes.Error || span.Status().Description != tt.description {
+			t.Errorf("%s: span status = %+v", tt.name, span.Status())
+		}
+		if errorCode := spanAttributes(span)[AttributeErrorCode].AsString(); errorCode != tt.errorCode {
+			t.Errorf("%s: error code = %s, want %s", tt.name, errorCode, tt.errorCode)
+		}
+		if tt.err != nil && (len(span.Events()) != 1 || span.Events()[0].Name != "exception") {
+			t.Errorf("%s: events = %v, want a recorded exception", tt.name, span.Events())
+		}
+	}
+}
+
+func TestTracingInterceptorPropagatesToPlatform(t *testing.T) {
+	traceparents := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparents <- r.Header.Get("traceparent")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"device_id":"device-1"}`))
+	}))
+	defer server.Close()
+
+	tracing, recorder := newTestTracing()
+	client, err := iot.NewClient(
+		iot.WithEndpoint(server.URL),
+		iot.WithProjectId("project-1"),
+		iot.WithToken("token"),
+		iot.WithInterceptors(NewTracingInterceptor(tracing)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.ShowDeviceCtx(context.Background(), "device-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	span := onlySpan(t, recorder)
+	if traceparent := <-traceparents; !strings.Contains(traceparent, span.SpanContext().SpanID().String()) {
+		t.Errorf("traceparent sent to the platform = %s, span = %v", traceparent, span.SpanContext())
+	}
+	if attributes := spanAttributes(span); attributes[AttributeDeviceId].AsString() != "device-1" ||
+		attributes[AttributeProjectId].AsString() != "project-1" {
+		t.Errorf("attributes = %v", span.Attributes())
+	}
+}
+
+func TestTracingAmqpHandler(t *testing.T) {
+	tracing, recorder := newTestTracing()
+
+	parent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	var handlerSpan trace.SpanContext
+	handler := NewTracingAmqpHandler(func(ctx context.Context, message *iot.AmqpMessage) error {
+		handlerSpan = trace.SpanContextFromContext(ctx)
+		return nil
+	}, tracing)
+
+	err := handler(context.Background(), &iot.AmqpMessage{
+		MessageID:             "message-1",
+		ApplicationProperties: map[string]interface{}{"traceparent": []byte(parent)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	span := onlySpan(t, recorder)
+	if span.Name() != "iotda.amqp.receive" || span.SpanKind() != trace.SpanKindConsumer {
+		t.Errorf("span name = %s, kind = %v", span.Name(), span.SpanKind())
+	}
+	if span.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" ||
+		span.Parent().SpanID().String() != "00f067aa0ba902b7" || !span.Parent().IsRemote() {
+		t.Errorf("span context = %v, parent = %v", span.SpanContext(), span.Parent())
+	}
+	if handlerSpan.SpanID() != span.SpanContext().SpanID() {
+		t.Error("handler is not called with the span context")
+	}
+
+	attributes := spanAttributes(span)
+	if attributes[AttributeMessaging].AsString() != "amqp" || attributes[AttributeMessageId].AsString() != "message-1" {
+		t.Errorf("attributes = %v", span.Attributes())
+	}
+}
+
+func TestTracingAmqpHandlerError(t *testing.T) {
+	tracing, recorder := newTestTracing()
+
+	handleErr := errors.New("database unavailable")
+	handler := NewTracingAmqpHandler(func(ctx context.Context, message *iot.AmqpMessage) error {
+		return handleErr
+	}, tracing)
+
+	if err := handler(context.Background(), &iot.AmqpMessage{MessageID: "message-1"}); err != handleErr {
+		t.Errorf("error = %v", err)
+	}
+
+	// 没有trace context时创建新的trace
+	span := onlySpan(t, recorder)
+	if span.Parent().IsValid() {
+		t.Errorf("parent = %v, want a new trace", span.Parent())
+	}
+	if span.Status().Code != codes.Error || span.Status().Description != handleErr.Error() {
+		t.Errorf("span status = %+v", span.Status())
+	}
+}
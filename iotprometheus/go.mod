@@ -0,0 +1,10 @@
+module huaweicloud-iot-application-sdk-go/iotprometheus
+
+go 1.15
+
+require (
+	github.com/prometheus/client_golang v1.11.1
+	huaweicloud-iot-application-sdk-go v0.0.0
+)
+
+replace huaweicloud-iot-application-sdk-go => ../
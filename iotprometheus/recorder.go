@@ -0,0 +1,81 @@
+// Package iotprometheus 使用Prometheus记录SDK的API调用指标
+package iotprometheus
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	iot "huaweicloud-iot-application-sdk-go"
+	"strconv"
+)
+
+const namespace = "iotda_client"
+
+// Recorder 实现了iot.MetricsRecorder，指标的标签包括operation、api_group、project_id，请求数额外包括status_code，错误指标额外包括status_code和error_code
+type Recorder struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	retries  *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+}
+
+// NewRecorder 创建Recorder并注册指标，registerer为空时使用prometheus.DefaultRegisterer
+func NewRecorder(registerer prometheus.Registerer) (*Recorder, error) {
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+
+	r := &Recorder{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "requests_total",
+			Help:      "Total number of IoTDA API calls.",
+		}, []string{"operation", "api_group", "project_id", "status_code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "Latency of IoTDA API calls including retries.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation", "api_group", "project_id"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "retries_total",
+			Help:      "Total number of retried IoTDA requests.",
+		}, []string{"operation", "api_group", "project_id"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "errors_total",
+			Help:      "Total number of failed IoTDA API calls.",
+		}, []string{"operation", "api_group", "project_id", "status_code", "error_code"}),
+	}
+
+	for _, collector := range []prometheus.Collector{r.requests, r.duration, r.retries, r.errors} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// MustNewRecorder 与NewRecorder相同，注册失败时panic
+func MustNewRecorder(registerer prometheus.Registerer) *Recorder {
+	r, err := NewRecorder(registerer)
+	if err != nil {
+		panic(err)
+	}
+
+	return r
+}
+
+func (r *Recorder) RecordCall(ctx context.Context, call iot.CallMetrics) {
+	statusCode := strconv.Itoa(call.StatusCode)
+
+	r.requests.WithLabelValues(call.Operation, string(call.ApiGroup), call.ProjectId, statusCode).Inc()
+	r.duration.WithLabelValues(call.Operation, string(call.ApiGroup), call.ProjectId).Observe(call.Duration.Seconds())
+	if call.Retries > 0 {
+		r.retries.WithLabelValues(call.Operation, string(call.ApiGroup), call.ProjectId).Add(float64(call.Retries))
+	}
+	if call.Err != nil {
+		r.errors.WithLabelValues(call.Operation, string(call.ApiGroup), call.ProjectId, statusCode, call.ErrorCode).Inc()
+	}
+}
@@ -0,0 +1,112 @@
+package iotprometheus
+
+import (
+	"context"
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	iot "huaweicloud-iot-application-sdk-go"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecorder(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	recorder, err := NewRecorder(registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	recorder.RecordCall(ctx, iot.CallMetrics{
+		Operation:  "ShowDevice",
+		ApiGroup:   iot.ApiGroupDevice,
+		ProjectId:  "project",
+		StatusCode: 200,
+		Duration:   300 * time.Millisecond,
+		Retries:    2,
+	})
+	recorder.RecordCall(ctx, iot.CallMetrics{
+		Operation:  "ShowDevice",
+		ApiGroup:   iot.ApiGroupDevice,
+		ProjectId:  "project",
+		StatusCode: 404,
+		ErrorCode:  "IOTDA.014000",
+		Err:        errors.New("device not found"),
+		Duration:   20 * time.Millisecond,
+	})
+
+	expected := `
+# HELP iotda_client_requests_total Total number of IoTDA API calls.
+# TYPE iotda_client_requests_total counter
+iotda_client_requests_total{api_group="device",operation="ShowDevice",project_id="project",status_code="200"} 1
+iotda_client_requests_total{api_group="device",operation="ShowDevice",project_id="project",status_code="404"} 1
+# HELP iotda_client_retries_total Total number of retried IoTDA requests.
+# TYPE iotda_client_retries_total counter
+iotda_client_retries_total{api_group="device",operation="ShowDevice",project_id="project"} 2
+# HELP iotda_client_errors_total Total number of failed IoTDA API calls.
+# TYPE iotda_client_errors_total counter
+iotda_client_errors_total{api_group="device",error_code="IOTDA.014000",operation="ShowDevice",project_id="project",status_code="404"} 1
+`
+	err = testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"iotda_client_requests_total", "iotda_client_retries_total", "iotda_client_errors_total")
+	if err != nil {
+		t.Error(err)
+	}
+
+	// 两次调用的耗时都被记录
+	expectedDuration := `
+# HELP iotda_client_request_duration_seconds Latency of IoTDA API calls including retries.
+# TYPE iotda_client_request_duration_seconds histogram
+iotda_client_request_duration_seconds_bucket{api_group="device",operation="ShowDevice",project_id="project",le="0.005"} 0
+iotda_client_request_duration_seconds_bucket{api_group="device",operation="ShowDevice",project_id="project",le="0.01"} 0
+iotda_client_request_duration_seconds_bucket{api_group="device",operation="ShowDevice",project_id="project",le="0.025"} 1
+iotda_client_request_duration_seconds_bucket{api_group="device",operation="ShowDevice",project_id="project",le="0.05"} 1
+iotda_client_request_duration_seconds_bucket{api_group="device",operation="ShowDevice",project_id="project",le="0.1"} 1
+iotda_client_request_duration_seconds_bucket{api_group="device",operation="ShowDevice",project_id="project",le="0.25"} 1
+iotda_client_request_duration_seconds_bucket{api_group="device",operation="ShowDevice",project_id="project",le="0.5"} 2
+iotda_client_request_duration_seconds_bucket{api_group="device",operation="ShowDevice",project_id="project",le="1"} 2
+iotda_client_request_duration_seconds_bucket{api_group="device",operation="ShowDevice",project_id="project",le="2.5"} 2
+iotda_client_request_duration_seconds_bucket{api_group="device",operation="ShowDevice",project_id="project",le="5"} 2
+iotda_client_request_duration_seconds_bucket{api_group="device",operation="ShowDevice",project_id="project",le="10"} 2
+iotda_client_request_duration_seconds_bucket{api_group="device",operation="ShowDevice",project_id="project",le="+Inf"} 2
+iotda_client_request_duration_seconds_sum{api_group="device",operation="ShowDevice",project_id="project"} 0.32
+iotda_client_request_duration_seconds_count{api_group="device",operation="ShowDevice",project_id="project"} 2
+`
+	err = testutil.GatherAndCompare(registry, strings.NewReader(expectedDuration), "iotda_client_request_duration_seconds")
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRecorderWithClient(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	recorder := MustNewRecorder(registry)
+
+	// 连接失败时状态码为0
+	client, err := iot.NewClient(iot.WithEndpoint("http://127.0.0.1:1"), iot.WithProjectId("project"),
+		iot.WithToken("token"), iot.WithRetryPolicy(iot.NoRetryPolicy()), iot.WithMetricsRecorder(recorder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ListAmqpQueues(iot.ListAmqpQueuesRequest{}); err == nil {
+		t.Fatal("ListAmqpQueues() should fail")
+	}
+
+	if count := testutil.ToFloat64(recorder.requests.WithLabelValues("ListAmqpQueues", "amqp", "project", "0")); count != 1 {
+		t.Errorf("requests = %v, want 1", count)
+	}
+	if count := testutil.ToFloat64(recorder.errors.WithLabelValues("ListAmqpQueues", "amqp", "project", "0", "")); count != 1 {
+		t.Errorf("errors = %v, want 1", count)
+	}
+}
+
+func TestNewRecorderDuplicated(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	MustNewRecorder(registry)
+
+	if _, err := NewRecorder(registry); err == nil {
+		t.Error("NewRecorder() with registered collectors should return error")
+	}
+}
@@ -0,0 +1,276 @@
+package iottest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	iot "huaweicloud-iot-application-sdk-go"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+// 调用没有Stub并且没有内置实现的方法时返回的错误
+var ErrNotStubbed = errors.New("iottest: operation is not stubbed")
+
+var _ iot.ApplicationClient = (*FakeClient)(nil)
+
+// Stub 处理一次方法调用，args为除了context以外的参数。
+// result的类型必须与方法的返回值一致，例如ShowDevice返回*iot.DeviceDetailResponse，返回bool的方法可以返回nil表示true
+type Stub func(ctx context.Context, args []interface{}) (result interface{}, err error)
+
+// Call 一次方法调用，Operation为去掉Ctx后缀的方法名
+type Call struct {
+	Operation string
+	Args      []interface{}
+}
+
+// FakeClient 用于单元测试的iot.ApplicationClient实现，不发送任何请求。
+// 设备、设备组、标签、设备影子、AMQP队列、证书和资源空间的方法默认使用与Server相同的内存状态，
+// 其他方法需要通过On或者Return设置返回值，否则返回ErrNotStubbed
+type FakeClient struct {
+	lock  sync.Mutex
+	state *state
+	stubs map[string]Stub
+	calls []Call
+}
+
+func NewFakeClient() *FakeClient {
+	return &FakeClient{
+		state: newState(DefaultProjectId),
+		stubs: map[string]Stub{},
+	}
+}
+
+// On 设置方法的Stub，覆盖内置实现和之前设置的Stub
+func (f *FakeClient) On(operation string, stub Stub) *FakeClient {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.stubs[operation] = stub
+	return f
+}
+
+// Return 设置方法每次调用时的返回值
+func (f *FakeClient) Return(operation string, result interface{}, err error) *FakeClient {
+	return f.On(operation, func(ctx context.Context, args []interface{}) (interface{}, error) {
+		return result, err
+	})
+}
+
+// Reset 清空Stub、调用记录和内存状态
+func (f *FakeClient) Reset() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.state = newState(DefaultProjectId)
+	f.stubs = map[string]Stub{}
+	f.calls = nil
+}
+
+// Calls 按照调用顺序返回所有调用记录
+func (f *FakeClient) Calls() []Call {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	calls := make([]Call, len(f.calls))
+	copy(calls, f.calls)
+	return calls
+}
+
+// CallsFor 返回指定方法的调用记录
+func (f *FakeClient) CallsFor(operation string) []Call {
+	var calls []Call
+	for _, call := range f.Calls() {
+		if call.Operation == operation {
+			calls = append(calls, call)
+		}
+	}
+
+	return calls
+}
+
+func (f *FakeClient) CallCount(operation string) int {
+	return len(f.CallsFor(operation))
+}
+
+// Called 方法是否被调用过，args不为空时只匹配前len(args)个参数相同的调用
+func (f *FakeClient) Called(operation string, args ...interface{}) bool {
+	for _, call := range f.CallsFor(operation) {
+		if matchArgs(call.Args, args) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TestingT 是testing.TB的子集，避免在非测试代码中引入testing包
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// AssertCalled 方法没有使用指定的参数调用过时报告测试失败，例如fake.AssertCalled(t, "ShowDevice", "device_id")
+func (f *FakeClient) AssertCalled(t TestingT, operation string, args ...interface{}) bool {
+	t.Helper()
+
+	if f.Called(operation, args...) {
+		return true
+	}
+
+	t.Errorf("expected %s to be called with %v, actual calls: %v", operation, args, f.argsOf(operation))
+	return false
+}
+
+func (f *FakeClient) AssertNotCalled(t TestingT, operation string, args ...interface{}) bool {
+	t.Helper()
+
+	if !f.Called(operation, args...) {
+		return true
+	}
+
+	t.Errorf("expected %s not to be called with %v, actual calls: %v", operation, args, f.argsOf(operation))
+	return false
+}
+
+func (f *FakeClient) AssertCallCount(t TestingT, operation string, expected int) bool {
+	t.Helper()
+
+	if actual := f.CallCount(operation); actual != expected {
+		t.Errorf("expected %s to be called %d times, actual %d", operation, expected, actual)
+		return false
+	}
+
+	return true
+}
+
+func (f *FakeClient) argsOf(operation string) [][]interface{} {
+	var args [][]interface{}
+	for _, call := range f.CallsFor(operation) {
+		args = append(args, call.Args)
+	}
+
+	return args
+}
+
+func matchArgs(actual, expected []interface{}) bool {
+	if len(expected) > len(actual) {
+		return false
+	}
+
+	for i, arg := range expected {
+		if !reflect.DeepEqual(actual[i], arg) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// 记录调用，优先使用Stub，其次使用内置实现
+func (f *FakeClient) invoke(ctx context.Context, operation string, args []interface{}, fallback func() (interface{}, error)) (interface{}, error) {
+	f.lock.Lock()
+	f.calls = append(f.calls, Call{Operation: operation, Args: args})
+	stub := f.stubs[operation]
+	f.lock.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if stub != nil {
+		return stub(ctx, args)
+	}
+
+	if fallback == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotStubbed, operation)
+	}
+
+	return fallback()
+}
+
+func boolResult(result interface{}, err error) (bool, error) {
+	if err != nil {
+		return false, err
+	}
+
+	if ok, isBool := result.(bool); isBool {
+		return ok, nil
+	}
+
+	return true, nil
+}
+
+var routesByOperation = func() map[string]*route {
+	m := map[string]*route{}
+	for _, r := range routes {
+		m[r.operation] = r
+	}
+
+	return m
+}()
+
+// 使用Server的处理逻辑作为内置实现，请求和响应经过JSON编解码，与真实调用的行为一致
+func (f *FakeClient) serve(operation string, params, query map[string]string, body, result interface{}) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		c := &call{
+			params: map[string]string{"project_id": DefaultProjectId},
+			query:  url.Values{},
+		}
+		for k, v := range params {
+			c.params[k] = v
+		}
+		for k, v := range query {
+			if len(v) != 0 {
+				c.query.Set(k, v)
+			}
+		}
+		if body != nil {
+			var err error
+			if c.body, err = json.Marshal(body); err != nil {
+				return nil, err
+			}
+		}
+
+		r := routesByOperation[operation]
+		f.lock.Lock()
+		statusCode, response := r.handler(f.state, c)
+		data, err := json.Marshal(response)
+		f.lock.Unlock()
+		if err != nil {
+			return nil, err
+		}
+
+		if statusCode >= http.StatusBadRequest {
+			ae := &iot.ApplicationError{}
+			_ = json.Unmarshal(data, ae)
+			ae.StatusCode = statusCode
+			ae.Method = r.method
+			ae.Endpoint = r.path(c.params)
+			return nil, ae
+		}
+
+		if result == nil || response == nil {
+			return nil, nil
+		}
+
+		return result, json.Unmarshal(data, result)
+	}
+}
+
+func pageQuery(limit int, marker string, offset int) map[string]string {
+	return map[string]string{
+		"limit":  strconv.Itoa(limit),
+		"marker": marker,
+		"offset": strconv.Itoa(offset),
+	}
+}
+
+func withQuery(query map[string]string, key, value string) map[string]string {
+	query[key] = value
+	return query
+}
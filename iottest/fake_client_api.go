@@ -0,0 +1,650 @@
+package iottest
+
+import (
+	"context"
+	iot "huaweicloud-iot-application-sdk-go"
+	"io"
+	"strconv"
+)
+
+// 产品管理
+
+func (f *FakeClient) ListProducts(request iot.ListProductsRequest) (*iot.ListProductsResponse, error) {
+	return f.ListProductsCtx(context.Background(), request)
+}
+
+func (f *FakeClient) ListProductsCtx(ctx context.Context, request iot.ListProductsRequest) (*iot.ListProductsResponse, error) {
+	result, err := f.invoke(ctx, "ListProducts", []interface{}{request}, nil)
+	response, _ := result.(*iot.ListProductsResponse)
+	return response, err
+}
+
+func (f *FakeClient) CreateProduct(request iot.CreateProductRequest) (*iot.ProductDetailResponse, error) {
+	return f.CreateProductCtx(context.Background(), request)
+}
+
+func (f *FakeClient) CreateProductCtx(ctx context.Context, request iot.CreateProductRequest) (*iot.ProductDetailResponse, error) {
+	result, err := f.invoke(ctx, "CreateProduct", []interface{}{request}, nil)
+	response, _ := result.(*iot.ProductDetailResponse)
+	return response, err
+}
+
+func (f *FakeClient) ShowProduct(productId, appId string) (*iot.ProductDetailResponse, error) {
+	return f.ShowProductCtx(context.Background(), productId, appId)
+}
+
+func (f *FakeClient) ShowProductCtx(ctx context.Context, productId, appId string) (*iot.ProductDetailResponse, error) {
+	result, err := f.invoke(ctx, "ShowProduct", []interface{}{productId, appId}, nil)
+	response, _ := result.(*iot.ProductDetailResponse)
+	return response, err
+}
+
+func (f *FakeClient) UpdateProduct(productId string, request iot.UpdateProductRequest) (*iot.ProductDetailResponse, error) {
+	return f.UpdateProductCtx(context.Background(), productId, request)
+}
+
+func (f *FakeClient) UpdateProductCtx(ctx context.Context, productId string, request iot.UpdateProductRequest) (*iot.ProductDetailResponse, error) {
+	result, err := f.invoke(ctx, "UpdateProduct", []interface{}{productId, request}, nil)
+	response, _ := result.(*iot.ProductDetailResponse)
+	return response, err
+}
+
+func (f *FakeClient) DeleteProduct(productId, appId string) (bool, error) {
+	return f.DeleteProductCtx(context.Background(), productId, appId)
+}
+
+func (f *FakeClient) DeleteProductCtx(ctx context.Context, productId, appId string) (bool, error) {
+	return boolResult(f.invoke(ctx, "DeleteProduct", []interface{}{productId, appId}, nil))
+}
+
+// 设备管理
+
+func (f *FakeClient) ListDevices(queryParas map[string]string) (*iot.ListDeviceResponse, error) {
+	return f.ListDevicesCtx(context.Background(), queryParas)
+}
+
+func (f *FakeClient) ListDevicesCtx(ctx context.Context, queryParas map[string]string) (*iot.ListDeviceResponse, error) {
+	result, err := f.invoke(ctx, "ListDevices", []interface{}{queryParas}, f.serve("ListDevices", nil, queryParas, nil, &iot.ListDeviceResponse{}))
+	response, _ := result.(*iot.ListDeviceResponse)
+	return response, err
+}
+
+func (f *FakeClient) CreateDevice(request iot.CreateDeviceRequest) (*iot.CreateDeviceResponse, error) {
+	return f.CreateDeviceCtx(context.Background(), request)
+}
+
+func (f *FakeClient) CreateDeviceCtx(ctx context.Context, request iot.CreateDeviceRequest) (*iot.CreateDeviceResponse, error) {
+	result, err := f.invoke(ctx, "CreateDevice", []interface{}{request}, f.serve("CreateDevice", nil, nil, request, &iot.CreateDeviceResponse{}))
+	response, _ := result.(*iot.CreateDeviceResponse)
+	return response, err
+}
+
+func (f *FakeClient) ShowDevice(deviceId string) (*iot.DeviceDetailResponse, error) {
+	return f.ShowDeviceCtx(context.Background(), deviceId)
+}
+
+func (f *FakeClient) ShowDeviceCtx(ctx context.Context, deviceId string) (*iot.DeviceDetailResponse, error) {
+	result, err := f.invoke(ctx, "ShowDevice", []interface{}{deviceId}, f.serve("ShowDevice", map[string]string{"device_id": deviceId}, nil, nil, &iot.DeviceDetailResponse{}))
+	response, _ := result.(*iot.DeviceDetailResponse)
+	return response, err
+}
+
+func (f *FakeClient) UpdateDevice(deviceId string, request iot.UpdateDeviceRequest) (*iot.DeviceDetailResponse, error) {
+	return f.UpdateDeviceCtx(context.Background(), deviceId, request)
+}
+
+func (f *FakeClient) UpdateDeviceCtx(ctx context.Context, deviceId string, request iot.UpdateDeviceRequest) (*iot.DeviceDetailResponse, error) {
+	result, err := f.invoke(ctx, "UpdateDevice", []interface{}{deviceId, request}, f.serve("UpdateDevice", map[string]string{"device_id": deviceId}, nil, request, &iot.DeviceDetailResponse{}))
+	response, _ := result.(*iot.DeviceDetailResponse)
+	return response, err
+}
+
+func (f *FakeClient) DeleteDevice(deviceId string) (bool, error) {
+	return f.DeleteDeviceCtx(context.Background(), deviceId)
+}
+
+func (f *FakeClient) DeleteDeviceCtx(ctx context.Context, deviceId string) (bool, error) {
+	return boolResult(f.invoke(ctx, "DeleteDevice", []interface{}{deviceId}, f.serve("DeleteDevice", map[string]string{"device_id": deviceId}, nil, nil, nil)))
+}
+
+func (f *FakeClient) FreezeDevice(deviceId string) (bool, error) {
+	return f.FreezeDeviceCtx(context.Background(), deviceId)
+}
+
+func (f *FakeClient) FreezeDeviceCtx(ctx context.Context, deviceId string) (bool, error) {
+	return boolResult(f.invoke(ctx, "FreezeDevice", []interface{}{deviceId}, f.serve("FreezeDevice", map[string]string{"device_id": deviceId}, nil, nil, nil)))
+}
+
+func (f *FakeClient) UnFreezeDevice(deviceId string) (bool, error) {
+	return f.UnFreezeDeviceCtx(context.Background(), deviceId)
+}
+
+func (f *FakeClient) UnFreezeDeviceCtx(ctx context.Context, deviceId string) (bool, error) {
+	return boolResult(f.invoke(ctx, "UnFreezeDevice", []interface{}{deviceId}, f.serve("UnFreezeDevice", map[string]string{"device_id": deviceId}, nil, nil, nil)))
+}
+
+func (f *FakeClient) ResetDeviceSecret(deviceId, secret string, forceDisconnect bool) (*iot.ResetDeviceSecretResponse, error) {
+	return f.ResetDeviceSecretCtx(context.Background(), deviceId, secret, forceDisconnect)
+}
+
+func (f *FakeClient) ResetDeviceSecretCtx(ctx context.Context, deviceId, secret string, forceDisconnect bool) (*iot.ResetDeviceSecretResponse, error) {
+	result, err := f.invoke(ctx, "ResetDeviceSecret", []interface{}{deviceId, secret, forceDisconnect}, f.serve("ResetDeviceSecret", map[string]string{"device_id": deviceId}, map[string]string{"action_id": "resetSecret"}, map[string]interface{}{"secret": secret, "force_disconnect": forceDisconnect}, &iot.ResetDeviceSecretResponse{}))
+	response, _ := result.(*iot.ResetDeviceSecretResponse)
+	return response, err
+}
+
+// 设备消息
+
+func (f *FakeClient) ListDeviceMessages(deviceId string) (*iot.DeviceMessages, error) {
+	return f.ListDeviceMessagesCtx(context.Background(), deviceId)
+}
+
+func (f *FakeClient) ListDeviceMessagesCtx(ctx context.Context, deviceId string) (*iot.DeviceMessages, error) {
+	result, err := f.invoke(ctx, "ListDeviceMessages", []interface{}{deviceId}, nil)
+	response, _ := result.(*iot.DeviceMessages)
+	return response, err
+}
+
+func (f *FakeClient) ShowDeviceMessage(deviceId, messageId string) (*iot.DeviceMessage, error) {
+	return f.ShowDeviceMessageCtx(context.Background(), deviceId, messageId)
+}
+
+func (f *FakeClient) ShowDeviceMessageCtx(ctx context.Context, deviceId, messageId string) (*iot.DeviceMessage, error) {
+	result, err := f.invoke(ctx, "ShowDeviceMessage", []interface{}{deviceId, messageId}, nil)
+	response, _ := result.(*iot.DeviceMessage)
+	return response, err
+}
+
+func (f *FakeClient) SendDeviceMessage(deviceId string, msg iot.SendDeviceMessageRequest) (*iot.SendDeviceMessageResponse, error) {
+	return f.SendDeviceMessageCtx(context.Background(), deviceId, msg)
+}
+
+func (f *FakeClient) SendDeviceMessageCtx(ctx context.Context, deviceId string, msg iot.SendDeviceMessageRequest) (*iot.SendDeviceMessageResponse, error) {
+	result, err := f.invoke(ctx, "SendDeviceMessage", []interface{}{deviceId, msg}, nil)
+	response, _ := result.(*iot.SendDeviceMessageResponse)
+	return response, err
+}
+
+// 设备命令
+
+func (f *FakeClient) SendDeviceSyncCommand(deviceId string, request iot.DeviceSyncCommandRequest) (*iot.DeviceSyncCommandResponse, error) {
+	return f.SendDeviceSyncCommandCtx(context.Background(), deviceId, request)
+}
+
+func (f *FakeClient) SendDeviceSyncCommandCtx(ctx context.Context, deviceId string, request iot.DeviceSyncCommandRequest) (*iot.DeviceSyncCommandResponse, error) {
+	result, err := f.invoke(ctx, "SendDeviceSyncCommand", []interface{}{deviceId, request}, nil)
+	response, _ := result.(*iot.DeviceSyncCommandResponse)
+	return response, err
+}
+
+func (f *FakeClient) SendDeviceAsyncCommand(deviceId string, request iot.DeviceAsyncCommandRequest) (*iot.DeviceAsyncCommand, error) {
+	return f.SendDeviceAsyncCommandCtx(context.Background(), deviceId, request)
+}
+
+func (f *FakeClient) SendDeviceAsyncCommandCtx(ctx context.Context, deviceId string, request iot.DeviceAsyncCommandRequest) (*iot.DeviceAsyncCommand, error) {
+	result, err := f.invoke(ctx, "SendDeviceAsyncCommand", []interface{}{deviceId, request}, nil)
+	response, _ := result.(*iot.DeviceAsyncCommand)
+	return response, err
+}
+
+func (f *FakeClient) ShowDeviceAsyncCommand(deviceId, commandId string) (*iot.DeviceAsyncCommand, error) {
+	return f.ShowDeviceAsyncCommandCtx(context.Background(), deviceId, commandId)
+}
+
+func (f *FakeClient) ShowDeviceAsyncCommandCtx(ctx context.Context, deviceId, commandId string) (*iot.DeviceAsyncCommand, error) {
+	result, err := f.invoke(ctx, "ShowDeviceAsyncCommand", []interface{}{deviceId, commandId}, nil)
+	response, _ := result.(*iot.DeviceAsyncCommand)
+	return response, err
+}
+
+func (f *FakeClient) ListDeviceAsyncCommands(deviceId string, request iot.ListDeviceAsyncCommandsRequest) (*iot.ListDeviceAsyncCommandsResponse, error) {
+	return f.ListDeviceAsyncCommandsCtx(context.Background(), deviceId, request)
+}
+
+func (f *FakeClient) ListDeviceAsyncCommandsCtx(ctx context.Context, deviceId string, request iot.ListDeviceAsyncCommandsRequest) (*iot.ListDeviceAsyncCommandsResponse, error) {
+	result, err := f.invoke(ctx, "ListDeviceAsyncCommands", []interface{}{deviceId, request}, nil)
+	response, _ := result.(*iot.ListDeviceAsyncCommandsResponse)
+	return response, err
+}
+
+// 设备属性
+
+func (f *FakeClient) QueryDeviceProperties(deviceId, serviceId string) (interface{}, error) {
+	return f.QueryDevicePropertiesCtx(context.Background(), deviceId, serviceId)
+}
+
+func (f *FakeClient) QueryDevicePropertiesCtx(ctx context.Context, deviceId, serviceId string) (interface{}, error) {
+	return f.invoke(ctx, "QueryDeviceProperties", []interface{}{deviceId, serviceId}, nil)
+}
+
+func (f *FakeClient) UpdateDeviceProperties(deviceId string, services interface{}) (bool, error) {
+	return f.UpdateDevicePropertiesCtx(context.Background(), deviceId, services)
+}
+
+func (f *FakeClient) UpdateDevicePropertiesCtx(ctx context.Context, deviceId string, services interface{}) (bool, error) {
+	return boolResult(f.invoke(ctx, "UpdateDeviceProperties", []interface{}{deviceId, services}, nil))
+}
+
+// AMQP队列管理
+
+func (f *FakeClient) ListAmqpQueues(req iot.ListAmqpQueuesRequest) (*iot.ListAmqpQueuesResponse, error) {
+	return f.ListAmqpQueuesCtx(context.Background(), req)
+}
+
+func (f *FakeClient) ListAmqpQueuesCtx(ctx context.Context, req iot.ListAmqpQueuesRequest) (*iot.ListAmqpQueuesResponse, error) {
+	result, err := f.invoke(ctx, "ListAmqpQueues", []interface{}{req}, f.serve("ListAmqpQueues", nil, map[string]string{"queue_name": req.QueueName, "limit": strconv.Itoa(req.Limit), "marker": req.Marker, "offset": req.Offset}, nil, &iot.ListAmqpQueuesResponse{}))
+	response, _ := result.(*iot.ListAmqpQueuesResponse)
+	return response, err
+}
+
+func (f *FakeClient) CreateAmqpQueue(queueName string) (*iot.CreateAmqpQueueResponse, error) {
+	return f.CreateAmqpQueueCtx(context.Background(), queueName)
+}
+
+func (f *FakeClient) CreateAmqpQueueCtx(ctx context.Context, queueName string) (*iot.CreateAmqpQueueResponse, error) {
+	result, err := f.invoke(ctx, "CreateAmqpQueue", []interface{}{queueName}, f.serve("CreateAmqpQueue", nil, nil, map[string]string{"queue_name": queueName}, &iot.CreateAmqpQueueResponse{}))
+	response, _ := result.(*iot.CreateAmqpQueueResponse)
+	return response, err
+}
+
+func (f *FakeClient) ShowAmqpQueue(queueId string) (*iot.ShowAmqpQueueResponse, error) {
+	return f.ShowAmqpQueueCtx(context.Background(), queueId)
+}
+
+func (f *FakeClient) ShowAmqpQueueCtx(ctx context.Context, queueId string) (*iot.ShowAmqpQueueResponse, error) {
+	result, err := f.invoke(ctx, "ShowAmqpQueue", []interface{}{queueId}, f.serve("ShowAmqpQueue", map[string]string{"queue_id": queueId}, nil, nil, &iot.ShowAmqpQueueResponse{}))
+	response, _ := result.(*iot.ShowAmqpQueueResponse)
+	return response, err
+}
+
+func (f *FakeClient) DeleteAmqpQueue(queueId string) (bool, error) {
+	return f.DeleteAmqpQueueCtx(context.Background(), queueId)
+}
+
+func (f *FakeClient) DeleteAmqpQueueCtx(ctx context.Context, queueId string) (bool, error) {
+	return boolResult(f.invoke(ctx, "DeleteAmqpQueue", []interface{}{queueId}, f.serve("DeleteAmqpQueue", map[string]string{"queue_id": queueId}, nil, nil, nil)))
+}
+
+// 接入凭证管理
+
+func (f *FakeClient) CreateAccessCode(accessType string) (*iot.CreateAccessCodeResponse, error) {
+	return f.CreateAccessCodeCtx(context.Background(), accessType)
+}
+
+func (f *FakeClient) CreateAccessCodeCtx(ctx context.Context, accessType string) (*iot.CreateAccessCodeResponse, error) {
+	result, err := f.invoke(ctx, "CreateAccessCode", []interface{}{accessType}, f.serve("CreateAccessCode", nil, nil, map[string]string{"type": accessType}, &iot.CreateAccessCodeResponse{}))
+	response, _ := result.(*iot.CreateAccessCodeResponse)
+	return response, err
+}
+
+// 数据流转规则管理
+
+func (f *FakeClient) ListRoutingRules(request iot.ListRoutingRulesRequest) (*iot.ListRoutingRulesResponse, error) {
+	return f.ListRoutingRulesCtx(context.Background(), request)
+}
+
+func (f *FakeClient) ListRoutingRulesCtx(ctx context.Context, request iot.ListRoutingRulesRequest) (*iot.ListRoutingRulesResponse, error) {
+	result, err := f.invoke(ctx, "ListRoutingRules", []interface{}{request}, nil)
+	response, _ := result.(*iot.ListRoutingRulesResponse)
+	return response, err
+}
+
+func (f *FakeClient) CreateRoutingRule(request iot.CreateRoutingRuleRequest) (*iot.RoutingRuleResponse, error) {
+	return f.CreateRoutingRuleCtx(context.Background(), request)
+}
+
+func (f *FakeClient) CreateRoutingRuleCtx(ctx context.Context, request iot.CreateRoutingRuleRequest) (*iot.RoutingRuleResponse, error) {
+	result, err := f.invoke(ctx, "CreateRoutingRule", []interface{}{request}, nil)
+	response, _ := result.(*iot.RoutingRuleResponse)
+	return response, err
+}
+
+func (f *FakeClient) ShowRoutingRule(ruleId string) (*iot.RoutingRuleResponse, error) {
+	return f.ShowRoutingRuleCtx(context.Background(), ruleId)
+}
+
+func (f *FakeClient) ShowRoutingRuleCtx(ctx context.Context, ruleId string) (*iot.RoutingRuleResponse, error) {
+	result, err := f.invoke(ctx, "ShowRoutingRule", []interface{}{ruleId}, nil)
+	response, _ := result.(*iot.RoutingRuleResponse)
+	return response, err
+}
+
+func (f *FakeClient) UpdateRoutingRule(ruleId string, request iot.UpdateRoutingRuleRequest) (*iot.RoutingRuleResponse, error) {
+	return f.UpdateRoutingRuleCtx(context.Background(), ruleId, request)
+}
+
+func (f *FakeClient) UpdateRoutingRuleCtx(ctx context.Context, ruleId string, request iot.UpdateRoutingRuleRequest) (*iot.RoutingRuleResponse, error) {
+	result, err := f.invoke(ctx, "UpdateRoutingRule", []interface{}{ruleId, request}, nil)
+	response, _ := result.(*iot.RoutingRuleResponse)
+	return response, err
+}
+
+func (f *FakeClient) DeleteRoutingRule(ruleId string) (bool, error) {
+	return f.DeleteRoutingRuleCtx(context.Background(), ruleId)
+}
+
+func (f *FakeClient) DeleteRoutingRuleCtx(ctx context.Context, ruleId string) (bool, error) {
+	return boolResult(f.invoke(ctx, "DeleteRoutingRule", []interface{}{ruleId}, nil))
+}
+
+func (f *FakeClient) ListRuleActions(request iot.ListRuleActionsRequest) (*iot.ListRuleActionsResponse, error) {
+	return f.ListRuleActionsCtx(context.Background(), request)
+}
+
+func (f *FakeClient) ListRuleActionsCtx(ctx context.Context, request iot.ListRuleActionsRequest) (*iot.ListRuleActionsResponse, error) {
+	result, err := f.invoke(ctx, "ListRuleActions", []interface{}{request}, nil)
+	response, _ := result.(*iot.ListRuleActionsResponse)
+	return response, err
+}
+
+func (f *FakeClient) CreateRuleAction(request iot.CreateRuleActionRequest) (*iot.RuleActionResponse, error) {
+	return f.CreateRuleActionCtx(context.Background(), request)
+}
+
+func (f *FakeClient) CreateRuleActionCtx(ctx context.Context, request iot.CreateRuleActionRequest) (*iot.RuleActionResponse, error) {
+	result, err := f.invoke(ctx, "CreateRuleAction", []interface{}{request}, nil)
+	response, _ := result.(*iot.RuleActionResponse)
+	return response, err
+}
+
+func (f *FakeClient) ShowRuleAction(actionId string) (*iot.RuleActionResponse, error) {
+	return f.ShowRuleActionCtx(context.Background(), actionId)
+}
+
+func (f *FakeClient) ShowRuleActionCtx(ctx context.Context, actionId string) (*iot.RuleActionResponse, error) {
+	result, err := f.invoke(ctx, "ShowRuleAction", []interface{}{actionId}, nil)
+	response, _ := result.(*iot.RuleActionResponse)
+	return response, err
+}
+
+func (f *FakeClient) UpdateRuleAction(actionId string, request iot.UpdateRuleActionRequest) (*iot.RuleActionResponse, error) {
+	return f.UpdateRuleActionCtx(context.Background(), actionId, request)
+}
+
+func (f *FakeClient) UpdateRuleActionCtx(ctx context.Context, actionId string, request iot.UpdateRuleActionRequest) (*iot.RuleActionResponse, error) {
+	result, err := f.invoke(ctx, "UpdateRuleAction", []interface{}{actionId, request}, nil)
+	response, _ := result.(*iot.RuleActionResponse)
+	return response, err
+}
+
+func (f *FakeClient) DeleteRuleAction(actionId string) (bool, error) {
+	return f.DeleteRuleActionCtx(context.Background(), actionId)
+}
+
+func (f *FakeClient) DeleteRuleActionCtx(ctx context.Context, actionId string) (bool, error) {
+	return boolResult(f.invoke(ctx, "DeleteRuleAction", []interface{}{actionId}, nil))
+}
+
+// 设备影子
+
+func (f *FakeClient) ShowDeviceShadow(deviceId string) (*iot.ShowDeviceShadowResponse, error) {
+	return f.ShowDeviceShadowCtx(context.Background(), deviceId)
+}
+
+func (f *FakeClient) ShowDeviceShadowCtx(ctx context.Context, deviceId string) (*iot.ShowDeviceShadowResponse, error) {
+	result, err := f.invoke(ctx, "ShowDeviceShadow", []interface{}{deviceId}, f.serve("ShowDeviceShadow", map[string]string{"device_id": deviceId}, nil, nil, &iot.ShowDeviceShadowResponse{}))
+	response, _ := result.(*iot.ShowDeviceShadowResponse)
+	return response, err
+}
+
+func (f *FakeClient) UpdateDeviceShadow(deviceId string, request iot.UpdateDeviceShadowRequest) (*iot.ShowDeviceShadowResponse, error) {
+	return f.UpdateDeviceShadowCtx(context.Background(), deviceId, request)
+}
+
+func (f *FakeClient) UpdateDeviceShadowCtx(ctx context.Context, deviceId string, request iot.UpdateDeviceShadowRequest) (*iot.ShowDeviceShadowResponse, error) {
+	result, err := f.invoke(ctx, "UpdateDeviceShadow", []interface{}{deviceId, request}, f.serve("UpdateDeviceShadow", map[string]string{"device_id": deviceId}, nil, request, &iot.ShowDeviceShadowResponse{}))
+	response, _ := result.(*iot.ShowDeviceShadowResponse)
+	return response, err
+}
+
+// 设备组管理
+
+func (f *FakeClient) ListDeviceGroups(request iot.ListDeviceGroupRequest) (*iot.ListDeviceGroupResponse, error) {
+	return f.ListDeviceGroupsCtx(context.Background(), request)
+}
+
+func (f *FakeClient) ListDeviceGroupsCtx(ctx context.Context, request iot.ListDeviceGroupRequest) (*iot.ListDeviceGroupResponse, error) {
+	result, err := f.invoke(ctx, "ListDeviceGroups", []interface{}{request}, f.serve("ListDeviceGroups", nil, withQuery(pageQuery(request.Limit, request.Marker, request.Offset), "app_id", request.AppId), nil, &iot.ListDeviceGroupResponse{}))
+	response, _ := result.(*iot.ListDeviceGroupResponse)
+	return response, err
+}
+
+func (f *FakeClient) CreateDeviceGroup(request iot.CreateDeviceGroupRequest) (*iot.CreateDeviceGroupResponse, error) {
+	return f.CreateDeviceGroupCtx(context.Background(), request)
+}
+
+func (f *FakeClient) CreateDeviceGroupCtx(ctx context.Context, request iot.CreateDeviceGroupRequest) (*iot.CreateDeviceGroupResponse, error) {
+	result, err := f.invoke(ctx, "CreateDeviceGroup", []interface{}{request}, f.serve("CreateDeviceGroup", nil, nil, request, &iot.CreateDeviceGroupResponse{}))
+	response, _ := result.(*iot.CreateDeviceGroupResponse)
+	return response, err
+}
+
+func (f *FakeClient) ShowDeviceGroup(deviceGroupId string) (*iot.ShowDeviceGroupResponse, error) {
+	return f.ShowDeviceGroupCtx(context.Background(), deviceGroupId)
+}
+
+func (f *FakeClient) ShowDeviceGroupCtx(ctx context.Context, deviceGroupId string) (*iot.ShowDeviceGroupResponse, error) {
+	result, err := f.invoke(ctx, "ShowDeviceGroup", []interface{}{deviceGroupId}, f.serve("ShowDeviceGroup", map[string]string{"group_id": deviceGroupId}, nil, nil, &iot.ShowDeviceGroupResponse{}))
+	response, _ := result.(*iot.ShowDeviceGroupResponse)
+	return response, err
+}
+
+func (f *FakeClient) UpdateDeviceGroup(deviceGroupId string, request iot.UpdateDeviceGroupRequest) (*iot.UpdateDeviceGroupResponse, error) {
+	return f.UpdateDeviceGroupCtx(context.Background(), deviceGroupId, request)
+}
+
+func (f *FakeClient) UpdateDeviceGroupCtx(ctx context.Context, deviceGroupId string, request iot.UpdateDeviceGroupRequest) (*iot.UpdateDeviceGroupResponse, error) {
+	result, err := f.invoke(ctx, "UpdateDeviceGroup", []interface{}{deviceGroupId, request}, f.serve("UpdateDeviceGroup", map[string]string{"group_id": deviceGroupId}, nil, request, &iot.UpdateDeviceGroupResponse{}))
+	response, _ := result.(*iot.UpdateDeviceGroupResponse)
+	return response, err
+}
+
+func (f *FakeClient) DeleteDeviceGroup(deviceGroupId string) (bool, error) {
+	return f.DeleteDeviceGroupCtx(context.Background(), deviceGroupId)
+}
+
+func (f *FakeClient) DeleteDeviceGroupCtx(ctx context.Context, deviceGroupId string) (bool, error) {
+	return boolResult(f.invoke(ctx, "DeleteDeviceGroup", []interface{}{deviceGroupId}, f.serve("DeleteDeviceGroup", map[string]string{"group_id": deviceGroupId}, nil, nil, nil)))
+}
+
+func (f *FakeClient) AddDeviceToDeviceGroup(deviceGroupId, deviceId string) (bool, error) {
+	return f.AddDeviceToDeviceGroupCtx(context.Background(), deviceGroupId, deviceId)
+}
+
+func (f *FakeClient) AddDeviceToDeviceGroupCtx(ctx context.Context, deviceGroupId, deviceId string) (bool, error) {
+	return boolResult(f.invoke(ctx, "AddDeviceToDeviceGroup", []interface{}{deviceGroupId, deviceId}, f.serve("ManageDeviceGroupDevices", map[string]string{"group_id": deviceGroupId}, map[string]string{"action_id": "addDevice", "device_id": deviceId}, nil, nil)))
+}
+
+func (f *FakeClient) RemoveDeviceFromDeviceGroup(deviceGroupId, deviceId string) (bool, error) {
+	return f.RemoveDeviceFromDeviceGroupCtx(context.Background(), deviceGroupId, deviceId)
+}
+
+func (f *FakeClient) RemoveDeviceFromDeviceGroupCtx(ctx context.Context, deviceGroupId, deviceId string) (bool, error) {
+	return boolResult(f.invoke(ctx, "RemoveDeviceFromDeviceGroup", []interface{}{deviceGroupId, deviceId}, f.serve("ManageDeviceGroupDevices", map[string]string{"group_id": deviceGroupId}, map[string]string{"action_id": "removeDevice", "device_id": deviceId}, nil, nil)))
+}
+
+func (f *FakeClient) ListDeviceInDeviceGroup(deviceGroupId string, request iot.ListDeviceInDeviceGroupRequest) (*iot.ListDeviceInDeviceGroupResponse, error) {
+	return f.ListDeviceInDeviceGroupCtx(context.Background(), deviceGroupId, request)
+}
+
+func (f *FakeClient) ListDeviceInDeviceGroupCtx(ctx context.Context, deviceGroupId string, request iot.ListDeviceInDeviceGroupRequest) (*iot.ListDeviceInDeviceGroupResponse, error) {
+	result, err := f.invoke(ctx, "ListDeviceInDeviceGroup", []interface{}{deviceGroupId, request}, f.serve("ListDeviceInDeviceGroup", map[string]string{"group_id": deviceGroupId}, pageQuery(request.Limit, request.Marker, request.Offset), nil, &iot.ListDeviceInDeviceGroupResponse{}))
+	response, _ := result.(*iot.ListDeviceInDeviceGroupResponse)
+	return response, err
+}
+
+// 标签管理
+
+func (f *FakeClient) DeviceBindTags(request iot.DeviceBindTagsRequest) (bool, error) {
+	return f.DeviceBindTagsCtx(context.Background(), request)
+}
+
+func (f *FakeClient) DeviceBindTagsCtx(ctx context.Context, request iot.DeviceBindTagsRequest) (bool, error) {
+	return boolResult(f.invoke(ctx, "DeviceBindTags", []interface{}{request}, f.serve("DeviceBindTags", nil, nil, request, nil)))
+}
+
+func (f *FakeClient) DeviceUnBindTags(request iot.DeviceUnBindTagsRequest) (bool, error) {
+	return f.DeviceUnBindTagsCtx(context.Background(), request)
+}
+
+func (f *FakeClient) DeviceUnBindTagsCtx(ctx context.Context, request iot.DeviceUnBindTagsRequest) (bool, error) {
+	return boolResult(f.invoke(ctx, "DeviceUnBindTags", []interface{}{request}, f.serve("DeviceUnBindTags", nil, nil, request, nil)))
+}
+
+func (f *FakeClient) ListDeviceByTags(request iot.ListDeviceByTagsRequest) (*iot.ListDeviceByTagsResponse, error) {
+	return f.ListDeviceByTagsCtx(context.Background(), request)
+}
+
+func (f *FakeClient) ListDeviceByTagsCtx(ctx context.Context, request iot.ListDeviceByTagsRequest) (*iot.ListDeviceByTagsResponse, error) {
+	result, err := f.invoke(ctx, "ListDeviceByTags", []interface{}{request}, f.serve("ListDeviceByTags", nil, pageQuery(request.Limit, request.Marker, request.Offset), request, &iot.ListDeviceByTagsResponse{}))
+	response, _ := result.(*iot.ListDeviceByTagsResponse)
+	return response, err
+}
+
+// 资源空间管理
+
+func (f *FakeClient) ListApplications() (*iot.Applications, error) {
+	return f.ListApplicationsCtx(context.Background())
+}
+
+func (f *FakeClient) ListApplicationsCtx(ctx context.Context) (*iot.Applications, error) {
+	result, err := f.invoke(ctx, "ListApplications", []interface{}{}, f.serve("ListApplications", nil, nil, nil, &iot.Applications{}))
+	response, _ := result.(*iot.Applications)
+	return response, err
+}
+
+func (f *FakeClient) ShowApplication(appId string) (*iot.Application, error) {
+	return f.ShowApplicationCtx(context.Background(), appId)
+}
+
+func (f *FakeClient) ShowApplicationCtx(ctx context.Context, appId string) (*iot.Application, error) {
+	result, err := f.invoke(ctx, "ShowApplication", []interface{}{appId}, f.serve("ShowApplication", map[string]string{"app_id": appId}, nil, nil, &iot.Application{}))
+	response, _ := result.(*iot.Application)
+	return response, err
+}
+
+func (f *FakeClient) DeleteApplication(appId string) (bool, error) {
+	return f.DeleteApplicationCtx(context.Background(), appId)
+}
+
+func (f *FakeClient) DeleteApplicationCtx(ctx context.Context, appId string) (bool, error) {
+	return boolResult(f.invoke(ctx, "DeleteApplication", []interface{}{appId}, f.serve("DeleteApplication", map[string]string{"app_id": appId}, nil, nil, nil)))
+}
+
+func (f *FakeClient) CreateApplication(request iot.ApplicationCreateRequest) (*iot.Application, error) {
+	return f.CreateApplicationCtx(context.Background(), request)
+}
+
+func (f *FakeClient) CreateApplicationCtx(ctx context.Context, request iot.ApplicationCreateRequest) (*iot.Application, error) {
+	result, err := f.invoke(ctx, "CreateApplication", []interface{}{request}, f.serve("CreateApplication", nil, nil, request, &iot.Application{}))
+	response, _ := result.(*iot.Application)
+	return response, err
+}
+
+// 批量任务
+
+func (f *FakeClient) ListBatchTasks(request iot.ListBatchTasksRequest) (*iot.ListBatchTasksResponse, error) {
+	return f.ListBatchTasksCtx(context.Background(), request)
+}
+
+func (f *FakeClient) ListBatchTasksCtx(ctx context.Context, request iot.ListBatchTasksRequest) (*iot.ListBatchTasksResponse, error) {
+	result, err := f.invoke(ctx, "ListBatchTasks", []interface{}{request}, nil)
+	response, _ := result.(*iot.ListBatchTasksResponse)
+	return response, err
+}
+
+func (f *FakeClient) CreateBatchTask(request iot.CreateBatchTaskRequest) (*iot.BatchTask, error) {
+	return f.CreateBatchTaskCtx(context.Background(), request)
+}
+
+func (f *FakeClient) CreateBatchTaskCtx(ctx context.Context, request iot.CreateBatchTaskRequest) (*iot.BatchTask, error) {
+	result, err := f.invoke(ctx, "CreateBatchTask", []interface{}{request}, nil)
+	response, _ := result.(*iot.BatchTask)
+	return response, err
+}
+
+func (f *FakeClient) ShowBatchTask(taskId string, request iot.ShowBatchTaskRequest) (*iot.ShowBatchTaskResponse, error) {
+	return f.ShowBatchTaskCtx(context.Background(), taskId, request)
+}
+
+func (f *FakeClient) ShowBatchTaskCtx(ctx context.Context, taskId string, request iot.ShowBatchTaskRequest) (*iot.ShowBatchTaskResponse, error) {
+	result, err := f.invoke(ctx, "ShowBatchTask", []interface{}{taskId, request}, nil)
+	response, _ := result.(*iot.ShowBatchTaskResponse)
+	return response, err
+}
+
+func (f *FakeClient) DeleteBatchTask(taskId string) (bool, error) {
+	return f.DeleteBatchTaskCtx(context.Background(), taskId)
+}
+
+func (f *FakeClient) DeleteBatchTaskCtx(ctx context.Context, taskId string) (bool, error) {
+	return boolResult(f.invoke(ctx, "DeleteBatchTask", []interface{}{taskId}, nil))
+}
+
+// 批量任务文件管理
+
+func (f *FakeClient) UploadBatchTaskFile(fileName string, content io.Reader) (*iot.UploadBatchTaskFileResponse, error) {
+	return f.UploadBatchTaskFileCtx(context.Background(), fileName, content)
+}
+
+func (f *FakeClient) UploadBatchTaskFileCtx(ctx context.Context, fileName string, content io.Reader) (*iot.UploadBatchTaskFileResponse, error) {
+	result, err := f.invoke(ctx, "UploadBatchTaskFile", []interface{}{fileName, content}, nil)
+	response, _ := result.(*iot.UploadBatchTaskFileResponse)
+	return response, err
+}
+
+func (f *FakeClient) ListBatchTaskFiles() (*iot.ListBatchTaskFilesResponse, error) {
+	return f.ListBatchTaskFilesCtx(context.Background())
+}
+
+func (f *FakeClient) ListBatchTaskFilesCtx(ctx context.Context) (*iot.ListBatchTaskFilesResponse, error) {
+	result, err := f.invoke(ctx, "ListBatchTaskFiles", []interface{}{}, nil)
+	response, _ := result.(*iot.ListBatchTaskFilesResponse)
+	return response, err
+}
+
+func (f *FakeClient) DeleteBatchTaskFile(fileId string) (bool, error) {
+	return f.DeleteBatchTaskFileCtx(context.Background(), fileId)
+}
+
+func (f *FakeClient) DeleteBatchTaskFileCtx(ctx context.Context, fileId string) (bool, error) {
+	return boolResult(f.invoke(ctx, "DeleteBatchTaskFile", []interface{}{fileId}, nil))
+}
+
+// 设备CA证书管理
+
+func (f *FakeClient) ListDeviceCertificates(request iot.ListDeviceCertificatesRequest) (*iot.ListDeviceCertificatesResponse, error) {
+	return f.ListDeviceCertificatesCtx(context.Background(), request)
+}
+
+func (f *FakeClient) ListDeviceCertificatesCtx(ctx context.Context, request iot.ListDeviceCertificatesRequest) (*iot.ListDeviceCertificatesResponse, error) {
+	result, err := f.invoke(ctx, "ListDeviceCertificates", []interface{}{request}, f.serve("ListDeviceCertificates", nil, withQuery(pageQuery(request.Limit, request.Marker, request.Offset), "app_id", request.AppId), nil, &iot.ListDeviceCertificatesResponse{}))
+	response, _ := result.(*iot.ListDeviceCertificatesResponse)
+	return response, err
+}
+
+func (f *FakeClient) UploadDeviceCertificates(request iot.UploadDeviceCertificatesRequest) (*iot.UploadDeviceCertificatesResponse, error) {
+	return f.UploadDeviceCertificatesCtx(context.Background(), request)
+}
+
+func (f *FakeClient) UploadDeviceCertificatesCtx(ctx context.Context, request iot.UploadDeviceCertificatesRequest) (*iot.UploadDeviceCertificatesResponse, error) {
+	result, err := f.invoke(ctx, "UploadDeviceCertificates", []interface{}{request}, f.serve("UploadDeviceCertificates", nil, nil, request, &iot.UploadDeviceCertificatesResponse{}))
+	response, _ := result.(*iot.UploadDeviceCertificatesResponse)
+	return response, err
+}
+
+func (f *FakeClient) DeleteDeviceCertificates(certificateId string) (bool, error) {
+	return f.DeleteDeviceCertificatesCtx(context.Background(), certificateId)
+}
+
+func (f *FakeClient) DeleteDeviceCertificatesCtx(ctx context.Context, certificateId string) (bool, error) {
+	return boolResult(f.invoke(ctx, "DeleteDeviceCertificates", []interface{}{certificateId}, f.serve("DeleteDeviceCertificates", map[string]string{"certificate_id": certificateId}, nil, nil, nil)))
+}
+
+func (f *FakeClient) VerifyDeviceCertificates(certificateId, verifyContent string) (bool, error) {
+	return f.VerifyDeviceCertificatesCtx(context.Background(), certificateId, verifyContent)
+}
+
+func (f *FakeClient) VerifyDeviceCertificatesCtx(ctx context.Context, certificateId, verifyContent string) (bool, error) {
+	return boolResult(f.invoke(ctx, "VerifyDeviceCertificates", []interface{}{certificateId, verifyContent}, f.serve("VerifyDeviceCertificates", map[string]string{"certificate_id": certificateId}, map[string]string{"action_id": "verify"}, map[string]string{"verify_content": verifyContent}, nil)))
+}
@@ -0,0 +1,232 @@
+package iottest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	iot "huaweicloud-iot-application-sdk-go"
+	"strings"
+	"testing"
+)
+
+// 记录断言失败信息的TestingT
+type fakeT struct {
+	errors []string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestFakeClientReturn(t *testing.T) {
+	fake := NewFakeClient().Return("ShowProduct", &iot.ProductDetailResponse{ProductID: "product", Name: "name"}, nil)
+
+	product, err := fake.ShowProduct("product", "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if product.ProductID != "product" || product.Name != "name" {
+		t.Errorf("ShowProduct() = %+v", product)
+	}
+
+	// Stub覆盖内置实现
+	fake.Return("ShowDevice", &iot.DeviceDetailResponse{DeviceID: "stubbed"}, nil)
+	if device, err := fake.ShowDevice("missing"); err != nil || device.DeviceID != "stubbed" {
+		t.Errorf("ShowDevice() = %+v, %v", device, err)
+	}
+}
+
+func TestFakeClientReturnError(t *testing.T) {
+	fake := NewFakeClient().
+		Return("ShowProduct", nil, iot.ErrApiThrottled).
+		Return("DeleteProduct", false, errors.New("delete failed"))
+
+	if product, err := fake.ShowProduct("product", "app"); product != nil || !errors.Is(err, iot.ErrApiThrottled) {
+		t.Errorf("ShowProduct() = %+v, %v", product, err)
+	}
+	if deleted, err := fake.DeleteProduct("product", "app"); deleted || err == nil || err.Error() != "delete failed" {
+		t.Errorf("DeleteProduct() = %v, %v", deleted, err)
+	}
+}
+
+func TestFakeClientOn(t *testing.T) {
+	fake := NewFakeClient().On("ShowProduct", func(ctx context.Context, args []interface{}) (interface{}, error) {
+		return &iot.ProductDetailResponse{ProductID: args[0].(string), AppID: args[1].(string)}, nil
+	})
+
+	product, err := fake.ShowProduct("product", "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if product.ProductID != "product" || product.AppID != "app" {
+		t.Errorf("ShowProduct() = %+v", product)
+	}
+
+	// 返回nil表示true
+	fake.Return("DeleteProduct", nil, nil)
+	if deleted, err := fake.DeleteProduct("product", "app"); !deleted || err != nil {
+		t.Errorf("DeleteProduct() = %v, %v", deleted, err)
+	}
+}
+
+func TestFakeClientNotStubbed(t *testing.T) {
+	fake := NewFakeClient()
+
+	product, err := fake.ShowProduct("product", "app")
+	if product != nil || !errors.Is(err, ErrNotStubbed) || !strings.Contains(err.Error(), "ShowProduct") {
+		t.Errorf("ShowProduct() = %+v, %v", product, err)
+	}
+	if deleted, err := fake.DeleteProduct("product", "app"); deleted || !errors.Is(err, ErrNotStubbed) {
+		t.Errorf("DeleteProduct() = %v, %v", deleted, err)
+	}
+
+	// 没有Stub的调用也会被记录
+	if fake.CallCount("ShowProduct") != 1 {
+		t.Errorf("CallCount(ShowProduct) = %d", fake.CallCount("ShowProduct"))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	fake.Return("ShowProduct", &iot.ProductDetailResponse{}, nil)
+	if _, err := fake.ShowProductCtx(ctx, "product", "app"); !errors.Is(err, context.Canceled) {
+		t.Errorf("ShowProductCtx() with canceled context error = %v", err)
+	}
+}
+
+func TestFakeClientState(t *testing.T) {
+	fake := NewFakeClient()
+
+	created, err := fake.CreateDevice(iot.CreateDeviceRequest{NodeID: "node", ProductID: "product"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shown, err := fake.ShowDevice(created.DeviceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shown.DeviceID != created.DeviceID || shown.NodeID != "node" {
+		t.Errorf("ShowDevice() = %+v", shown)
+	}
+
+	if deleted, err := fake.DeleteDevice(created.DeviceID); !deleted || err != nil {
+		t.Errorf("DeleteDevice() = %v, %v", deleted, err)
+	}
+
+	// 错误与Server返回的一致
+	_, err = fake.ShowDevice(created.DeviceID)
+	var ae *iot.ApplicationError
+	if !errors.As(err, &ae) || !errors.Is(err, iot.ErrDeviceNotFound) || ae.StatusCode != 404 ||
+		!strings.HasSuffix(ae.Endpoint, "/devices/"+created.DeviceID) {
+		t.Errorf("ShowDevice() after delete error = %#v", err)
+	}
+
+	if _, err := fake.CreateAmqpQueue("queue"); err != nil {
+		t.Fatal(err)
+	}
+	queues, err := fake.ListAmqpQueues(iot.ListAmqpQueuesRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queues.Queues) != 1 || queues.Queues[0].QueueName != "queue" {
+		t.Errorf("ListAmqpQueues() = %+v", queues)
+	}
+
+	fake.Reset()
+	if _, err := fake.ShowDevice(created.DeviceID); !errors.Is(err, iot.ErrDeviceNotFound) {
+		t.Errorf("ShowDevice() after reset error = %v", err)
+	}
+	if queues, _ := fake.ListAmqpQueues(iot.ListAmqpQueuesRequest{}); len(queues.Queues) != 0 {
+		t.Errorf("ListAmqpQueues() after reset = %+v", queues)
+	}
+	if calls := fake.Calls(); len(calls) != 2 {
+		t.Errorf("calls after reset = %v", calls)
+	}
+}
+
+func TestFakeClientCalls(t *testing.T) {
+	fake := NewFakeClient().Return("ShowProduct", &iot.ProductDetailResponse{}, nil)
+
+	_, _ = fake.ShowProduct("product-1", "app")
+	_, _ = fake.ShowDevice("device-1")
+	_, _ = fake.ShowProductCtx(context.Background(), "product-2", "app")
+
+	calls := fake.Calls()
+	expected := []Call{
+		{Operation: "ShowProduct", Args: []interface{}{"product-1", "app"}},
+		{Operation: "ShowDevice", Args: []interface{}{"device-1"}},
+		{Operation: "ShowProduct", Args: []interface{}{"product-2", "app"}},
+	}
+	if fmt.Sprint(calls) != fmt.Sprint(expected) {
+		t.Errorf("Calls() = %v, want %v", calls, expected)
+	}
+
+	if fake.CallCount("ShowProduct") != 2 || fake.CallCount("ShowDevice") != 1 || fake.CallCount("DeleteDevice") != 0 {
+		t.Errorf("CallCount() = %d, %d, %d", fake.CallCount("ShowProduct"), fake.CallCount("ShowDevice"), fake.CallCount("DeleteDevice"))
+	}
+
+	tests := []struct {
+		operation string
+		args      []interface{}
+		called    bool
+	}{
+		{"ShowProduct", nil, true},
+		{"ShowProduct", []interface{}{"product-2"}, true},
+		{"ShowProduct", []interface{}{"product-2", "app"}, true},
+		{"ShowProduct", []interface{}{"product-3"}, false},
+		{"ShowProduct", []interface{}{"product-1", "app", "extra"}, false},
+		{"DeleteDevice", nil, false},
+	}
+	for _, tt := range tests {
+		if called := fake.Called(tt.operation, tt.args...); called != tt.called {
+			t.Errorf("Called(%s, %v) = %v, want %v", tt.operation, tt.args, called, tt.called)
+		}
+	}
+}
+
+func TestFakeClientAssertions(t *testing.T) {
+	fake := NewFakeClient()
+	_, _ = fake.ShowDevice("device-1")
+
+	ft := &fakeT{}
+	if !fake.AssertCalled(ft, "ShowDevice", "device-1") || !fake.AssertNotCalled(ft, "DeleteDevice") ||
+		!fake.AssertCallCount(ft, "ShowDevice", 1) {
+		t.Error("assertions on matching calls failed")
+	}
+	if len(ft.errors) != 0 {
+		t.Errorf("errors = %v", ft.errors)
+	}
+
+	tests := []struct {
+		name   string
+		assert func(t TestingT) bool
+		msg    string
+	}{
+		{
+			name:   "AssertCalled",
+			assert: func(t TestingT) bool { return fake.AssertCalled(t, "ShowDevice", "device-2") },
+			msg:    "expected ShowDevice to be called with [device-2], actual calls: [[device-1]]",
+		},
+		{
+			name:   "AssertNotCalled",
+			assert: func(t TestingT) bool { return fake.AssertNotCalled(t, "ShowDevice", "device-1") },
+			msg:    "expected ShowDevice not to be called with [device-1], actual calls: [[device-1]]",
+		},
+		{
+			name:   "AssertCallCount",
+			assert: func(t TestingT) bool { return fake.AssertCallCount(t, "ShowDevice", 2) },
+			msg:    "expected ShowDevice to be called 2 times, actual 1",
+		},
+	}
+	for _, tt := range tests {
+		ft := &fakeT{}
+		if tt.assert(ft) {
+			t.Errorf("%s: returns true", tt.name)
+		}
+		if len(ft.errors) != 1 || ft.errors[0] != tt.msg {
+			t.Errorf("%s: errors = %q, want %q", tt.name, ft.errors, tt.msg)
+		}
+	}
+}
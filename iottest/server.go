@@ -0,0 +1,462 @@
+// Package iottest 提供进程内的IoTDA模拟服务器，用于在没有网络的环境中测试基于ApplicationClient的代码
+package iottest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	iot "huaweicloud-iot-application-sdk-go"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 模拟服务器的默认鉴权信息
+const (
+	DefaultProjectId = "iottest-project"
+	DefaultAk        = "iottest-ak"
+	DefaultSk        = "iottest-sk"
+	DefaultToken     = "iottest-token"
+)
+
+// 模拟服务器返回的错误码，除了SDK中定义的错误码之外不保证与平台一致
+const (
+	ErrorCodeApiNotFound   = "APIGW.0101"
+	ErrorCodeNotFound      = "IOTDA.000002"
+	ErrorCodeConflict      = "IOTDA.000007"
+	ErrorCodeInternalError = "IOTDA.000003"
+)
+
+// Server 模拟IoTDA应用侧v5接口，包括设备、设备组、标签、设备影子、AMQP队列、证书和资源空间。
+// 状态保存在内存中，请求需要使用AK/SK签名或者携带X-Auth-Token
+type Server struct {
+	*httptest.Server
+
+	ProjectId string
+	Ak        string
+	Sk        string
+	Token     string
+
+	verifier *iot.Verifier
+
+	lock     sync.Mutex
+	state    *state
+	faults   []*Fault
+	requests []RecordedRequest
+}
+
+// Fault 注入到匹配的请求上的故障
+type Fault struct {
+	// 操作名称，与SDK记录日志和指标时使用的名称一致，例如ShowDevice，为空时匹配所有请求
+	Operation string
+	// 处理请求之前的延迟
+	Latency time.Duration
+	// 返回的HTTP状态码，为0时只注入延迟，请求正常处理
+	StatusCode int
+	// 为空时429使用APIGW.0308，其他状态码使用ErrorCodeInternalError
+	ErrorCode string
+	// 大于0时设置Retry-After消息头，单位为秒
+	RetryAfter time.Duration
+	// 生效的次数，为0时一直生效
+	Times int
+}
+
+// RecordedRequest 服务器收到的请求，包括鉴权失败和注入故障的请求
+type RecordedRequest struct {
+	Operation  string
+	Method     string
+	Path       string
+	Query      url.Values
+	Header     http.Header
+	Body       []byte
+	StatusCode int
+	Time       time.Time
+}
+
+// NewServer 启动模拟服务器，使用结束后需要调用Close
+func NewServer() *Server {
+	s := &Server{
+		ProjectId: DefaultProjectId,
+		Ak:        DefaultAk,
+		Sk:        DefaultSk,
+		Token:     DefaultToken,
+	}
+	s.state = newState(s.ProjectId)
+	verifier, err := iot.NewVerifier(func(ctx context.Context, ak string) (string, error) {
+		if ak != s.Ak {
+			return "", fmt.Errorf("access key %s not found", ak)
+		}
+		return s.Sk, nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	s.verifier = verifier
+	s.Server = httptest.NewServer(s)
+
+	return s
+}
+
+// ApplicationOptions 返回连接模拟服务器的配置，使用AK/SK签名
+func (s *Server) ApplicationOptions() *iot.ApplicationOptions {
+	options := iot.NewApplicationOptions().WithEndpoint(s.URL)
+	options.ProjectId = s.ProjectId
+	options.Credential = &iot.Credentials{
+		Ak:      s.Ak,
+		Sk:      s.Sk,
+		UseAkSk: true,
+	}
+
+	return options
+}
+
+// TokenApplicationOptions 返回连接模拟服务器的配置，使用X-Auth-Token鉴权
+func (s *Server) TokenApplicationOptions() *iot.ApplicationOptions {
+	options := iot.NewApplicationOptions().WithEndpoint(s.URL)
+	options.ProjectId = s.ProjectId
+	options.Credential = &iot.Credentials{
+		Token: s.Token,
+	}
+
+	return options
+}
+
+// InjectFault 添加故障，多个故障匹配同一个请求时使用最先添加的
+func (s *Server) InjectFault(fault Fault) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.faults = append(s.faults, &fault)
+}
+
+func (s *Server) ClearFaults() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.faults = nil
+}
+
+// Requests 按照接收顺序返回服务器收到的请求
+func (s *Server) Requests() []RecordedRequest {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	requests := make([]RecordedRequest, len(s.requests))
+	copy(requests, s.requests)
+	return requests
+}
+
+// RequestsFor 返回指定操作的请求
+func (s *Server) RequestsFor(operation string) []RecordedRequest {
+	var requests []RecordedRequest
+	for _, request := range s.Requests() {
+		if request.Operation == operation {
+			requests = append(requests, request)
+		}
+	}
+
+	return requests
+}
+
+func (s *Server) ResetRequests() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.requests = nil
+}
+
+// Reset 清空所有资源、故障和请求记录
+func (s *Server) Reset() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.state = newState(s.ProjectId)
+	s.faults = nil
+	s.requests = nil
+}
+
+// 一次请求的上下文
+type call struct {
+	request *http.Request
+	params  map[string]string
+	query   url.Values
+	body    []byte
+}
+
+func (c *call) decode(v interface{}) *iot.ApplicationResponseError {
+	if len(c.body) == 0 {
+		return newError(iot.ErrInvalidInput.ErrorCode, "request body is empty")
+	}
+
+	if err := json.Unmarshal(c.body, v); err != nil {
+		return newError(iot.ErrInvalidInput.ErrorCode, "malformed request body: "+err.Error())
+	}
+
+	return nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, request *http.Request) {
+	body, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		s.write(w, http.StatusBadRequest, newError(iot.ErrInvalidInput.ErrorCode, "read request body failed"))
+		return
+	}
+	request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	operation := "Unknown"
+	r, params := matchRoute(request.Method, request.URL.Path)
+	if r != nil {
+		operation = r.operation
+	}
+
+	statusCode, response := s.handle(request, r, &call{
+		request: request,
+		params:  params,
+		query:   request.URL.Query(),
+		body:    body,
+	})
+
+	s.lock.Lock()
+	s.requests = append(s.requests, RecordedRequest{
+		Operation:  operation,
+		Method:     request.Method,
+		Path:       request.URL.Path,
+		Query:      request.URL.Query(),
+		Header:     request.Header.Clone(),
+		Body:       body,
+		StatusCode: statusCode,
+		Time:       time.Now(),
+	})
+	s.lock.Unlock()
+
+	if fault, ok := response.(*Fault); ok && fault.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(fault.RetryAfter/time.Second)))
+	}
+	s.write(w, statusCode, response)
+}
+
+func (s *Server) handle(request *http.Request, r *route, c *call) (int, interface{}) {
+	if err := s.authenticate(request, c.body); err != nil {
+		return http.StatusUnauthorized, newError(iot.ErrIamAuthFailed.ErrorCode, err.Error())
+	}
+
+	if r == nil {
+		return http.StatusNotFound, newError(ErrorCodeApiNotFound, "the API does not exist or has not been published")
+	}
+
+	if fault := s.takeFault(r.operation); fault != nil {
+		if fault.Latency > 0 {
+			select {
+			case <-time.After(fault.Latency):
+			case <-request.Context().Done():
+				return http.StatusServiceUnavailable, newError(ErrorCodeInternalError, "request canceled")
+			}
+		}
+		if fault.StatusCode != 0 {
+			return fault.StatusCode, fault
+		}
+	}
+
+	if c.params["project_id"] != s.ProjectId {
+		return http.StatusForbidden, newError(iot.ErrIamAuthFailed.ErrorCode, "project id does not match")
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// 响应中的资源与状态共享内存，因此在锁内序列化
+	statusCode, response := r.handler(s.state, c)
+	if response == nil {
+		return statusCode, nil
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		return http.StatusInternalServerError, newError(ErrorCodeInternalError, err.Error())
+	}
+
+	return statusCode, json.RawMessage(body)
+}
+
+// X-Auth-Token存在时校验Token，否则校验AK/SK签名
+func (s *Server) authenticate(request *http.Request, body []byte) error {
+	if token := request.Header.Get("X-Auth-Token"); len(token) != 0 {
+		if token != s.Token {
+			return fmt.Errorf("invalid token")
+		}
+		return nil
+	}
+
+	_, err := s.verifier.Verify(request, body)
+	return err
+}
+
+func (s *Server) takeFault(operation string) *Fault {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for i, fault := range s.faults {
+		if len(fault.Operation) != 0 && fault.Operation != operation {
+			continue
+		}
+
+		if fault.Times > 0 {
+			fault.Times--
+			if fault.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		f := *fault
+		return &f
+	}
+
+	return nil
+}
+
+func (s *Server) write(w http.ResponseWriter, statusCode int, response interface{}) {
+	if fault, ok := response.(*Fault); ok {
+		response = faultError(fault)
+	}
+
+	w.Header().Set("X-Request-Id", randomHex(16))
+	if statusCode == http.StatusNoContent || response == nil {
+		w.WriteHeader(statusCode)
+		return
+	}
+
+	body, _ := json.Marshal(response)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(body)
+}
+
+func faultError(fault *Fault) *iot.ApplicationResponseError {
+	errorCode := fault.ErrorCode
+	if len(errorCode) == 0 {
+		errorCode = ErrorCodeInternalError
+		if fault.StatusCode == http.StatusTooManyRequests {
+			errorCode = iot.ErrApiThrottled.ErrorCode
+		}
+	}
+
+	return newError(errorCode, "injected fault: "+http.StatusText(fault.StatusCode))
+}
+
+func newError(errorCode, errorMsg string) *iot.ApplicationResponseError {
+	return &iot.ApplicationResponseError{
+		ErrorCode: errorCode,
+		ErrorMsg:  errorMsg,
+	}
+}
+
+type handler func(st *state, c *call) (int, interface{})
+
+type route struct {
+	operation string
+	method    string
+	segments  []string
+	handler   handler
+}
+
+// 路径相对于/v5/iot/{project_id}
+var routes = []*route{
+	newRoute("ListDevices", http.MethodGet, "/devices", listDevices),
+	newRoute("CreateDevice", http.MethodPost, "/devices", createDevice),
+	newRoute("ShowDevice", http.MethodGet, "/devices/{device_id}", showDevice),
+	newRoute("UpdateDevice", http.MethodPut, "/devices/{device_id}", updateDevice),
+	newRoute("DeleteDevice", http.MethodDelete, "/devices/{device_id}", deleteDevice),
+	newRoute("FreezeDevice", http.MethodPost, "/devices/{device_id}/freeze", freezeDevice),
+	newRoute("UnFreezeDevice", http.MethodPost, "/devices/{device_id}/unfreeze", unFreezeDevice),
+	newRoute("ResetDeviceSecret", http.MethodPost, "/devices/{device_id}/action", resetDeviceSecret),
+
+	newRoute("ShowDeviceShadow", http.MethodGet, "/devices/{device_id}/shadow", showDeviceShadow),
+	newRoute("UpdateDeviceShadow", http.MethodPut, "/devices/{device_id}/shadow", updateDeviceShadow),
+
+	newRoute("ListDeviceGroups", http.MethodGet, "/device-group", listDeviceGroups),
+	newRoute("CreateDeviceGroup", http.MethodPost, "/device-group", createDeviceGroup),
+	newRoute("ShowDeviceGroup", http.MethodGet, "/device-group/{group_id}", showDeviceGroup),
+	newRoute("UpdateDeviceGroup", http.MethodPut, "/device-group/{group_id}", updateDeviceGroup),
+	newRoute("DeleteDeviceGroup", http.MethodDelete, "/device-group/{group_id}", deleteDeviceGroup),
+	newRoute("ManageDeviceGroupDevices", http.MethodPost, "/device-group/{group_id}/action", manageDeviceGroupDevices),
+	newRoute("ListDeviceInDeviceGroup", http.MethodGet, "/device-group/{group_id}/devices", listDeviceInDeviceGroup),
+
+	newRoute("DeviceBindTags", http.MethodPost, "/tags/bind-resource", deviceBindTags),
+	newRoute("DeviceUnBindTags", http.MethodPost, "/tags/unbind-resource", deviceUnBindTags),
+	newRoute("ListDeviceByTags", http.MethodPost, "/tags/query-resources", listDeviceByTags),
+
+	newRoute("ListAmqpQueues", http.MethodGet, "/amqp-queues", listAmqpQueues),
+	newRoute("CreateAmqpQueue", http.MethodPost, "/amqp-queues", createAmqpQueue),
+	newRoute("ShowAmqpQueue", http.MethodGet, "/amqp-queues/{queue_id}", showAmqpQueue),
+	newRoute("DeleteAmqpQueue", http.MethodDelete, "/amqp-queues/{queue_id}", deleteAmqpQueue),
+	newRoute("CreateAccessCode", http.MethodPost, "/auth/accesscode", createAccessCode),
+
+	newRoute("ListDeviceCertificates", http.MethodGet, "/certificates", listDeviceCertificates),
+	newRoute("UploadDeviceCertificates", http.MethodPost, "/certificates", uploadDeviceCertificates),
+	newRoute("DeleteDeviceCertificates", http.MethodDelete, "/certificates/{certificate_id}", deleteDeviceCertificates),
+	newRoute("VerifyDeviceCertificates", http.MethodPost, "/certificates/{certificate_id}/action", verifyDeviceCertificates),
+
+	newRoute("ListApplications", http.MethodGet, "/apps", listApplications),
+	newRoute("CreateApplication", http.MethodPost, "/apps", createApplication),
+	newRoute("ShowApplication", http.MethodGet, "/apps/{app_id}", showApplication),
+	newRoute("DeleteApplication", http.MethodDelete, "/apps/{app_id}", deleteApplication),
+}
+
+func newRoute(operation, method, path string, h handler) *route {
+	return &route{
+		operation: operation,
+		method:    method,
+		segments:  splitPath("/v5/iot/{project_id}" + path),
+		handler:   h,
+	}
+}
+
+func matchRoute(method, path string) (*route, map[string]string) {
+	segments := splitPath(path)
+	for _, r := range routes {
+		if r.method != method || len(r.segments) != len(segments) {
+			continue
+		}
+
+		params := map[string]string{}
+		matched := true
+		for i, segment := range r.segments {
+			if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+				params[strings.Trim(segment, "{}")] = segments[i]
+				continue
+			}
+			if segment != segments[i] {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			return r, params
+		}
+	}
+
+	return nil, nil
+}
+
+// 使用路径参数生成请求路径
+func (r *route) path(params map[string]string) string {
+	segments := make([]string, len(r.segments))
+	for i, segment := range r.segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			segment = params[strings.Trim(segment, "{}")]
+		}
+		segments[i] = segment
+	}
+
+	return "/" + strings.Join(segments, "/")
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
@@ -0,0 +1,517 @@
+package iottest
+
+import (
+	"context"
+	"errors"
+	iot "huaweicloud-iot-application-sdk-go"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func fastRetryPolicy() *iot.RetryPolicy {
+	policy := iot.DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	policy.MaxBackoff = 5 * time.Millisecond
+	return policy
+}
+
+func newTestClient(options *iot.ApplicationOptions) iot.ApplicationClient {
+	return iot.CreateSyncIotApplicationClient(*options.SetRetryPolicy(fastRetryPolicy()))
+}
+
+func applicationError(t *testing.T, err error) *iot.ApplicationError {
+	t.Helper()
+
+	var ae *iot.ApplicationError
+	if !errors.As(err, &ae) {
+		t.Fatalf("error = %v, want *iot.ApplicationError", err)
+	}
+
+	return ae
+}
+
+func TestServerDevices(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := newTestClient(server.ApplicationOptions())
+
+	created, err := client.CreateDevice(iot.CreateDeviceRequest{NodeID: "node-1", ProductID: "product", DeviceName: "device"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.DeviceID != "product_node-1" || created.NodeType != "GATEWAY" || len(created.AuthInfo.Secret) == 0 {
+		t.Errorf("created device = %+v", created)
+	}
+
+	if _, err := client.CreateDevice(iot.CreateDeviceRequest{NodeID: "node-1", ProductID: "product"}); applicationError(t, err).StatusCode != http.StatusConflict {
+		t.Errorf("create duplicated device error = %v", err)
+	}
+	if _, err := client.CreateDevice(iot.CreateDeviceRequest{NodeID: "node-2"}); !errors.Is(err, iot.ErrInvalidInput) {
+		t.Errorf("create device without product error = %v", err)
+	}
+
+	updated, err := client.UpdateDevice(created.DeviceID, iot.UpdateDeviceRequest{DeviceName: "renamed", Description: "updated"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.DeviceName != "renamed" || updated.Description != "updated" {
+		t.Errorf("updated device = %+v", updated)
+	}
+
+	shown, err := client.ShowDevice(created.DeviceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shown.DeviceName != "renamed" || shown.ProductID != "product" {
+		t.Errorf("shown device = %+v", shown)
+	}
+
+	if frozen, err := client.FreezeDevice(created.DeviceID); err != nil || !frozen {
+		t.Errorf("FreezeDevice() = %v, %v", frozen, err)
+	}
+	if shown, _ := client.ShowDevice(created.DeviceID); shown.Status != "FROZEN" {
+		t.Errorf("status after freeze = %s", shown.Status)
+	}
+
+	list, err := client.ListDevices(map[string]string{"product_id": "product"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Devices) != 1 || list.Devices[0].DeviceID != created.DeviceID || list.Page.Count != 1 {
+		t.Errorf("ListDevices() = %+v", list)
+	}
+
+	if deleted, err := client.DeleteDevice(created.DeviceID); err != nil || !deleted {
+		t.Errorf("DeleteDevice() = %v, %v", deleted, err)
+	}
+	if _, err := client.ShowDevice(created.DeviceID); !errors.Is(err, iot.ErrDeviceNotFound) || applicationError(t, err).StatusCode != http.StatusNotFound {
+		t.Errorf("show deleted device error = %v", err)
+	}
+
+	requests := server.RequestsFor("CreateDevice")
+	if len(requests) != 3 || requests[0].StatusCode != http.StatusCreated || requests[1].StatusCode != http.StatusConflict {
+		t.Errorf("recorded CreateDevice requests = %+v", requests)
+	}
+}
+
+func TestServerDeviceGroups(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := newTestClient(server.ApplicationOptions())
+
+	device, err := client.CreateDevice(iot.CreateDeviceRequest{NodeID: "node", ProductID: "product"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parent, err := client.CreateDeviceGroup(iot.CreateDeviceGroupRequest{Name: "parent"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, err := client.CreateDeviceGroup(iot.CreateDeviceGroupRequest{Name: "child", SuperGroupID: parent.GroupID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if child.SuperGroupID != parent.GroupID {
+		t.Errorf("child group = %+v", child)
+	}
+
+	if _, err := client.UpdateDeviceGroup(child.GroupID, iot.UpdateDeviceGroupRequest{Name: "renamed", Description: "updated"}); err != nil {
+		t.Fatal(err)
+	}
+	shown, err := client.ShowDeviceGroup(child.GroupID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shown.Name != "renamed" || shown.Description != "updated" {
+		t.Errorf("shown group = %+v", shown)
+	}
+
+	if _, err := client.AddDeviceToDeviceGroup(child.GroupID, device.DeviceID); err != nil {
+		t.Fatal(err)
+	}
+	members, err := client.ListDeviceInDeviceGroup(child.GroupID, iot.ListDeviceInDeviceGroupRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members.Devices) != 1 || members.Devices[0].DeviceID != device.DeviceID {
+		t.Errorf("group members = %+v", members)
+	}
+	if _, err := client.RemoveDeviceFromDeviceGroup(child.GroupID, device.DeviceID); err != nil {
+		t.Fatal(err)
+	}
+	if members, _ := client.ListDeviceInDeviceGroup(child.GroupID, iot.ListDeviceInDeviceGroupRequest{}); len(members.Devices) != 0 {
+		t.Errorf("group members after remove = %+v", members)
+	}
+
+	groups, err := client.ListDeviceGroups(iot.ListDeviceGroupRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups.DeviceGroups) != 2 || groups.Page.Count != 2 {
+		t.Errorf("ListDeviceGroups() = %+v", groups)
+	}
+
+	// 存在子分组时不能删除
+	if _, err := client.DeleteDeviceGroup(parent.GroupID); applicationError(t, err).ErrorCode != ErrorCodeConflict {
+		t.Errorf("delete parent group error = %v", err)
+	}
+	for _, groupId := range []string{child.GroupID, parent.GroupID} {
+		if _, err := client.DeleteDeviceGroup(groupId); err != nil {
+			t.Errorf("DeleteDeviceGroup(%s) error = %v", groupId, err)
+		}
+	}
+	if _, err := client.ShowDeviceGroup(parent.GroupID); applicationError(t, err).StatusCode != http.StatusNotFound {
+		t.Errorf("show deleted group error = %v", err)
+	}
+}
+
+func TestServerAmqpQueues(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := newTestClient(server.ApplicationOptions())
+
+	created, err := client.CreateAmqpQueue("queue-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.QueueName != "queue-1" || len(created.QueueID) == 0 {
+		t.Errorf("created queue = %+v", created)
+	}
+	if _, err := client.CreateAmqpQueue("queue-1"); applicationError(t, err).StatusCode != http.StatusConflict {
+		t.Errorf("create duplicated queue error = %v", err)
+	}
+	if _, err := client.CreateAmqpQueue("queue-2"); err != nil {
+		t.Fatal(err)
+	}
+
+	shown, err := client.ShowAmqpQueue(created.QueueID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shown.QueueName != "queue-1" {
+		t.Errorf("shown queue = %+v", shown)
+	}
+
+	queues, err := client.ListAmqpQueues(iot.ListAmqpQueuesRequest{QueueName: "queue-2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queues.Queues) != 1 || queues.Queues[0].QueueName != "queue-2" {
+		t.Errorf("ListAmqpQueues(queue-2) = %+v", queues)
+	}
+
+	if deleted, err := client.DeleteAmqpQueue(created.QueueID); err != nil || !deleted {
+		t.Errorf("DeleteAmqpQueue() = %v, %v", deleted, err)
+	}
+	if _, err := client.ShowAmqpQueue(created.QueueID); applicationError(t, err).ErrorCode != ErrorCodeNotFound {
+		t.Errorf("show deleted queue error = %v", err)
+	}
+}
+
+func TestServerAuthentication(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	if _, err := newTestClient(server.ApplicationOptions()).ListAmqpQueues(iot.ListAmqpQueuesRequest{}); err != nil {
+		t.Errorf("AK/SK request error = %v", err)
+	}
+	if _, err := newTestClient(server.TokenApplicationOptions()).ListAmqpQueues(iot.ListAmqpQueuesRequest{}); err != nil {
+		t.Errorf("token request error = %v", err)
+	}
+
+	for name, credential := range map[string]iot.Option{
+		"ak/sk": iot.WithAkSk(server.Ak, server.Sk),
+		"token": iot.WithToken(server.Token),
+	} {
+		client, err := iot.NewClient(iot.WithEndpoint(server.URL), iot.WithProjectId(server.ProjectId), credential)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.ListAmqpQueues(iot.ListAmqpQueuesRequest{}); err != nil {
+			t.Errorf("NewClient with %s: error = %v", name, err)
+		}
+	}
+
+	badSk := server.ApplicationOptions()
+	badSk.Credential.Sk = "wrong-sk"
+	badToken := server.TokenApplicationOptions().SetToken("wrong-token")
+	unknownAk := server.ApplicationOptions()
+	unknownAk.Credential.Ak = "unknown-ak"
+
+	for name, options := range map[string]*iot.ApplicationOptions{
+		"bad signature": badSk,
+		"bad token":     badToken,
+		"unknown ak":    unknownAk,
+	} {
+		_, err := newTestClient(options).ListAmqpQueues(iot.ListAmqpQueuesRequest{})
+		if ae := applicationError(t, err); ae.StatusCode != http.StatusUnauthorized || !errors.Is(err, iot.ErrIamAuthFailed) {
+			t.Errorf("%s: error = %v", name, err)
+		}
+	}
+
+	// 未签名的请求
+	response, err := http.Get(server.URL + "/v5/iot/" + server.ProjectId + "/amqp-queues")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = response.Body.Close()
+	if response.StatusCode != http.StatusUnauthorized {
+		t.Errorf("unsigned request status = %d", response.StatusCode)
+	}
+
+	otherProject := server.ApplicationOptions().SetProjectId("other-project")
+	if _, err := newTestClient(otherProject).ListAmqpQueues(iot.ListAmqpQueuesRequest{}); applicationError(t, err).StatusCode != http.StatusForbidden {
+		t.Errorf("other project error = %v", err)
+	}
+
+	for _, request := range server.RequestsFor("ListAmqpQueues") {
+		if len(request.Header.Get("Authorization")) == 0 && len(request.Header.Get("X-Auth-Token")) == 0 &&
+			request.StatusCode != http.StatusUnauthorized {
+			t.Errorf("request without credentials is accepted: %+v", request)
+		}
+	}
+}
+
+func TestServerFaultLatencyAndTimes(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := iot.CreateSyncIotApplicationClient(*server.ApplicationOptions().SetRetryPolicy(iot.NoRetryPolicy()))
+
+	queue, err := client.CreateAmqpQueue("queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server.InjectFault(Fault{Operation: "ShowAmqpQueue", Latency: 100 * time.Millisecond, Times: 1})
+
+	start := time.Now()
+	if _, err := client.ShowAmqpQueue(queue.QueueID); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("first request took %v, want at least the injected latency", elapsed)
+	}
+
+	// Times用完之后故障被移除
+	start = time.Now()
+	if _, err := client.ShowAmqpQueue(queue.QueueID); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed >= 100*time.Millisecond {
+		t.Errorf("second request took %v, want no latency", elapsed)
+	}
+
+	// 请求超时时延迟被中断
+	server.InjectFault(Fault{Operation: "ShowAmqpQueue", Latency: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.ShowAmqpQueueCtx(ctx, queue.QueueID); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("request with latency beyond the deadline error = %v", err)
+	}
+	server.ClearFaults()
+
+	// Operation不匹配的请求不受影响
+	server.InjectFault(Fault{Operation: "DeleteAmqpQueue", StatusCode: http.StatusInternalServerError, Times: 2})
+	if _, err := client.ShowAmqpQueue(queue.QueueID); err != nil {
+		t.Errorf("ShowAmqpQueue() error = %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := client.DeleteAmqpQueue(queue.QueueID); applicationError(t, err).StatusCode != http.StatusInternalServerError {
+			t.Errorf("DeleteAmqpQueue() #%d error = %v", i+1, err)
+		}
+	}
+	if _, err := client.DeleteAmqpQueue(queue.QueueID); err != nil {
+		t.Errorf("DeleteAmqpQueue() after the fault is used up error = %v", err)
+	}
+}
+
+func TestServerThrottlingWithRetryAfter(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	var attempts []iot.RetryAttempt
+	policy := fastRetryPolicy()
+	policy.OnAttempt = func(attempt iot.RetryAttempt) { attempts = append(attempts, attempt) }
+	client := iot.CreateSyncIotApplicationClient(*server.ApplicationOptions().SetRetryPolicy(policy))
+
+	server.InjectFault(Fault{Operation: "CreateAmqpQueue", StatusCode: http.StatusTooManyRequests, RetryAfter: time.Second, Times: 1})
+
+	// 429时非幂等操作也会重试，等待时间使用Retry-After
+	start := time.Now()
+	if _, err := client.CreateAmqpQueue("queue"); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("request took %v, want at least Retry-After", elapsed)
+	}
+
+	if len(attempts) != 2 || attempts[0].StatusCode != http.StatusTooManyRequests || attempts[0].Delay != time.Second ||
+		attempts[1].StatusCode != http.StatusCreated {
+		t.Errorf("attempts = %+v", attempts)
+	}
+
+	requests := server.RequestsFor("CreateAmqpQueue")
+	if len(requests) != 2 || requests[0].StatusCode != http.StatusTooManyRequests {
+		t.Errorf("recorded requests = %+v", requests)
+	}
+
+	// 不重试时返回平台的流控错误
+	server.InjectFault(Fault{Operation: "ListAmqpQueues", StatusCode: http.StatusTooManyRequests, RetryAfter: time.Second})
+	noRetry := iot.CreateSyncIotApplicationClient(*server.ApplicationOptions().SetRetryPolicy(iot.NoRetryPolicy()))
+	if _, err := noRetry.ListAmqpQueues(iot.ListAmqpQueuesRequest{}); !iot.IsThrottled(err) || !errors.Is(err, iot.ErrApiThrottled) {
+		t.Errorf("throttled error = %v", err)
+	}
+}
+
+func TestServerServerErrors(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := newTestClient(server.ApplicationOptions())
+
+	queue, err := client.CreateAmqpQueue("queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 幂等操作遇到5xx时重试
+	server.InjectFault(Fault{Operation: "ShowAmqpQueue", StatusCode: http.StatusServiceUnavailable, Times: 2})
+	if _, err := client.ShowAmqpQueue(queue.QueueID); err != nil {
+		t.Errorf("ShowAmqpQueue() error = %v", err)
+	}
+	if requests := server.RequestsFor("ShowAmqpQueue"); len(requests) != 3 {
+		t.Errorf("ShowAmqpQueue requests = %d, want 3", len(requests))
+	}
+
+	// 非幂等操作不重试
+	server.InjectFault(Fault{Operation: "CreateAmqpQueue", StatusCode: http.StatusInternalServerError, ErrorCode: "IOTDA.000500"})
+	_, err = client.CreateAmqpQueue("other")
+	if ae := applicationError(t, err); ae.StatusCode != http.StatusInternalServerError || ae.ErrorCode != "IOTDA.000500" || !iot.IsServerError(err) {
+		t.Errorf("CreateAmqpQueue() error = %v", err)
+	}
+	if requests := server.RequestsFor("CreateAmqpQueue"); len(requests) != 2 {
+		t.Errorf("CreateAmqpQueue requests = %d, want 2", len(requests))
+	}
+
+	// 重试次数用完时返回最后一次的错误
+	server.InjectFault(Fault{Operation: "ListAmqpQueues", StatusCode: http.StatusBadGateway})
+	_, err = client.ListAmqpQueues(iot.ListAmqpQueuesRequest{})
+	if ae := applicationError(t, err); ae.StatusCode != http.StatusBadGateway || ae.ErrorCode != ErrorCodeInternalError {
+		t.Errorf("ListAmqpQueues() error = %v", err)
+	}
+	if requests := server.RequestsFor("ListAmqpQueues"); len(requests) != 3 {
+		t.Errorf("ListAmqpQueues requests = %d, want 3", len(requests))
+	}
+}
+
+func TestServerPagination(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := newTestClient(server.ApplicationOptions())
+
+	var names, ids []string
+	for i := 0; i < 7; i++ {
+		queue, err := client.CreateAmqpQueue("queue-" + strconv.Itoa(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, queue.QueueName)
+		ids = append(ids, queue.QueueID)
+	}
+
+	page := func(request iot.ListAmqpQueuesRequest) ([]string, iot.Page) {
+		t.Helper()
+
+		response, err := client.ListAmqpQueues(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var result []string
+		for _, queue := range response.Queues {
+			result = append(result, queue.QueueName)
+		}
+		return result, response.Page
+	}
+
+	first, firstPage := page(iot.ListAmqpQueuesRequest{Limit: 3})
+	if !equalStrings(first, names[:3]) || firstPage.Count != 7 {
+		t.Errorf("first page = %v, %+v", first, firstPage)
+	}
+
+	second, secondPage := page(iot.ListAmqpQueuesRequest{Limit: 3, Marker: firstPage.Marker})
+	if !equalStrings(second, names[3:6]) {
+		t.Errorf("page after marker = %v", second)
+	}
+
+	// offset在marker之后继续跳过
+	skipped, _ := page(iot.ListAmqpQueuesRequest{Limit: 3, Marker: firstPage.Marker, Offset: "2"})
+	if !equalStrings(skipped, names[5:]) {
+		t.Errorf("page after marker with offset = %v", skipped)
+	}
+
+	last, lastPage := page(iot.ListAmqpQueuesRequest{Limit: 3, Marker: secondPage.Marker})
+	if !equalStrings(last, names[6:]) || lastPage.Marker != ids[6] {
+		t.Errorf("last page = %v, %+v", last, lastPage)
+	}
+
+	all, _ := page(iot.ListAmqpQueuesRequest{})
+	if !equalStrings(all, names) {
+		t.Errorf("default page = %v", all)
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	ids := make([]string, 600)
+	for i := range ids {
+		ids[i] = strconv.Itoa(i)
+	}
+
+	tests := []struct {
+		query  url.Values
+		first  string
+		length int
+	}{
+		{url.Values{}, "0", 10},
+		{url.Values{"limit": {"0"}}, "0", 10},
+		{url.Values{"limit": {"-1"}}, "0", 10},
+		{url.Values{"limit": {"100"}}, "0", 50},
+		{url.Values{"offset": {"20"}, "limit": {"5"}}, "20", 5},
+		{url.Values{"offset": {"-5"}}, "0", 10},
+		{url.Values{"offset": {"1000"}}, "500", 10},
+		{url.Values{"marker": {"9"}, "offset": {"1"}}, "11", 10},
+		{url.Values{"marker": {"unknown"}}, "0", 10},
+		{url.Values{"marker": {"595"}, "limit": {"50"}}, "596", 4},
+	}
+
+	for _, tt := range tests {
+		page, result := paginate(&call{query: tt.query}, ids)
+		if len(page) != tt.length || (len(page) != 0 && page[0] != tt.first) {
+			t.Errorf("paginate(%v) = %d ids from %v, want %d ids from %s", tt.query, len(page), page, tt.length, tt.first)
+			continue
+		}
+		if result.Count != len(ids) || result.Marker != page[len(page)-1] {
+			t.Errorf("paginate(%v) page = %+v", tt.query, result)
+		}
+	}
+
+	if page, result := paginate(&call{query: url.Values{"marker": {"599"}}}, ids); len(page) != 0 || len(result.Marker) != 0 {
+		t.Errorf("paginate after the last id = %v, %+v", page, result)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,925 @@
+package iottest
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	iot "huaweicloud-iot-application-sdk-go"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const timeFormat = "20060102T150405Z"
+
+// 模拟服务器的内存状态，所有方法都在Server.lock内调用
+type state struct {
+	sequence int
+
+	defaultAppId string
+
+	devices     map[string]*device
+	deviceOrder []string
+
+	groups     map[string]*group
+	groupOrder []string
+
+	queues     map[string]*iot.QueryQueueBase
+	queueOrder []string
+
+	certificates     map[string]*iot.CertificatesRspDTO
+	certificateOrder []string
+
+	apps     map[string]*iot.Application
+	appOrder []string
+}
+
+type device struct {
+	detail iot.DeviceDetailResponse
+	shadow []iot.DeviceShadowData
+	// 冻结之前的状态，解冻时恢复
+	status string
+}
+
+type group struct {
+	detail  iot.DeviceGroupResponseDTO
+	appId   string
+	members []string
+}
+
+// 每个项目都有一个默认资源空间
+func newState(projectId string) *state {
+	st := &state{
+		devices:      map[string]*device{},
+		groups:       map[string]*group{},
+		queues:       map[string]*iot.QueryQueueBase{},
+		certificates: map[string]*iot.CertificatesRspDTO{},
+		apps:         map[string]*iot.Application{},
+	}
+
+	app := &iot.Application{
+		AppId:      st.newId(),
+		AppName:    "DefaultApp_" + projectId,
+		CreateTime: now(),
+		DefaultApp: true,
+	}
+	st.defaultAppId = app.AppId
+	st.apps[app.AppId] = app
+	st.appOrder = append(st.appOrder, app.AppId)
+
+	return st
+}
+
+func (st *state) newId() string {
+	st.sequence++
+	return fmt.Sprintf("%08x%s", st.sequence, randomHex(12))
+}
+
+func (st *state) appId(appId string) string {
+	if len(appId) == 0 {
+		return st.defaultAppId
+	}
+
+	return appId
+}
+
+func now() string {
+	return time.Now().UTC().Format(timeFormat)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func deviceNotFound(deviceId string) (int, interface{}) {
+	return http.StatusNotFound, newError(iot.ErrDeviceNotFound.ErrorCode, "device "+deviceId+" not found")
+}
+
+func notFound(resource, id string) (int, interface{}) {
+	return http.StatusNotFound, newError(ErrorCodeNotFound, resource+" "+id+" not found")
+}
+
+func invalidInput(errorMsg string) (int, interface{}) {
+	return http.StatusBadRequest, newError(iot.ErrInvalidInput.ErrorCode, errorMsg)
+}
+
+// 按照平台的分页规则返回一页ID：从marker之后开始，跳过offset条，最多返回limit条
+func paginate(c *call, ids []string) ([]string, iot.Page) {
+	limit, err := strconv.Atoi(c.query.Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+	if limit > 50 {
+		limit = 50
+	}
+	offset, err := strconv.Atoi(c.query.Get("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	if offset > 500 {
+		offset = 500
+	}
+
+	start := 0
+	if marker := c.query.Get("marker"); len(marker) != 0 {
+		for i, id := range ids {
+			if id == marker {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	start += offset
+	if start > len(ids) {
+		start = len(ids)
+	}
+	end := start + limit
+	if end > len(ids) {
+		end = len(ids)
+	}
+
+	page := iot.Page{Count: len(ids)}
+	if end > start {
+		page.Marker = ids[end-1]
+	}
+
+	return ids[start:end], page
+}
+
+func removeId(ids []string, id string) []string {
+	for i, v := range ids {
+		if v == id {
+			return append(ids[:i], ids[i+1:]...)
+		}
+	}
+
+	return ids
+}
+
+// 设备
+
+func (d *device) simplify() iot.QueryDeviceSimplify {
+	return iot.QueryDeviceSimplify{
+		AppID:       d.detail.AppID,
+		AppName:     d.detail.AppName,
+		DeviceID:    d.detail.DeviceID,
+		NodeID:      d.detail.NodeID,
+		GatewayID:   d.detail.GatewayID,
+		DeviceName:  d.detail.DeviceName,
+		NodeType:    d.detail.NodeType,
+		Description: d.detail.Description,
+		FwVersion:   d.detail.FwVersion,
+		SwVersion:   d.detail.SwVersion,
+		ProductID:   d.detail.ProductID,
+		ProductName: d.detail.ProductName,
+		Status:      d.detail.Status,
+		Tags:        d.detail.Tags,
+	}
+}
+
+func listDevices(st *state, c *call) (int, interface{}) {
+	var ids []string
+	for _, id := range st.deviceOrder {
+		d := st.devices[id]
+		if productId := c.query.Get("product_id"); len(productId) != 0 && d.detail.ProductID != productId {
+			continue
+		}
+		if appId := c.query.Get("app_id"); len(appId) != 0 && d.detail.AppID != appId {
+			continue
+		}
+		if nodeId := c.query.Get("node_id"); len(nodeId) != 0 && d.detail.NodeID != nodeId {
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	ids, page := paginate(c, ids)
+	response := &iot.ListDeviceResponse{Devices: []iot.QueryDeviceSimplify{}, Page: page}
+	for _, id := range ids {
+		response.Devices = append(response.Devices, st.devices[id].simplify())
+	}
+
+	return http.StatusOK, response
+}
+
+func createDevice(st *state, c *call) (int, interface{}) {
+	request := &iot.CreateDeviceRequest{}
+	if err := c.decode(request); err != nil {
+		return http.StatusBadRequest, err
+	}
+	if len(request.NodeID) == 0 || len(request.ProductID) == 0 {
+		return invalidInput("node_id and product_id are required")
+	}
+
+	deviceId := request.DeviceID
+	if len(deviceId) == 0 {
+		deviceId = request.ProductID + "_" + request.NodeID
+	}
+	if _, ok := st.devices[deviceId]; ok {
+		return http.StatusConflict, newError(ErrorCodeConflict, "device "+deviceId+" already exists")
+	}
+
+	appId := st.appId(request.AppID)
+	app, ok := st.apps[appId]
+	if !ok {
+		return notFound("application", appId)
+	}
+
+	authInfo := request.AuthInfo
+	if len(authInfo.AuthType) == 0 {
+		authInfo.AuthType = "SECRET"
+	}
+	if authInfo.AuthType == "SECRET" && len(authInfo.Secret) == 0 {
+		authInfo.Secret = randomHex(16)
+	}
+
+	nodeType := "ENDPOINT"
+	if len(request.GatewayID) == 0 {
+		request.GatewayID = deviceId
+		nodeType = "GATEWAY"
+	}
+
+	d := &device{
+		detail: iot.DeviceDetailResponse{
+			AppID:         appId,
+			AppName:       app.AppName,
+			DeviceID:      deviceId,
+			NodeID:        request.NodeID,
+			GatewayID:     request.GatewayID,
+			DeviceName:    request.DeviceName,
+			NodeType:      nodeType,
+			Description:   request.Description,
+			AuthInfo:      authInfo,
+			ProductID:     request.ProductID,
+			Status:        "INACTIVE",
+			CreateTime:    now(),
+			Tags:          []iot.TagV5DTO{},
+			ExtensionInfo: request.ExtensionInfo,
+		},
+	}
+	for _, desired := range request.Shadow {
+		d.shadow = append(d.shadow, iot.DeviceShadowData{
+			ServiceID: desired.ServiceID,
+			Desired:   iot.DeviceShadowProperties{Properties: desired.Desired, EventTime: now()},
+			Version:   1,
+		})
+	}
+	st.devices[deviceId] = d
+	st.deviceOrder = append(st.deviceOrder, deviceId)
+
+	response := iot.CreateDeviceResponse(d.detail)
+	return http.StatusCreated, &response
+}
+
+func showDevice(st *state, c *call) (int, interface{}) {
+	d, ok := st.devices[c.params["device_id"]]
+	if !ok {
+		return deviceNotFound(c.params["device_id"])
+	}
+
+	return http.StatusOK, &d.detail
+}
+
+func updateDevice(st *state, c *call) (int, interface{}) {
+	d, ok := st.devices[c.params["device_id"]]
+	if !ok {
+		return deviceNotFound(c.params["device_id"])
+	}
+
+	request := &iot.UpdateDeviceRequest{}
+	if err := c.decode(request); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	if len(request.DeviceName) != 0 {
+		d.detail.DeviceName = request.DeviceName
+	}
+	if len(request.Description) != 0 {
+		d.detail.Description = request.Description
+	}
+	if request.ExtensionInfo != nil {
+		d.detail.ExtensionInfo = request.ExtensionInfo
+	}
+	if request.AuthInfo.Timeout != 0 {
+		d.detail.AuthInfo.Timeout = request.AuthInfo.Timeout
+	}
+	d.detail.AuthInfo.SecureAccess = request.AuthInfo.SecureAccess
+
+	return http.StatusOK, &d.detail
+}
+
+func deleteDevice(st *state, c *call) (int, interface{}) {
+	deviceId := c.params["device_id"]
+	if _, ok := st.devices[deviceId]; !ok {
+		return deviceNotFound(deviceId)
+	}
+
+	delete(st.devices, deviceId)
+	st.deviceOrder = removeId(st.deviceOrder, deviceId)
+	for _, g := range st.groups {
+		g.members = removeId(g.members, deviceId)
+	}
+
+	return http.StatusNoContent, nil
+}
+
+func freezeDevice(st *state, c *call) (int, interface{}) {
+	d, ok := st.devices[c.params["device_id"]]
+	if !ok {
+		return deviceNotFound(c.params["device_id"])
+	}
+
+	if d.detail.Status != "FROZEN" {
+		d.status = d.detail.Status
+		d.detail.Status = "FROZEN"
+	}
+
+	return http.StatusNoContent, nil
+}
+
+func unFreezeDevice(st *state, c *call) (int, interface{}) {
+	d, ok := st.devices[c.params["device_id"]]
+	if !ok {
+		return deviceNotFound(c.params["device_id"])
+	}
+
+	if d.detail.Status == "FROZEN" {
+		d.detail.Status = d.status
+	}
+
+	return http.StatusNoContent, nil
+}
+
+func resetDeviceSecret(st *state, c *call) (int, interface{}) {
+	d, ok := st.devices[c.params["device_id"]]
+	if !ok {
+		return deviceNotFound(c.params["device_id"])
+	}
+	if c.query.Get("action_id") != "resetSecret" {
+		return invalidInput("unsupported action_id " + c.query.Get("action_id"))
+	}
+
+	request := &struct {
+		Secret string `json:"secret"`
+	}{}
+	if err := c.decode(request); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	if len(request.Secret) == 0 {
+		request.Secret = randomHex(16)
+	}
+	d.detail.AuthInfo.Secret = request.Secret
+
+	return http.StatusOK, &iot.ResetDeviceSecretResponse{
+		DeviceId: d.detail.DeviceID,
+		Secret:   request.Secret,
+	}
+}
+
+// 设备影子
+
+func showDeviceShadow(st *state, c *call) (int, interface{}) {
+	d, ok := st.devices[c.params["device_id"]]
+	if !ok {
+		return deviceNotFound(c.params["device_id"])
+	}
+
+	return http.StatusOK, d.shadowResponse()
+}
+
+// 每个服务的期望值整体替换，Version大于0时必须与当前版本一致
+func updateDeviceShadow(st *state, c *call) (int, interface{}) {
+	d, ok := st.devices[c.params["device_id"]]
+	if !ok {
+		return deviceNotFound(c.params["device_id"])
+	}
+
+	request := &iot.UpdateDeviceShadowRequest{}
+	if err := c.decode(request); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	for _, desired := range request.Shadow {
+		index := -1
+		for i, shadow := range d.shadow {
+			if shadow.ServiceID == desired.ServiceId {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			d.shadow = append(d.shadow, iot.DeviceShadowData{ServiceID: desired.ServiceId})
+			index = len(d.shadow) - 1
+		}
+
+		shadow := &d.shadow[index]
+		if desired.Version > 0 && desired.Version != shadow.Version {
+			return http.StatusConflict, newError(ErrorCodeConflict,
+				fmt.Sprintf("shadow version of service %s is %d", desired.ServiceId, shadow.Version))
+		}
+		shadow.Desired = iot.DeviceShadowProperties{Properties: desired.Desired, EventTime: now()}
+		shadow.Version++
+	}
+
+	return http.StatusOK, d.shadowResponse()
+}
+
+func (d *device) shadowResponse() *iot.ShowDeviceShadowResponse {
+	response := &iot.ShowDeviceShadowResponse{
+		DeviceID: d.detail.DeviceID,
+		Shadow:   make([]iot.DeviceShadowData, len(d.shadow)),
+	}
+	copy(response.Shadow, d.shadow)
+
+	return response
+}
+
+// 设备组
+
+func listDeviceGroups(st *state, c *call) (int, interface{}) {
+	var ids []string
+	for _, id := range st.groupOrder {
+		if appId := c.query.Get("app_id"); len(appId) != 0 && st.groups[id].appId != appId {
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	ids, page := paginate(c, ids)
+	response := &iot.ListDeviceGroupResponse{DeviceGroups: []iot.DeviceGroupResponseDTO{}, Page: page}
+	for _, id := range ids {
+		response.DeviceGroups = append(response.DeviceGroups, st.groups[id].detail)
+	}
+
+	return http.StatusOK, response
+}
+
+func createDeviceGroup(st *state, c *call) (int, interface{}) {
+	request := &iot.CreateDeviceGroupRequest{}
+	if err := c.decode(request); err != nil {
+		return http.StatusBadRequest, err
+	}
+	if len(request.Name) == 0 {
+		return invalidInput("name is required")
+	}
+	if len(request.SuperGroupID) != 0 {
+		if _, ok := st.groups[request.SuperGroupID]; !ok {
+			return notFound("device group", request.SuperGroupID)
+		}
+	}
+
+	g := &group{
+		detail: iot.DeviceGroupResponseDTO{
+			GroupId:      st.newId(),
+			Name:         request.Name,
+			Description:  request.Description,
+			SuperGroupId: request.SuperGroupID,
+		},
+		appId: st.appId(request.AppID),
+	}
+	st.groups[g.detail.GroupId] = g
+	st.groupOrder = append(st.groupOrder, g.detail.GroupId)
+
+	return http.StatusCreated, &iot.CreateDeviceGroupResponse{
+		GroupID:      g.detail.GroupId,
+		Name:         g.detail.Name,
+		Description:  g.detail.Description,
+		SuperGroupID: g.detail.SuperGroupId,
+	}
+}
+
+func showDeviceGroup(st *state, c *call) (int, interface{}) {
+	g, ok := st.groups[c.params["group_id"]]
+	if !ok {
+		return notFound("device group", c.params["group_id"])
+	}
+
+	return http.StatusOK, &iot.ShowDeviceGroupResponse{
+		GroupID:      g.detail.GroupId,
+		Name:         g.detail.Name,
+		Description:  g.detail.Description,
+		SuperGroupID: g.detail.SuperGroupId,
+	}
+}
+
+func updateDeviceGroup(st *state, c *call) (int, interface{}) {
+	g, ok := st.groups[c.params["group_id"]]
+	if !ok {
+		return notFound("device group", c.params["group_id"])
+	}
+
+	request := &iot.UpdateDeviceGroupRequest{}
+	if err := c.decode(request); err != nil {
+		return http.StatusBadRequest, err
+	}
+	if len(request.Name) != 0 {
+		g.detail.Name = request.Name
+	}
+	g.detail.Description = request.Description
+
+	return http.StatusOK, &iot.UpdateDeviceGroupResponse{
+		GroupID:      g.detail.GroupId,
+		Name:         g.detail.Name,
+		Description:  g.detail.Description,
+		SuperGroupID: g.detail.SuperGroupId,
+	}
+}
+
+func deleteDeviceGroup(st *state, c *call) (int, interface{}) {
+	groupId := c.params["group_id"]
+	if _, ok := st.groups[groupId]; !ok {
+		return notFound("device group", groupId)
+	}
+
+	for _, g := range st.groups {
+		if g.detail.SuperGroupId == groupId {
+			return http.StatusConflict, newError(ErrorCodeConflict, "device group "+groupId+" has sub groups")
+		}
+	}
+
+	delete(st.groups, groupId)
+	st.groupOrder = removeId(st.groupOrder, groupId)
+
+	return http.StatusNoContent, nil
+}
+
+func manageDeviceGroupDevices(st *state, c *call) (int, interface{}) {
+	g, ok := st.groups[c.params["group_id"]]
+	if !ok {
+		return notFound("device group", c.params["group_id"])
+	}
+
+	deviceId := c.query.Get("device_id")
+	if _, ok := st.devices[deviceId]; !ok {
+		return deviceNotFound(deviceId)
+	}
+
+	switch c.query.Get("action_id") {
+	case "addDevice":
+		g.members = append(removeId(g.members, deviceId), deviceId)
+	case "removeDevice":
+		g.members = removeId(g.members, deviceId)
+	default:
+		return invalidInput("unsupported action_id " + c.query.Get("action_id"))
+	}
+
+	return http.StatusOK, nil
+}
+
+func listDeviceInDeviceGroup(st *state, c *call) (int, interface{}) {
+	g, ok := st.groups[c.params["group_id"]]
+	if !ok {
+		return notFound("device group", c.params["group_id"])
+	}
+
+	ids, page := paginate(c, g.members)
+	response := &iot.ListDeviceInDeviceGroupResponse{Devices: []iot.SimplifyDevice{}, Page: page}
+	for _, id := range ids {
+		d := st.devices[id]
+		response.Devices = append(response.Devices, iot.SimplifyDevice{
+			DeviceID:   d.detail.DeviceID,
+			NodeID:     d.detail.NodeID,
+			DeviceName: d.detail.DeviceName,
+			ProductID:  d.detail.ProductID,
+		})
+	}
+
+	return http.StatusOK, response
+}
+
+// 标签，只支持设备资源
+
+func deviceBindTags(st *state, c *call) (int, interface{}) {
+	request := &iot.DeviceBindTagsRequest{}
+	if err := c.decode(request); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	d, ok := st.devices[request.ResourceID]
+	if !ok {
+		return deviceNotFound(request.ResourceID)
+	}
+
+	for _, tag := range request.Tags {
+		replaced := false
+		for i := range d.detail.Tags {
+			if d.detail.Tags[i].TagKey == tag.TagKey {
+				d.detail.Tags[i].TagValue = tag.TagValue
+				replaced = true
+			}
+		}
+		if !replaced {
+			d.detail.Tags = append(d.detail.Tags, tag)
+		}
+	}
+
+	return http.StatusOK, nil
+}
+
+func deviceUnBindTags(st *state, c *call) (int, interface{}) {
+	request := &iot.DeviceUnBindTagsRequest{}
+	if err := c.decode(request); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	d, ok := st.devices[request.ResourceID]
+	if !ok {
+		return deviceNotFound(request.ResourceID)
+	}
+
+	tags := []iot.TagV5DTO{}
+	for _, tag := range d.detail.Tags {
+		if !containsString(request.TagKeys, tag.TagKey) {
+			tags = append(tags, tag)
+		}
+	}
+	d.detail.Tags = tags
+
+	return http.StatusOK, nil
+}
+
+// 返回包含所有指定标签的设备，标签值为空时只匹配标签键
+func listDeviceByTags(st *state, c *call) (int, interface{}) {
+	request := &iot.ListDeviceByTagsRequest{}
+	if err := c.decode(request); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	var ids []string
+	for _, id := range st.deviceOrder {
+		if hasTags(st.devices[id].detail.Tags, request.Tags) {
+			ids = append(ids, id)
+		}
+	}
+
+	ids, page := paginate(c, ids)
+	response := &iot.ListDeviceByTagsResponse{Resources: []iot.ResourceDTO{}, Page: page}
+	for _, id := range ids {
+		response.Resources = append(response.Resources, iot.ResourceDTO{ResourceID: id})
+	}
+
+	return http.StatusOK, response
+}
+
+func hasTags(tags, expected []iot.TagV5DTO) bool {
+	for _, e := range expected {
+		found := false
+		for _, tag := range tags {
+			if tag.TagKey == e.TagKey && (len(e.TagValue) == 0 || tag.TagValue == e.TagValue) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AMQP队列和接入凭证
+
+func listAmqpQueues(st *state, c *call) (int, interface{}) {
+	var ids []string
+	for _, id := range st.queueOrder {
+		if name := c.query.Get("queue_name"); len(name) != 0 && st.queues[id].QueueName != name {
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	ids, page := paginate(c, ids)
+	response := &iot.ListAmqpQueuesResponse{Queues: []iot.QueryQueueBase{}, Page: page}
+	for _, id := range ids {
+		response.Queues = append(response.Queues, *st.queues[id])
+	}
+
+	return http.StatusOK, response
+}
+
+func createAmqpQueue(st *state, c *call) (int, interface{}) {
+	request := &struct {
+		QueueName string `json:"queue_name"`
+	}{}
+	if err := c.decode(request); err != nil {
+		return http.StatusBadRequest, err
+	}
+	if len(request.QueueName) == 0 {
+		return invalidInput("queue_name is required")
+	}
+
+	for _, queue := range st.queues {
+		if queue.QueueName == request.QueueName {
+			return http.StatusConflict, newError(ErrorCodeConflict, "queue "+request.QueueName+" already exists")
+		}
+	}
+
+	queue := &iot.QueryQueueBase{
+		QueueID:        st.newId(),
+		QueueName:      request.QueueName,
+		CreateTime:     now(),
+		LastModifyTime: now(),
+	}
+	st.queues[queue.QueueID] = queue
+	st.queueOrder = append(st.queueOrder, queue.QueueID)
+
+	return http.StatusCreated, &iot.CreateAmqpQueueResponse{
+		QueueID:        queue.QueueID,
+		QueueName:      queue.QueueName,
+		CreateTime:     queue.CreateTime,
+		LastModifyTime: queue.LastModifyTime,
+	}
+}
+
+func showAmqpQueue(st *state, c *call) (int, interface{}) {
+	queue, ok := st.queues[c.params["queue_id"]]
+	if !ok {
+		return notFound("queue", c.params["queue_id"])
+	}
+
+	return http.StatusOK, &iot.ShowAmqpQueueResponse{
+		QueueID:        queue.QueueID,
+		QueueName:      queue.QueueName,
+		CreateTime:     queue.CreateTime,
+		LastModifyTime: queue.LastModifyTime,
+	}
+}
+
+func deleteAmqpQueue(st *state, c *call) (int, interface{}) {
+	queueId := c.params["queue_id"]
+	if _, ok := st.queues[queueId]; !ok {
+		return notFound("queue", queueId)
+	}
+
+	delete(st.queues, queueId)
+	st.queueOrder = removeId(st.queueOrder, queueId)
+
+	return http.StatusNoContent, nil
+}
+
+func createAccessCode(st *state, c *call) (int, interface{}) {
+	return http.StatusCreated, &iot.CreateAccessCodeResponse{
+		AccessKey:  randomHex(4),
+		AccessCode: randomHex(16),
+	}
+}
+
+// 设备CA证书
+
+func listDeviceCertificates(st *state, c *call) (int, interface{}) {
+	ids, page := paginate(c, st.certificateOrder)
+	response := &iot.ListDeviceCertificatesResponse{Certificates: []iot.CertificatesRspDTO{}, Page: page}
+	for _, id := range ids {
+		response.Certificates = append(response.Certificates, *st.certificates[id])
+	}
+
+	return http.StatusOK, response
+}
+
+// 证书内容必须是PEM格式的X.509证书
+func uploadDeviceCertificates(st *state, c *call) (int, interface{}) {
+	request := &iot.UploadDeviceCertificatesRequest{}
+	if err := c.decode(request); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	block, _ := pem.Decode([]byte(request.Content))
+	if block == nil {
+		return invalidInput("content is not a PEM encoded certificate")
+	}
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return invalidInput("parse certificate failed: " + err.Error())
+	}
+
+	dto := &iot.CertificatesRspDTO{
+		CertificateID: st.newId(),
+		CnName:        certificate.Subject.CommonName,
+		Owner:         certificate.Issuer.CommonName,
+		Status:        false,
+		VerifyCode:    randomHex(16),
+		CreateDate:    now(),
+		EffectiveDate: certificate.NotBefore.UTC().Format(timeFormat),
+		ExpiryDate:    certificate.NotAfter.UTC().Format(timeFormat),
+	}
+	st.certificates[dto.CertificateID] = dto
+	st.certificateOrder = append(st.certificateOrder, dto.CertificateID)
+
+	response := iot.UploadDeviceCertificatesResponse(*dto)
+	return http.StatusOK, &response
+}
+
+func deleteDeviceCertificates(st *state, c *call) (int, interface{}) {
+	certificateId := c.params["certificate_id"]
+	if _, ok := st.certificates[certificateId]; !ok {
+		return notFound("certificate", certificateId)
+	}
+
+	delete(st.certificates, certificateId)
+	st.certificateOrder = removeId(st.certificateOrder, certificateId)
+
+	return http.StatusNoContent, nil
+}
+
+// 只校验验证证书不为空，不校验验证证书的签名
+func verifyDeviceCertificates(st *state, c *call) (int, interface{}) {
+	certificate, ok := st.certificates[c.params["certificate_id"]]
+	if !ok {
+		return notFound("certificate", c.params["certificate_id"])
+	}
+	if c.query.Get("action_id") != "verify" {
+		return invalidInput("unsupported action_id " + c.query.Get("action_id"))
+	}
+
+	request := &struct {
+		VerifyContent string `json:"verify_content"`
+	}{}
+	if err := c.decode(request); err != nil {
+		return http.StatusBadRequest, err
+	}
+	if len(request.VerifyContent) == 0 {
+		return invalidInput("verify_content is required")
+	}
+
+	certificate.Status = true
+	return http.StatusOK, nil
+}
+
+// 资源空间
+
+func listApplications(st *state, c *call) (int, interface{}) {
+	response := &iot.Applications{Applications: []iot.Application{}}
+	for _, id := range st.appOrder {
+		response.Applications = append(response.Applications, *st.apps[id])
+	}
+
+	return http.StatusOK, response
+}
+
+func createApplication(st *state, c *call) (int, interface{}) {
+	request := &iot.ApplicationCreateRequest{}
+	if err := c.decode(request); err != nil {
+		return http.StatusBadRequest, err
+	}
+	if len(request.AppName) == 0 {
+		return invalidInput("app_name is required")
+	}
+
+	app := &iot.Application{
+		AppId:      st.newId(),
+		AppName:    request.AppName,
+		CreateTime: now(),
+	}
+	st.apps[app.AppId] = app
+	st.appOrder = append(st.appOrder, app.AppId)
+
+	return http.StatusCreated, app
+}
+
+func showApplication(st *state, c *call) (int, interface{}) {
+	app, ok := st.apps[c.params["app_id"]]
+	if !ok {
+		return notFound("application", c.params["app_id"])
+	}
+
+	return http.StatusOK, app
+}
+
+// 默认资源空间和仍然有设备的资源空间不能删除
+func deleteApplication(st *state, c *call) (int, interface{}) {
+	appId := c.params["app_id"]
+	app, ok := st.apps[appId]
+	if !ok {
+		return notFound("application", appId)
+	}
+	if app.DefaultApp {
+		return http.StatusConflict, newError(ErrorCodeConflict, "default application can not be deleted")
+	}
+	for _, d := range st.devices {
+		if d.detail.AppID == appId {
+			return http.StatusConflict, newError(ErrorCodeConflict, "application "+appId+" has devices")
+		}
+	}
+
+	delete(st.apps, appId)
+	st.appOrder = removeId(st.appOrder, appId)
+
+	return http.StatusNoContent, nil
+}
@@ -0,0 +1,606 @@
+package iot
+
+import (
+	"context"
+	"strconv"
+)
+
+// 迭代器选项，PageSize为每页查询的数量，默认50；MaxItems为最多返回的记录数，为0时不限制
+type IteratorOptions struct {
+	PageSize int
+	MaxItems int
+}
+
+func (o IteratorOptions) pageSize() int {
+	if o.PageSize >= 1 && o.PageSize <= maxPageLimit {
+		return o.PageSize
+	}
+
+	return maxPageLimit
+}
+
+// 查询marker之后的一页数据，返回当前页的数据和下一页的marker
+type pageFetcher func(ctx context.Context, marker string, limit int) ([]interface{}, string, error)
+
+// 基于marker的分页迭代器，依次查询每一页直到没有更多数据或者达到MaxItems
+type pageIterator struct {
+	ctx     context.Context
+	fetch   pageFetcher
+	options IteratorOptions
+
+	items  []interface{}
+	item   interface{}
+	marker string
+	count  int
+	last   bool
+	err    error
+}
+
+func newPageIterator(ctx context.Context, options IteratorOptions, fetch pageFetcher) *pageIterator {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return &pageIterator{
+		ctx:     ctx,
+		fetch:   fetch,
+		options: options,
+	}
+}
+
+func (it *pageIterator) next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	if it.options.MaxItems > 0 && it.count >= it.options.MaxItems {
+		return false
+	}
+
+	for len(it.items) == 0 {
+		if it.last {
+			return false
+		}
+
+		if err := it.ctx.Err(); err != nil {
+			it.err = err
+			return false
+		}
+
+		limit := it.options.pageSize()
+		items, marker, err := it.fetch(it.ctx, it.marker, limit)
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		// 没有下一页的marker、marker没有变化或者当前页不满时表示已经是最后一页
+		if len(items) < limit || len(marker) == 0 || marker == it.marker {
+			it.last = true
+		}
+		it.marker = marker
+		it.items = items
+	}
+
+	it.item = it.items[0]
+	it.items = it.items[1:]
+	it.count++
+	return true
+}
+
+func (it *pageIterator) forEach(fn func(item interface{}) error) error {
+	for it.next() {
+		if err := fn(it.item); err != nil {
+			return err
+		}
+	}
+
+	return it.err
+}
+
+func copyQueryParas(queryParas map[string]string) map[string]string {
+	paras := make(map[string]string, len(queryParas)+2)
+	for k, v := range queryParas {
+		paras[k] = v
+	}
+	delete(paras, "offset")
+
+	return paras
+}
+
+// ProductIterator 遍历所有产品
+type ProductIterator struct {
+	it *pageIterator
+}
+
+func NewProductIterator(ctx context.Context, client ApplicationClient, request ListProductsRequest, options IteratorOptions) *ProductIterator {
+	fetch := func(ctx context.Context, marker string, limit int) ([]interface{}, string, error) {
+		request.Marker = marker
+		request.Limit = limit
+		request.Offset = 0
+		response, err := client.ListProductsCtx(ctx, request)
+		if err != nil {
+			return nil, "", err
+		}
+
+		items := make([]interface{}, len(response.Products))
+		for i := range response.Products {
+			items[i] = response.Products[i]
+		}
+
+		return items, response.Page.Marker, nil
+	}
+
+	return &ProductIterator{it: newPageIterator(ctx, options, fetch)}
+}
+
+func (i *ProductIterator) Next() bool {
+	return i.it.next()
+}
+
+func (i *ProductIterator) Item() ProductSummary {
+	item, _ := i.it.item.(ProductSummary)
+	return item
+}
+
+func (i *ProductIterator) Err() error {
+	return i.it.err
+}
+
+func (i *ProductIterator) ForEach(fn func(item ProductSummary) error) error {
+	return i.it.forEach(func(item interface{}) error {
+		return fn(item.(ProductSummary))
+	})
+}
+
+// DeviceIterator 遍历所有设备
+type DeviceIterator struct {
+	it *pageIterator
+}
+
+func NewDeviceIterator(ctx context.Context, client ApplicationClient, queryParas map[string]string, options IteratorOptions) *DeviceIterator {
+	fetch := func(ctx context.Context, marker string, limit int) ([]interface{}, string, error) {
+		paras := copyQueryParas(queryParas)
+		paras["limit"] = strconv.Itoa(limit)
+		if len(marker) != 0 {
+			paras["marker"] = marker
+		}
+		response, err := client.ListDevicesCtx(ctx, paras)
+		if err != nil {
+			return nil, "", err
+		}
+
+		items := make([]interface{}, len(response.Devices))
+		for i := range response.Devices {
+			items[i] = response.Devices[i]
+		}
+
+		return items, response.Page.Marker, nil
+	}
+
+	return &DeviceIterator{it: newPageIterator(ctx, options, fetch)}
+}
+
+func (i *DeviceIterator) Next() bool {
+	return i.it.next()
+}
+
+func (i *DeviceIterator) Item() QueryDeviceSimplify {
+	item, _ := i.it.item.(QueryDeviceSimplify)
+	return item
+}
+
+func (i *DeviceIterator) Err() error {
+	return i.it.err
+}
+
+func (i *DeviceIterator) ForEach(fn func(item QueryDeviceSimplify) error) error {
+	return i.it.forEach(func(item interface{}) error {
+		return fn(item.(QueryDeviceSimplify))
+	})
+}
+
+// DeviceAsyncCommandIterator 遍历所有设备异步命令
+type DeviceAsyncCommandIterator struct {
+	it *pageIterator
+}
+
+func NewDeviceAsyncCommandIterator(ctx context.Context, client ApplicationClient, deviceId string, request ListDeviceAsyncCommandsRequest, options IteratorOptions) *DeviceAsyncCommandIterator {
+	fetch := func(ctx context.Context, marker string, limit int) ([]interface{}, string, error) {
+		request.Marker = marker
+		request.Limit = limit
+		request.Offset = 0
+		response, err := client.ListDeviceAsyncCommandsCtx(ctx, deviceId, request)
+		if err != nil {
+			return nil, "", err
+		}
+
+		items := make([]interface{}, len(response.Commands))
+		for i := range response.Commands {
+			items[i] = response.Commands[i]
+		}
+
+		return items, response.Page.Marker, nil
+	}
+
+	return &DeviceAsyncCommandIterator{it: newPageIterator(ctx, options, fetch)}
+}
+
+func (i *DeviceAsyncCommandIterator) Next() bool {
+	return i.it.next()
+}
+
+func (i *DeviceAsyncCommandIterator) Item() DeviceAsyncCommand {
+	item, _ := i.it.item.(DeviceAsyncCommand)
+	return item
+}
+
+func (i *DeviceAsyncCommandIterator) Err() error {
+	return i.it.err
+}
+
+func (i *DeviceAsyncCommandIterator) ForEach(fn func(item DeviceAsyncCommand) error) error {
+	return i.it.forEach(func(item interface{}) error {
+		return fn(item.(DeviceAsyncCommand))
+	})
+}
+
+// AmqpQueueIterator 遍历所有AMQP队列
+type AmqpQueueIterator struct {
+	it *pageIterator
+}
+
+func NewAmqpQueueIterator(ctx context.Context, client ApplicationClient, request ListAmqpQueuesRequest, options IteratorOptions) *AmqpQueueIterator {
+	fetch := func(ctx context.Context, marker string, limit int) ([]interface{}, string, error) {
+		request.Marker = marker
+		request.Limit = limit
+		request.Offset = ""
+		response, err := client.ListAmqpQueuesCtx(ctx, request)
+		if err != nil {
+			return nil, "", err
+		}
+
+		items := make([]interface{}, len(response.Queues))
+		for i := range response.Queues {
+			items[i] = response.Queues[i]
+		}
+
+		return items, response.Page.Marker, nil
+	}
+
+	return &AmqpQueueIterator{it: newPageIterator(ctx, options, fetch)}
+}
+
+func (i *AmqpQueueIterator) Next() bool {
+	return i.it.next()
+}
+
+func (i *AmqpQueueIterator) Item() QueryQueueBase {
+	item, _ := i.it.item.(QueryQueueBase)
+	return item
+}
+
+func (i *AmqpQueueIterator) Err() error {
+	return i.it.err
+}
+
+func (i *AmqpQueueIterator) ForEach(fn func(item QueryQueueBase) error) error {
+	return i.it.forEach(func(item interface{}) error {
+		return fn(item.(QueryQueueBase))
+	})
+}
+
+// RoutingRuleIterator 遍历所有数据流转规则
+type RoutingRuleIterator struct {
+	it *pageIterator
+}
+
+func NewRoutingRuleIterator(ctx context.Context, client ApplicationClient, request ListRoutingRulesRequest, options IteratorOptions) *RoutingRuleIterator {
+	fetch := func(ctx context.Context, marker string, limit int) ([]interface{}, string, error) {
+		request.Marker = marker
+		request.Limit = limit
+		request.Offset = 0
+		response, err := client.ListRoutingRulesCtx(ctx, request)
+		if err != nil {
+			return nil, "", err
+		}
+
+		items := make([]interface{}, len(response.Rules))
+		for i := range response.Rules {
+			items[i] = response.Rules[i]
+		}
+
+		return items, response.Marker, nil
+	}
+
+	return &RoutingRuleIterator{it: newPageIterator(ctx, options, fetch)}
+}
+
+func (i *RoutingRuleIterator) Next() bool {
+	return i.it.next()
+}
+
+func (i *RoutingRuleIterator) Item() RoutingRuleResponse {
+	item, _ := i.it.item.(RoutingRuleResponse)
+	return item
+}
+
+func (i *RoutingRuleIterator) Err() error {
+	return i.it.err
+}
+
+func (i *RoutingRuleIterator) ForEach(fn func(item RoutingRuleResponse) error) error {
+	return i.it.forEach(func(item interface{}) error {
+		return fn(item.(RoutingRuleResponse))
+	})
+}
+
+// RuleActionIterator 遍历所有数据流转规则动作
+type RuleActionIterator struct {
+	it *pageIterator
+}
+
+func NewRuleActionIterator(ctx context.Context, client ApplicationClient, request ListRuleActionsRequest, options IteratorOptions) *RuleActionIterator {
+	fetch := func(ctx context.Context, marker string, limit int) ([]interface{}, string, error) {
+		request.Marker = marker
+		request.Limit = limit
+		request.Offset = 0
+		response, err := client.ListRuleActionsCtx(ctx, request)
+		if err != nil {
+			return nil, "", err
+		}
+
+		items := make([]interface{}, len(response.Actions))
+		for i := range response.Actions {
+			items[i] = response.Actions[i]
+		}
+
+		return items, response.Marker, nil
+	}
+
+	return &RuleActionIterator{it: newPageIterator(ctx, options, fetch)}
+}
+
+func (i *RuleActionIterator) Next() bool {
+	return i.it.next()
+}
+
+func (i *RuleActionIterator) Item() RuleActionResponse {
+	item, _ := i.it.item.(RuleActionResponse)
+	return item
+}
+
+func (i *RuleActionIterator) Err() error {
+	return i.it.err
+}
+
+func (i *RuleActionIterator) ForEach(fn func(item RuleActionResponse) error) error {
+	return i.it.forEach(func(item interface{}) error {
+		return fn(item.(RuleActionResponse))
+	})
+}
+
+// DeviceGroupIterator 遍历所有设备组
+type DeviceGroupIterator struct {
+	it *pageIterator
+}
+
+func NewDeviceGroupIterator(ctx context.Context, client ApplicationClient, request ListDeviceGroupRequest, options IteratorOptions) *DeviceGroupIterator {
+	fetch := func(ctx context.Context, marker string, limit int) ([]interface{}, string, error) {
+		request.Marker = marker
+		request.Limit = limit
+		request.Offset = 0
+		response, err := client.ListDeviceGroupsCtx(ctx, request)
+		if err != nil {
+			return nil, "", err
+		}
+
+		items := make([]interface{}, len(response.DeviceGroups))
+		for i := range response.DeviceGroups {
+			items[i] = response.DeviceGroups[i]
+		}
+
+		return items, response.Page.Marker, nil
+	}
+
+	return &DeviceGroupIterator{it: newPageIterator(ctx, options, fetch)}
+}
+
+func (i *DeviceGroupIterator) Next() bool {
+	return i.it.next()
+}
+
+func (i *DeviceGroupIterator) Item() DeviceGroupResponseDTO {
+	item, _ := i.it.item.(DeviceGroupResponseDTO)
+	return item
+}
+
+func (i *DeviceGroupIterator) Err() error {
+	return i.it.err
+}
+
+func (i *DeviceGroupIterator) ForEach(fn func(item DeviceGroupResponseDTO) error) error {
+	return i.it.forEach(func(item interface{}) error {
+		return fn(item.(DeviceGroupResponseDTO))
+	})
+}
+
+// DeviceInDeviceGroupIterator 遍历所有设备组中的设备
+type DeviceInDeviceGroupIterator struct {
+	it *pageIterator
+}
+
+func NewDeviceInDeviceGroupIterator(ctx context.Context, client ApplicationClient, deviceGroupId string, request ListDeviceInDeviceGroupRequest, options IteratorOptions) *DeviceInDeviceGroupIterator {
+	fetch := func(ctx context.Context, marker string, limit int) ([]interface{}, string, error) {
+		request.Marker = marker
+		request.Limit = limit
+		request.Offset = 0
+		response, err := client.ListDeviceInDeviceGroupCtx(ctx, deviceGroupId, request)
+		if err != nil {
+			return nil, "", err
+		}
+
+		items := make([]interface{}, len(response.Devices))
+		for i := range response.Devices {
+			items[i] = response.Devices[i]
+		}
+
+		return items, response.Page.Marker, nil
+	}
+
+	return &DeviceInDeviceGroupIterator{it: newPageIterator(ctx, options, fetch)}
+}
+
+func (i *DeviceInDeviceGroupIterator) Next() bool {
+	return i.it.next()
+}
+
+func (i *DeviceInDeviceGroupIterator) Item() SimplifyDevice {
+	item, _ := i.it.item.(SimplifyDevice)
+	return item
+}
+
+func (i *DeviceInDeviceGroupIterator) Err() error {
+	return i.it.err
+}
+
+func (i *DeviceInDeviceGroupIterator) ForEach(fn func(item SimplifyDevice) error) error {
+	return i.it.forEach(func(item interface{}) error {
+		return fn(item.(SimplifyDevice))
+	})
+}
+
+// DeviceByTagsIterator 遍历所有绑定了指定标签的设备
+type DeviceByTagsIterator struct {
+	it *pageIterator
+}
+
+func NewDeviceByTagsIterator(ctx context.Context, client ApplicationClient, request ListDeviceByTagsRequest, options IteratorOptions) *DeviceByTagsIterator {
+	fetch := func(ctx context.Context, marker string, limit int) ([]interface{}, string, error) {
+		request.Marker = marker
+		request.Limit = limit
+		request.Offset = 0
+		response, err := client.ListDeviceByTagsCtx(ctx, request)
+		if err != nil {
+			return nil, "", err
+		}
+
+		items := make([]interface{}, len(response.Resources))
+		for i := range response.Resources {
+			items[i] = response.Resources[i]
+		}
+
+		return items, response.Page.Marker, nil
+	}
+
+	return &DeviceByTagsIterator{it: newPageIterator(ctx, options, fetch)}
+}
+
+func (i *DeviceByTagsIterator) Next() bool {
+	return i.it.next()
+}
+
+func (i *DeviceByTagsIterator) Item() ResourceDTO {
+	item, _ := i.it.item.(ResourceDTO)
+	return item
+}
+
+func (i *DeviceByTagsIterator) Err() error {
+	return i.it.err
+}
+
+func (i *DeviceByTagsIterator) ForEach(fn func(item ResourceDTO) error) error {
+	return i.it.forEach(func(item interface{}) error {
+		return fn(item.(ResourceDTO))
+	})
+}
+
+// BatchTaskIterator 遍历所有批量任务
+type BatchTaskIterator struct {
+	it *pageIterator
+}
+
+func NewBatchTaskIterator(ctx context.Context, client ApplicationClient, request ListBatchTasksRequest, options IteratorOptions) *BatchTaskIterator {
+	fetch := func(ctx context.Context, marker string, limit int) ([]interface{}, string, error) {
+		request.Marker = marker
+		request.Limit = limit
+		request.Offset = 0
+		response, err := client.ListBatchTasksCtx(ctx, request)
+		if err != nil {
+			return nil, "", err
+		}
+
+		items := make([]interface{}, len(response.BatchTasks))
+		for i := range response.BatchTasks {
+			items[i] = response.BatchTasks[i]
+		}
+
+		return items, response.Page.Marker, nil
+	}
+
+	return &BatchTaskIterator{it: newPageIterator(ctx, options, fetch)}
+}
+
+func (i *BatchTaskIterator) Next() bool {
+	return i.it.next()
+}
+
+func (i *BatchTaskIterator) Item() BatchTask {
+	item, _ := i.it.item.(BatchTask)
+	return item
+}
+
+func (i *BatchTaskIterator) Err() error {
+	return i.it.err
+}
+
+func (i *BatchTaskIterator) ForEach(fn func(item BatchTask) error) error {
+	return i.it.forEach(func(item interface{}) error {
+		return fn(item.(BatchTask))
+	})
+}
+
+// DeviceCertificateIterator 遍历所有设备CA证书
+type DeviceCertificateIterator struct {
+	it *pageIterator
+}
+
+func NewDeviceCertificateIterator(ctx context.Context, client ApplicationClient, request ListDeviceCertificatesRequest, options IteratorOptions) *DeviceCertificateIterator {
+	fetch := func(ctx context.Context, marker string, limit int) ([]interface{}, string, error) {
+		request.Marker = marker
+		request.Limit = limit
+		request.Offset = 0
+		response, err := client.ListDeviceCertificatesCtx(ctx, request)
+		if err != nil {
+			return nil, "", err
+		}
+
+		items := make([]interface{}, len(response.Certificates))
+		for i := range response.Certificates {
+			items[i] = response.Certificates[i]
+		}
+
+		return items, response.Page.Marker, nil
+	}
+
+	return &DeviceCertificateIterator{it: newPageIterator(ctx, options, fetch)}
+}
+
+func (i *DeviceCertificateIterator) Next() bool {
+	return i.it.next()
+}
+
+func (i *DeviceCertificateIterator) Item() CertificatesRspDTO {
+	item, _ := i.it.item.(CertificatesRspDTO)
+	return item
+}
+
+func (i *DeviceCertificateIterator) Err() error {
+	return i.it.err
+}
+
+func (i *DeviceCertificateIterator) ForEach(fn func(item CertificatesRspDTO) error) error {
+	return i.it.forEach(func(item interface{}) error {
+		return fn(item.(CertificatesRspDTO))
+	})
+}
@@ -0,0 +1,255 @@
+package iot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// 模拟基于marker的分页接口，marker为上一页最后一条记录
+type pageStub struct {
+	ids []string
+	// 返回固定的marker，模拟marker不变的异常响应
+	fixedMarker string
+	// 第几次查询返回错误，从1开始
+	failAt int
+	err    error
+
+	requests []pageStubRequest
+}
+
+type pageStubRequest struct {
+	marker string
+	limit  int
+}
+
+func newPageStub(count int) *pageStub {
+	stub := &pageStub{}
+	for i := 0; i < count; i++ {
+		stub.ids = append(stub.ids, fmt.Sprintf("id-%02d", i))
+	}
+
+	return stub
+}
+
+func (s *pageStub) fetch(ctx context.Context, marker string, limit int) ([]interface{}, string, error) {
+	s.requests = append(s.requests, pageStubRequest{marker: marker, limit: limit})
+	if s.failAt == len(s.requests) {
+		return nil, "", s.err
+	}
+
+	start := 0
+	for i, id := range s.ids {
+		if id == marker {
+			start = i + 1
+		}
+	}
+	end := start + limit
+	if end > len(s.ids) {
+		end = len(s.ids)
+	}
+
+	items := make([]interface{}, 0, end-start)
+	for _, id := range s.ids[start:end] {
+		items = append(items, id)
+	}
+
+	next := ""
+	if len(items) != 0 {
+		next = s.ids[end-1]
+	}
+	if len(s.fixedMarker) != 0 {
+		next = s.fixedMarker
+	}
+
+	return items, next, nil
+}
+
+func collectPage(it *pageIterator) []string {
+	var ids []string
+	for it.next() {
+		ids = append(ids, it.item.(string))
+	}
+
+	return ids
+}
+
+func TestPageIteratorFollowsMarker(t *testing.T) {
+	stub := newPageStub(7)
+	it := newPageIterator(context.Background(), IteratorOptions{PageSize: 3}, stub.fetch)
+
+	ids := collectPage(it)
+	if fmt.Sprint(ids) != fmt.Sprint(stub.ids) || it.err != nil {
+		t.Errorf("items = %v, %v, want %v", ids, it.err, stub.ids)
+	}
+
+	// 最后一页不满时不再查询
+	expected := []pageStubRequest{{"", 3}, {"id-02", 3}, {"id-05", 3}}
+	if fmt.Sprint(stub.requests) != fmt.Sprint(expected) {
+		t.Errorf("requests = %v, want %v", stub.requests, expected)
+	}
+	if it.next() || len(stub.requests) != 3 {
+		t.Error("next() after the last page fetches again")
+	}
+}
+
+func TestPageIteratorFullLastPage(t *testing.T) {
+	stub := newPageStub(6)
+	it := newPageIterator(context.Background(), IteratorOptions{PageSize: 3}, stub.fetch)
+
+	// 最后一页刚好满时需要多查询一次空页
+	if ids := collectPage(it); len(ids) != 6 || it.err != nil {
+		t.Errorf("items = %v, %v", ids, it.err)
+	}
+	if len(stub.requests) != 3 || stub.requests[2].marker != "id-05" {
+		t.Errorf("requests = %v", stub.requests)
+	}
+}
+
+func TestPageIteratorPageSize(t *testing.T) {
+	for _, tt := range []struct {
+		pageSize int
+		limit    int
+	}{
+		{0, maxPageLimit},
+		{-1, maxPageLimit},
+		{20, 20},
+		{maxPageLimit + 1, maxPageLimit},
+	} {
+		stub := newPageStub(1)
+		collectPage(newPageIterator(context.Background(), IteratorOptions{PageSize: tt.pageSize}, stub.fetch))
+		if stub.requests[0].limit != tt.limit {
+			t.Errorf("PageSize %d: limit = %d, want %d", tt.pageSize, stub.requests[0].limit, tt.limit)
+		}
+	}
+}
+
+func TestPageIteratorMaxItems(t *testing.T) {
+	stub := newPageStub(20)
+	it := newPageIterator(context.Background(), IteratorOptions{PageSize: 3, MaxItems: 5}, stub.fetch)
+
+	ids := collectPage(it)
+	if fmt.Sprint(ids) != fmt.Sprint(stub.ids[:5]) || it.err != nil {
+		t.Errorf("items = %v, %v, want %v", ids, it.err, stub.ids[:5])
+	}
+	if len(stub.requests) != 2 {
+		t.Errorf("requests = %v, want 2 pages", stub.requests)
+	}
+}
+
+func TestPageIteratorUnchangedMarker(t *testing.T) {
+	stub := newPageStub(10)
+	stub.fixedMarker = "id-02"
+	it := newPageIterator(context.Background(), IteratorOptions{PageSize: 3}, stub.fetch)
+
+	// 第二页返回的marker与请求的marker相同，不能无限循环
+	ids := collectPage(it)
+	if len(ids) != 6 || len(stub.requests) != 2 || it.err != nil {
+		t.Errorf("items = %v, %v after requests %v", ids, it.err, stub.requests)
+	}
+
+	// 满页但是没有返回marker时也结束
+	stub = newPageStub(10)
+	it = newPageIterator(context.Background(), IteratorOptions{PageSize: 3}, func(ctx context.Context, marker string, limit int) ([]interface{}, string, error) {
+		items, _, err := stub.fetch(ctx, marker, limit)
+		return items, "", err
+	})
+	if ids := collectPage(it); len(ids) != 3 || len(stub.requests) != 1 {
+		t.Errorf("items without marker = %v after requests %v", ids, stub.requests)
+	}
+}
+
+func TestPageIteratorError(t *testing.T) {
+	stub := newPageStub(10)
+	stub.failAt = 2
+	stub.err = &ApplicationError{ErrorCode: "APIGW.0308", StatusCode: http.StatusTooManyRequests}
+	it := newPageIterator(context.Background(), IteratorOptions{PageSize: 3}, stub.fetch)
+
+	ids := collectPage(it)
+	if len(ids) != 3 || it.err != stub.err {
+		t.Errorf("items = %v, %v, want 3 items and the fetch error", ids, it.err)
+	}
+	if it.next() || len(stub.requests) != 2 {
+		t.Error("next() after an error fetches again")
+	}
+
+	// ForEach返回回调的错误，并停止遍历
+	stopErr := errors.New("stop")
+	count := 0
+	err := newPageIterator(context.Background(), IteratorOptions{PageSize: 3}, newPageStub(10).fetch).forEach(func(item interface{}) error {
+		count++
+		if count == 4 {
+			return stopErr
+		}
+		return nil
+	})
+	if err != stopErr || count != 4 {
+		t.Errorf("forEach() = %v after %d items", err, count)
+	}
+}
+
+func TestPageIteratorCanceled(t *testing.T) {
+	stub := newPageStub(10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	it := newPageIterator(ctx, IteratorOptions{PageSize: 3}, stub.fetch)
+
+	var ids []string
+	for it.next() {
+		ids = append(ids, it.item.(string))
+		if len(ids) == 2 {
+			cancel()
+		}
+	}
+
+	// 当前页的数据遍历完之后才检查ctx
+	if len(ids) != 3 || !errors.Is(it.err, context.Canceled) || len(stub.requests) != 1 {
+		t.Errorf("items = %v, %v after requests %v", ids, it.err, stub.requests)
+	}
+}
+
+func TestDeviceIterator(t *testing.T) {
+	var lock sync.Mutex
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		queries = append(queries, r.URL.RawQuery)
+		lock.Unlock()
+
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		start := 0
+		if marker := r.URL.Query().Get("marker"); len(marker) != 0 {
+			start, _ = strconv.Atoi(marker)
+		}
+		var devices []string
+		for i := start; i < start+limit && i < 5; i++ {
+			devices = append(devices, fmt.Sprintf(`{"device_id":"device-%d"}`, i))
+		}
+		_, _ = fmt.Fprintf(w, `{"devices":[%s],"page":{"count":5,"marker":"%d"}}`, strings.Join(devices, ","), start+len(devices))
+	}))
+	defer server.Close()
+
+	client := CreateSyncIotApplicationClient(*NewApplicationOptions().WithEndpoint(server.URL).SetProjectId("project").SetToken("token"))
+	it := NewDeviceIterator(context.Background(), client, map[string]string{"product_id": "product", "offset": "3"}, IteratorOptions{PageSize: 2})
+
+	var ids []string
+	err := it.ForEach(func(device QueryDeviceSimplify) error {
+		ids = append(ids, device.DeviceID)
+		return nil
+	})
+	if err != nil || fmt.Sprint(ids) != "[device-0 device-1 device-2 device-3 device-4]" {
+		t.Errorf("ForEach() = %v, %v", ids, err)
+	}
+
+	// offset被忽略，使用marker翻页
+	expected := []string{"limit=2&product_id=product", "limit=2&marker=2&product_id=product", "limit=2&marker=4&product_id=product"}
+	if fmt.Sprint(queries) != fmt.Sprint(expected) {
+		t.Errorf("queries = %v, want %v", queries, expected)
+	}
+}
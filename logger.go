@@ -0,0 +1,238 @@
+package iot
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level 日志级别，取值与log/slog一致
+type Level int
+
+const (
+	LevelDebug Level = -4
+	LevelInfo  Level = 0
+	LevelWarn  Level = 4
+	LevelError Level = 8
+)
+
+func (l Level) String() string {
+	switch {
+	case l < LevelInfo:
+		return "DEBUG"
+	case l < LevelWarn:
+		return "INFO"
+	case l < LevelError:
+		return "WARN"
+	default:
+		return "ERROR"
+	}
+}
+
+// Logger 方法与log/slog的*slog.Logger一致，*slog.Logger可以直接作为Logger使用。args为交替出现的key和value
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+type nopLogger struct {
+}
+
+// NopLogger 丢弃所有日志，没有设置Logger时使用
+func NopLogger() Logger {
+	return nopLogger{}
+}
+
+func (nopLogger) Debug(msg string, args ...interface{}) {}
+
+func (nopLogger) Info(msg string, args ...interface{}) {}
+
+func (nopLogger) Warn(msg string, args ...interface{}) {}
+
+func (nopLogger) Error(msg string, args ...interface{}) {}
+
+type textLogger struct {
+	lock  sync.Mutex
+	w     io.Writer
+	level Level
+}
+
+// NewTextLogger 以key=value格式向w输出不低于level的日志
+func NewTextLogger(w io.Writer, level Level) Logger {
+	return &textLogger{
+		w:     w,
+		level: level,
+	}
+}
+
+func (l *textLogger) Debug(msg string, args ...interface{}) {
+	l.log(LevelDebug, msg, args)
+}
+
+func (l *textLogger) Info(msg string, args ...interface{}) {
+	l.log(LevelInfo, msg, args)
+}
+
+func (l *textLogger) Warn(msg string, args ...interface{}) {
+	l.log(LevelWarn, msg, args)
+}
+
+func (l *textLogger) Error(msg string, args ...interface{}) {
+	l.log(LevelError, msg, args)
+}
+
+func (l *textLogger) log(level Level, msg string, args []interface{}) {
+	if level < l.level {
+		return
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString("time=" + time.Now().Format(time.RFC3339Nano))
+	buffer.WriteString(" level=" + level.String())
+	buffer.WriteString(" msg=" + quoteLogValue(msg))
+	for i := 0; i < len(args); i += 2 {
+		if i+1 == len(args) {
+			buffer.WriteString(" !BADKEY=" + quoteLogValue(fmt.Sprint(args[i])))
+			break
+		}
+		buffer.WriteString(" " + fmt.Sprint(args[i]) + "=" + quoteLogValue(fmt.Sprint(args[i+1])))
+	}
+	buffer.WriteByte('\n')
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	_, _ = l.w.Write(buffer.Bytes())
+}
+
+func quoteLogValue(value string) string {
+	if len(value) == 0 || strings.ContainsAny(value, " \t\r\n\"=") {
+		return strconv.Quote(value)
+	}
+
+	return value
+}
+
+const redactedValue = "******"
+
+// 值需要脱敏的key和消息头，不区分大小写，-和_等价
+var sensitiveKeys = map[string]bool{
+	"authorization":    true,
+	"x_auth_token":     true,
+	"x_security_token": true,
+	"x_subject_token":  true,
+	"token":            true,
+	"secret":           true,
+	"ak":               true,
+	"sk":               true,
+	"access_key":       true,
+	"password":         true,
+	"access_code":      true,
+	"security_token":   true,
+}
+
+func isSensitiveKey(key string) bool {
+	return sensitiveKeys[strings.ReplaceAll(strings.ToLower(key), "-", "_")]
+}
+
+var (
+	// JSON中的设备密钥、接入凭证、密码等字段，字段名不区分大小写
+	sensitiveFieldPattern = regexp.MustCompile(`(?i)"(secret|access_code|access_key|password|token|security_token|ak|sk)"(\s*:\s*)"(?:[^"\\]|\\.)*"`)
+	// 日志消息、错误信息、查询参数中的key=value
+	sensitiveParamPattern = regexp.MustCompile(`(?i)\b(secret|access[_-]code|access[_-]key|password|security[_-]token|token|ak|sk)(=)[^\s&,;"]+`)
+	// 日志消息中的消息头，例如X-Auth-Token: xxx
+	sensitiveHeaderPattern = regexp.MustCompile(`(?i)\b(x-auth-token|x-security-token|x-subject-token)(\s*[=:]\s*)[^\s&,;"]+`)
+	// 日志中的Authorization消息头，包括签名使用的AK
+	authorizationPattern = regexp.MustCompile(`(SDK-HMAC-SHA256\s+)[^\r\n"]+`)
+)
+
+// 对敏感信息脱敏的Logger，SDK内部统一通过它输出日志
+type redactingLogger struct {
+	logger Logger
+}
+
+func newRedactingLogger(logger Logger) Logger {
+	if logger == nil {
+		return NopLogger()
+	}
+
+	return &redactingLogger{
+		logger: logger,
+	}
+}
+
+func (l *redactingLogger) Debug(msg string, args ...interface{}) {
+	l.logger.Debug(redactText(msg), redactLogArgs(args)...)
+}
+
+func (l *redactingLogger) Info(msg string, args ...interface{}) {
+	l.logger.Info(redactText(msg), redactLogArgs(args)...)
+}
+
+func (l *redactingLogger) Warn(msg string, args ...interface{}) {
+	l.logger.Warn(redactText(msg), redactLogArgs(args)...)
+}
+
+func (l *redactingLogger) Error(msg string, args ...interface{}) {
+	l.logger.Error(redactText(msg), redactLogArgs(args)...)
+}
+
+func redactLogArgs(args []interface{}) []interface{} {
+	redacted := make([]interface{}, len(args))
+	copy(redacted, args)
+
+	for i := 0; i+1 < len(redacted); i += 2 {
+		key, ok := redacted[i].(string)
+		if !ok {
+			continue
+		}
+
+		if isSensitiveKey(key) {
+			redacted[i+1] = redactedValue
+			continue
+		}
+
+		switch value := redacted[i+1].(type) {
+		case http.Header:
+			redacted[i+1] = redactHeader(value)
+		case []byte:
+			redacted[i+1] = redactText(string(value))
+		case string:
+			redacted[i+1] = redactText(value)
+		case error:
+			// 错误信息中可能包含请求地址和响应体
+			if text := value.Error(); redactText(text) != text {
+				redacted[i+1] = redactText(text)
+			}
+		}
+	}
+
+	return redacted
+}
+
+func redactHeader(header http.Header) http.Header {
+	redacted := make(http.Header, len(header))
+	for key, values := range header {
+		if isSensitiveKey(key) {
+			redacted[key] = []string{redactedValue}
+		} else {
+			redacted[key] = values
+		}
+	}
+
+	return redacted
+}
+
+func redactText(text string) string {
+	text = sensitiveFieldPattern.ReplaceAllString(text, `"$1"$2"`+redactedValue+`"`)
+	text = authorizationPattern.ReplaceAllString(text, "${1}"+redactedValue)
+	text = sensitiveHeaderPattern.ReplaceAllString(text, "${1}${2}"+redactedValue)
+	return sensitiveParamPattern.ReplaceAllString(text, "${1}${2}"+redactedValue)
+}
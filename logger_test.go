@@ -0,0 +1,161 @@
+package iot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type logEntry struct {
+	msg  string
+	args []interface{}
+}
+
+type captureLogger struct {
+	lock    sync.Mutex
+	entries []logEntry
+}
+
+func (l *captureLogger) Debug(msg string, args ...interface{}) { l.add(msg, args) }
+
+func (l *captureLogger) Info(msg string, args ...interface{}) { l.add(msg, args) }
+
+func (l *captureLogger) Warn(msg string, args ...interface{}) { l.add(msg, args) }
+
+func (l *captureLogger) Error(msg string, args ...interface{}) { l.add(msg, args) }
+
+func (l *captureLogger) add(msg string, args []interface{}) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.entries = append(l.entries, logEntry{msg: msg, args: args})
+}
+
+func (l *captureLogger) count(msg string) int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	n := 0
+	for _, entry := range l.entries {
+		if entry.msg == msg {
+			n++
+		}
+	}
+
+	return n
+}
+
+func (l *captureLogger) String() string {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	var buffer bytes.Buffer
+	for _, entry := range l.entries {
+		fmt.Fprintln(&buffer, entry.msg, entry.args)
+	}
+
+	return buffer.String()
+}
+
+const (
+	secretAk         = "AKSECRETVALUE01"
+	secretSk         = "SKSECRETVALUE02"
+	secretToken      = "TOKENSECRETVALUE03"
+	secretDevice     = "DEVICESECRETVALUE04"
+	secretAccessCode = "ACCESSCODEVALUE05"
+)
+
+var secretValues = []string{secretAk, secretSk, secretToken, secretDevice, secretAccessCode}
+
+func TestRedactingLoggerAttributes(t *testing.T) {
+	capture := &captureLogger{}
+	logger := newRedactingLogger(capture)
+
+	logger.Info("attributes",
+		"ak", secretAk,
+		"SK", secretSk,
+		"X-Auth-Token", secretToken,
+		"secret", secretDevice,
+		"access-code", secretAccessCode,
+		"access_key", secretAk,
+		"device_id", "device-1")
+	logger.Info("header", "header", http.Header{
+		"Authorization":    {"SDK-HMAC-SHA256 Access=" + secretAk + ", SignedHeaders=host, Signature=abc"},
+		"X-Auth-Token":     {secretToken},
+		"X-Security-Token": {secretToken},
+		"Content-Type":     {"application/json"},
+	})
+	logger.Info("body",
+		"request_body", []byte(`{"device_name":"d","auth_info":{"secret":"`+secretDevice+`"}}`),
+		"response_body", `{"access_key":"`+secretAk+`","access_code" : "`+secretAccessCode+`"}`,
+		"body", `{"Secret":"`+secretDevice+`","PASSWORD":"`+secretAccessCode+`","Security_Token":"`+secretToken+`"}`,
+		"error", fmt.Errorf("request failed: %w", errors.New("token="+secretToken)))
+
+	output := capture.String()
+	for _, value := range secretValues {
+		if strings.Contains(output, value) {
+			t.Errorf("%s is not redacted:\n%s", value, output)
+		}
+	}
+	for _, value := range []string{"device-1", "application/json", `"device_name":"d"`, "request failed"} {
+		if !strings.Contains(output, value) {
+			t.Errorf("%s should not be redacted:\n%s", value, output)
+		}
+	}
+
+	// 原始参数不会被修改
+	header := capture.entries[1].args[1].(http.Header)
+	if header.Get("Content-Type") != "application/json" {
+		t.Errorf("header = %v", header)
+	}
+}
+
+func TestRedactingLoggerMessages(t *testing.T) {
+	capture := &captureLogger{}
+	logger := newRedactingLogger(capture)
+
+	logger.Warn("connect with ak=" + secretAk + "&sk=" + secretSk)
+	logger.Warn("X-Auth-Token: " + secretToken)
+	logger.Warn("Authorization: SDK-HMAC-SHA256 Access=" + secretAk + ", SignedHeaders=host, Signature=abc")
+	logger.Warn(`create device {"secret":"` + secretDevice + `"}`)
+	logger.Warn("amqp password=" + secretAccessCode + " token=" + secretToken)
+	logger.Warn(`reset secret {"Secret":"` + secretDevice + `","AK":"` + secretAk + `","Sk":"` + secretSk + `"}`)
+	logger.Warn(`connect {"Password":"` + secretAccessCode + `","Token" : "` + secretToken + `","Access_Key":"` + secretAk + `"}`)
+
+	output := capture.String()
+	for _, value := range secretValues {
+		if strings.Contains(output, value) {
+			t.Errorf("%s is not redacted:\n%s", value, output)
+		}
+	}
+	if !strings.Contains(output, "connect with ak=******&sk=******") {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+}
+
+func TestRedactingTextLogger(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := newRedactingLogger(NewTextLogger(&buffer, LevelInfo))
+
+	logger.Debug("dropped", "sk", secretSk)
+	logger.Info("request sk="+secretSk, "token", secretToken, "status_code", 200)
+
+	output := buffer.String()
+	if strings.Contains(output, "dropped") || strings.Contains(output, secretSk) || strings.Contains(output, secretToken) {
+		t.Errorf("unexpected output: %s", output)
+	}
+	if !strings.Contains(output, `msg="request sk=******"`) || !strings.Contains(output, "token=******") ||
+		!strings.Contains(output, "status_code=200") {
+		t.Errorf("unexpected output: %s", output)
+	}
+}
+
+func TestNewRedactingLoggerNil(t *testing.T) {
+	if _, ok := newRedactingLogger(nil).(nopLogger); !ok {
+		t.Error("newRedactingLogger(nil) should return NopLogger")
+	}
+}
@@ -0,0 +1,54 @@
+package iot
+
+import (
+	"context"
+	"time"
+)
+
+// MetricsRecorder 记录每一次API调用的指标，iotprometheus和iototel子包分别提供了Prometheus和OpenTelemetry的实现
+type MetricsRecorder interface {
+	RecordCall(ctx context.Context, call CallMetrics)
+}
+
+// CallMetrics 一次API调用（包括所有重试）的结果
+type CallMetrics struct {
+	Operation string
+	// 操作所属的API分组，与限流使用的分组相同
+	ApiGroup  ApiGroup
+	ProjectId string
+	// 最后一次响应的HTTP状态码，没有收到响应时为0
+	StatusCode int
+	// 平台返回的错误码，调用成功或者响应中没有错误码时为空
+	ErrorCode string
+	// 调用失败时的错误，成功时为nil
+	Err      error
+	Duration time.Duration
+	// 重试次数，不包括第一次请求
+	Retries int
+}
+
+func (client *syncClient) recordMetrics(ctx context.Context, op *operation, request *Request, response *Response, err error, duration time.Duration) {
+	if client.metrics == nil {
+		return
+	}
+
+	call := CallMetrics{
+		Operation: request.Operation,
+		ApiGroup:  op.group,
+		ProjectId: request.ProjectId,
+		Err:       err,
+		Duration:  duration,
+	}
+	if request.attempts > 1 {
+		call.Retries = request.attempts - 1
+	}
+	if response != nil {
+		call.StatusCode = response.StatusCode
+	}
+	if ae, ok := asApplicationError(err); ok {
+		call.StatusCode = ae.StatusCode
+		call.ErrorCode = ae.ErrorCode
+	}
+
+	client.metrics.RecordCall(ctx, call)
+}
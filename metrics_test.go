@@ -0,0 +1,106 @@
+package iot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type recordingMetricsRecorder struct {
+	lock  sync.Mutex
+	calls []CallMetrics
+}
+
+func (r *recordingMetricsRecorder) RecordCall(ctx context.Context, call CallMetrics) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.calls = append(r.calls, call)
+}
+
+func (r *recordingMetricsRecorder) last(t *testing.T) CallMetrics {
+	t.Helper()
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if len(r.calls) == 0 {
+		t.Fatal("no call is recorded")
+	}
+
+	return r.calls[len(r.calls)-1]
+}
+
+func TestRecordMetrics(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		switch {
+		case r.URL.Path == "/v5/iot/project/devices/retried" && n == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.URL.Path == "/v5/iot/project/devices/missing":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error_code":"IOTDA.014000","error_msg":"device not found"}`))
+		default:
+			time.Sleep(10 * time.Millisecond)
+			_, _ = w.Write([]byte(`{"device_id":"retried"}`))
+		}
+	}))
+	defer server.Close()
+
+	recorder := &recordingMetricsRecorder{}
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	client, err := NewClient(WithEndpoint(server.URL), WithProjectId("project"), WithToken("token"),
+		WithRetryPolicy(policy), WithMetricsRecorder(recorder))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 503之后重试成功
+	if _, err := client.ShowDevice("retried"); err != nil {
+		t.Fatal(err)
+	}
+	call := recorder.last(t)
+	if call.Operation != "ShowDevice" || call.ApiGroup != ApiGroupDevice || call.ProjectId != "project" ||
+		call.StatusCode != http.StatusOK || len(call.ErrorCode) != 0 || call.Err != nil || call.Retries != 1 {
+		t.Errorf("call = %+v", call)
+	}
+	if call.Duration < 10*time.Millisecond {
+		t.Errorf("duration = %v, want the latency of all attempts", call.Duration)
+	}
+
+	_, err = client.ShowDevice("missing")
+	call = recorder.last(t)
+	if call.StatusCode != http.StatusNotFound || call.ErrorCode != "IOTDA.014000" || call.Err != err || call.Retries != 0 {
+		t.Errorf("call = %+v", call)
+	}
+
+	if _, err := client.ListAmqpQueues(ListAmqpQueuesRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if call = recorder.last(t); call.Operation != "ListAmqpQueues" || call.ApiGroup != ApiGroupAmqp {
+		t.Errorf("call = %+v", call)
+	}
+}
+
+func TestRecordMetricsTransportError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	recorder := &recordingMetricsRecorder{}
+	client, err := NewClient(WithEndpoint(server.URL), WithProjectId("project"), WithToken("token"),
+		WithRetryPolicy(NoRetryPolicy()), WithMetricsRecorder(recorder))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.CreateDevice(CreateDeviceRequest{NodeID: "node", ProductID: "product"})
+	call := recorder.last(t)
+	if err == nil || call.Err != err || call.StatusCode != 0 || len(call.ErrorCode) != 0 || call.ApiGroup != ApiGroupDevice {
+		t.Errorf("call = %+v, error = %v", call, err)
+	}
+}
@@ -0,0 +1,325 @@
+package iot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-resty/resty/v2"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// operation 描述平台的一个API：名称、HTTP方法、路径、调用成功时平台返回的状态码以及流控分组
+type operation struct {
+	name         string
+	method       string
+	path         string
+	successCodes []int
+	group        ApiGroup
+}
+
+func (op *operation) success(statusCode int) bool {
+	for _, code := range op.successCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+// 产品管理
+var (
+	opListProducts  = &operation{"ListProducts", http.MethodGet, "/v5/iot/{project_id}/products", []int{http.StatusOK}, ApiGroupProduct}
+	opCreateProduct = &operation{"CreateProduct", http.MethodPost, "/v5/iot/{project_id}/products", []int{http.StatusCreated}, ApiGroupProduct}
+	opShowProduct   = &operation{"ShowProduct", http.MethodGet, "/v5/iot/{project_id}/products/{product_id}", []int{http.StatusOK}, ApiGroupProduct}
+	opUpdateProduct = &operation{"UpdateProduct", http.MethodPut, "/v5/iot/{project_id}/products/{product_id}", []int{http.StatusOK}, ApiGroupProduct}
+	opDeleteProduct = &operation{"DeleteProduct", http.MethodDelete, "/v5/iot/{project_id}/products/{product_id}", []int{http.StatusNoContent}, ApiGroupProduct}
+)
+
+// 设备管理
+var (
+	opListDevices       = &operation{"ListDevices", http.MethodGet, "/v5/iot/{project_id}/devices", []int{http.StatusOK}, ApiGroupDevice}
+	opCreateDevice      = &operation{"CreateDevice", http.MethodPost, "/v5/iot/{project_id}/devices", []int{http.StatusOK, http.StatusCreated}, ApiGroupDevice}
+	opShowDevice        = &operation{"ShowDevice", http.MethodGet, "/v5/iot/{project_id}/devices/{device_id}", []int{http.StatusOK}, ApiGroupDevice}
+	opUpdateDevice      = &operation{"UpdateDevice", http.MethodPut, "/v5/iot/{project_id}/devices/{device_id}", []int{http.StatusOK}, ApiGroupDevice}
+	opDeleteDevice      = &operation{"DeleteDevice", http.MethodDelete, "/v5/iot/{project_id}/devices/{device_id}", []int{http.StatusNoContent}, ApiGroupDevice}
+	opFreezeDevice      = &operation{"FreezeDevice", http.MethodPost, "/v5/iot/{project_id}/devices/{device_id}/freeze", []int{http.StatusNoContent}, ApiGroupDevice}
+	opUnFreezeDevice    = &operation{"UnFreezeDevice", http.MethodPost, "/v5/iot/{project_id}/devices/{device_id}/unfreeze", []int{http.StatusNoContent}, ApiGroupDevice}
+	opResetDeviceSecret = &operation{"ResetDeviceSecret", http.MethodPost, "/v5/iot/{project_id}/devices/{device_id}/action", []int{http.StatusOK, http.StatusCreated}, ApiGroupDevice}
+)
+
+// 设备消息
+var (
+	opListDeviceMessages = &operation{"ListDeviceMessages", http.MethodGet, "/v5/iot/{project_id}/devices/{device_id}/messages", []int{http.StatusOK}, ApiGroupMessage}
+	opShowDeviceMessage  = &operation{"ShowDeviceMessage", http.MethodGet, "/v5/iot/{project_id}/devices/{device_id}/messages/{message_id}", []int{http.StatusOK}, ApiGroupMessage}
+	opSendDeviceMessage  = &operation{"SendDeviceMessage", http.MethodPost, "/v5/iot/{project_id}/devices/{device_id}/messages", []int{http.StatusOK, http.StatusCreated}, ApiGroupMessage}
+)
+
+// 设备命令
+var (
+	opSendDeviceSyncCommand   = &operation{"SendDeviceSyncCommand", http.MethodPost, "/v5/iot/{project_id}/devices/{device_id}/commands", []int{http.StatusOK, http.StatusCreated}, ApiGroupCommand}
+	opSendDeviceAsyncCommand  = &operation{"SendDeviceAsyncCommand", http.MethodPost, "/v5/iot/{project_id}/devices/{device_id}/async-commands", []int{http.StatusOK, http.StatusCreated}, ApiGroupCommand}
+	opShowDeviceAsyncCommand  = &operation{"ShowDeviceAsyncCommand", http.MethodGet, "/v5/iot/{project_id}/devices/{device_id}/async-commands/{command_id}", []int{http.StatusOK}, ApiGroupCommand}
+	opListDeviceAsyncCommands = &operation{"ListDeviceAsyncCommands", http.MethodGet, "/v5/iot/{project_id}/devices/{device_id}/async-commands-history", []int{http.StatusOK}, ApiGroupCommand}
+)
+
+// 设备属性
+var (
+	opQueryDeviceProperties  = &operation{"QueryDeviceProperties", http.MethodGet, "/v5/iot/{project_id}/devices/{device_id}/properties", []int{http.StatusOK}, ApiGroupProperty}
+	opUpdateDeviceProperties = &operation{"UpdateDeviceProperties", http.MethodPut, "/v5/iot/{project_id}/devices/{device_id}/properties", []int{http.StatusOK}, ApiGroupProperty}
+)
+
+// AMQP队列管理和接入凭证管理
+var (
+	opListAmqpQueues   = &operation{"ListAmqpQueues", http.MethodGet, "/v5/iot/{project_id}/amqp-queues", []int{http.StatusOK}, ApiGroupAmqp}
+	opCreateAmqpQueue  = &operation{"CreateAmqpQueue", http.MethodPost, "/v5/iot/{project_id}/amqp-queues", []int{http.StatusCreated}, ApiGroupAmqp}
+	opShowAmqpQueue    = &operation{"ShowAmqpQueue", http.MethodGet, "/v5/iot/{project_id}/amqp-queues/{queue_id}", []int{http.StatusOK}, ApiGroupAmqp}
+	opDeleteAmqpQueue  = &operation{"DeleteAmqpQueue", http.MethodDelete, "/v5/iot/{project_id}/amqp-queues/{queue_id}", []int{http.StatusNoContent}, ApiGroupAmqp}
+	opCreateAccessCode = &operation{"CreateAccessCode", http.MethodPost, "/v5/iot/{project_id}/auth/accesscode", []int{http.StatusCreated}, ApiGroupAmqp}
+)
+
+// 数据流转规则管理
+var (
+	opListRoutingRules  = &operation{"ListRoutingRules", http.MethodGet, "/v5/iot/{project_id}/routing-rule/rules", []int{http.StatusOK}, ApiGroupRoutingRule}
+	opCreateRoutingRule = &operation{"CreateRoutingRule", http.MethodPost, "/v5/iot/{project_id}/routing-rule/rules", []int{http.StatusCreated}, ApiGroupRoutingRule}
+	opShowRoutingRule   = &operation{"ShowRoutingRule", http.MethodGet, "/v5/iot/{project_id}/routing-rule/rules/{rule_id}", []int{http.StatusOK}, ApiGroupRoutingRule}
+	opUpdateRoutingRule = &operation{"UpdateRoutingRule", http.MethodPut, "/v5/iot/{project_id}/routing-rule/rules/{rule_id}", []int{http.StatusOK}, ApiGroupRoutingRule}
+	opDeleteRoutingRule = &operation{"DeleteRoutingRule", http.MethodDelete, "/v5/iot/{project_id}/routing-rule/rules/{rule_id}", []int{http.StatusNoContent}, ApiGroupRoutingRule}
+	opListRuleActions   = &operation{"ListRuleActions", http.MethodGet, "/v5/iot/{project_id}/routing-rule/actions", []int{http.StatusOK}, ApiGroupRoutingRule}
+	opCreateRuleAction  = &operation{"CreateRuleAction", http.MethodPost, "/v5/iot/{project_id}/routing-rule/actions", []int{http.StatusCreated}, ApiGroupRoutingRule}
+	opShowRuleAction    = &operation{"ShowRuleAction", http.MethodGet, "/v5/iot/{project_id}/routing-rule/actions/{action_id}", []int{http.StatusOK}, ApiGroupRoutingRule}
+	opUpdateRuleAction  = &operation{"UpdateRuleAction", http.MethodPut, "/v5/iot/{project_id}/routing-rule/actions/{action_id}", []int{http.StatusOK}, ApiGroupRoutingRule}
+	opDeleteRuleAction  = &operation{"DeleteRuleAction", http.MethodDelete, "/v5/iot/{project_id}/routing-rule/actions/{action_id}", []int{http.StatusNoContent}, ApiGroupRoutingRule}
+)
+
+// 设备影子
+var (
+	opShowDeviceShadow   = &operation{"ShowDeviceShadow", http.MethodGet, "/v5/iot/{project_id}/devices/{device_id}/shadow", []int{http.StatusOK}, ApiGroupShadow}
+	opUpdateDeviceShadow = &operation{"UpdateDeviceShadow", http.MethodPut, "/v5/iot/{project_id}/devices/{device_id}/shadow", []int{http.StatusOK}, ApiGroupShadow}
+)
+
+// 设备组管理
+var (
+	opListDeviceGroups         = &operation{"ListDeviceGroups", http.MethodGet, "/v5/iot/{project_id}/device-group", []int{http.StatusOK}, ApiGroupDeviceGroup}
+	opCreateDeviceGroup        = &operation{"CreateDeviceGroup", http.MethodPost, "/v5/iot/{project_id}/device-group", []int{http.StatusCreated}, ApiGroupDeviceGroup}
+	opShowDeviceGroup          = &operation{"ShowDeviceGroup", http.MethodGet, "/v5/iot/{project_id}/device-group/{group_id}", []int{http.StatusOK}, ApiGroupDeviceGroup}
+	opUpdateDeviceGroup        = &operation{"UpdateDeviceGroup", http.MethodPut, "/v5/iot/{project_id}/device-group/{group_id}", []int{http.StatusOK}, ApiGroupDeviceGroup}
+	opDeleteDeviceGroup        = &operation{"DeleteDeviceGroup", http.MethodDelete, "/v5/iot/{project_id}/device-group/{group_id}", []int{http.StatusOK, http.StatusNoContent}, ApiGroupDeviceGroup}
+	opManageDeviceGroupDevices = &operation{"ManageDeviceGroupDevices", http.MethodPost, "/v5/iot/{project_id}/device-group/{group_id}/action", []int{http.StatusOK}, ApiGroupDeviceGroup}
+	opListDeviceInDeviceGroup  = &operation{"ListDeviceInDeviceGroup", http.MethodGet, "/v5/iot/{project_id}/device-group/{group_id}/devices", []int{http.StatusOK}, ApiGroupDeviceGroup}
+)
+
+// 标签管理
+var (
+	opDeviceBindTags   = &operation{"DeviceBindTags", http.MethodPost, "/v5/iot/{project_id}/tags/bind-resource", []int{http.StatusOK}, ApiGroupTag}
+	opDeviceUnBindTags = &operation{"DeviceUnBindTags", http.MethodPost, "/v5/iot/{project_id}/tags/unbind-resource", []int{http.StatusOK}, ApiGroupTag}
+	opListDeviceByTags = &operation{"ListDeviceByTags", http.MethodPost, "/v5/iot/{project_id}/tags/query-resources", []int{http.StatusOK}, ApiGroupTag}
+)
+
+// 资源空间管理
+var (
+	opListApplications  = &operation{"ListApplications", http.MethodGet, "/v5/iot/{project_id}/apps", []int{http.StatusOK}, ApiGroupApplication}
+	opShowApplication   = &operation{"ShowApplication", http.MethodGet, "/v5/iot/{project_id}/apps/{app_id}", []int{http.StatusOK}, ApiGroupApplication}
+	opDeleteApplication = &operation{"DeleteApplication", http.MethodDelete, "/v5/iot/{project_id}/apps/{app_id}", []int{http.StatusNoContent}, ApiGroupApplication}
+	opCreateApplication = &operation{"CreateApplication", http.MethodPost, "/v5/iot/{project_id}/apps", []int{http.StatusOK, http.StatusCreated}, ApiGroupApplication}
+)
+
+// 批量任务和批量任务文件管理
+var (
+	opListBatchTasks      = &operation{"ListBatchTasks", http.MethodGet, "/v5/iot/{project_id}/batchtasks", []int{http.StatusOK}, ApiGroupBatchTask}
+	opCreateBatchTask     = &operation{"CreateBatchTask", http.MethodPost, "/v5/iot/{project_id}/batchtasks", []int{http.StatusCreated}, ApiGroupBatchTask}
+	opShowBatchTask       = &operation{"ShowBatchTask", http.MethodGet, "/v5/iot/{project_id}/batchtasks/{task_id}", []int{http.StatusOK}, ApiGroupBatchTask}
+	opDeleteBatchTask     = &operation{"DeleteBatchTask", http.MethodDelete, "/v5/iot/{project_id}/batchtasks/{task_id}", []int{http.StatusNoContent}, ApiGroupBatchTask}
+	opUploadBatchTaskFile = &operation{"UploadBatchTaskFile", http.MethodPost, "/v5/iot/{project_id}/batchtask-files", []int{http.StatusCreated}, ApiGroupBatchTask}
+	opListBatchTaskFiles  = &operation{"ListBatchTaskFiles", http.MethodGet, "/v5/iot/{project_id}/batchtask-files", []int{http.StatusOK}, ApiGroupBatchTask}
+	opDeleteBatchTaskFile = &operation{"DeleteBatchTaskFile", http.MethodDelete, "/v5/iot/{project_id}/batchtask-files/{file_id}", []int{http.StatusNoContent}, ApiGroupBatchTask}
+)
+
+// 设备CA证书管理
+var (
+	opListDeviceCertificates   = &operation{"ListDeviceCertificates", http.MethodGet, "/v5/iot/{project_id}/certificates", []int{http.StatusOK}, ApiGroupCertificate}
+	opUploadDeviceCertificates = &operation{"UploadDeviceCertificates", http.MethodPost, "/v5/iot/{project_id}/certificates", []int{http.StatusOK}, ApiGroupCertificate}
+	opDeleteDeviceCertificates = &operation{"DeleteDeviceCertificates", http.MethodDelete, "/v5/iot/{project_id}/certificates/{certificate_id}", []int{http.StatusNoContent}, ApiGroupCertificate}
+	opVerifyDeviceCertificates = &operation{"VerifyDeviceCertificates", http.MethodPost, "/v5/iot/{project_id}/certificates/{certificate_id}/action", []int{http.StatusOK}, ApiGroupCertificate}
+)
+
+// apiRequest 一次API调用的参数，body不为空时以JSON格式发送，file不为空时以multipart/form-data格式上传
+type apiRequest struct {
+	pathParams  map[string]string
+	queryParams map[string]string
+	body        interface{}
+
+	fileName string
+	file     io.Reader
+}
+
+// invoke 是所有API调用共用的处理流程：构造请求、经过拦截器发送请求、校验状态码并解析响应。
+// 状态码不是operation期望的状态码时返回*ApplicationError，result为nil时不解析响应体。
+func (client *syncClient) invoke(ctx context.Context, op *operation, request *apiRequest, result interface{}) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if request == nil {
+		request = &apiRequest{}
+	}
+
+	r := &Request{
+		Operation:   op.name,
+		Method:      op.method,
+		Path:        op.path,
+		ProjectId:   client.currentProjectId(),
+		PathParams:  request.pathParams,
+		QueryParams: request.queryParams,
+		Header:      http.Header{},
+		Body:        request.body,
+		fileName:    request.fileName,
+		file:        request.file,
+	}
+
+	invoker := chainInterceptors(client.interceptors, func(ctx context.Context, request *Request) (*Response, error) {
+		return client.executeWithRetry(ctx, op, request)
+	})
+
+	start := time.Now()
+	response, err := invoker(ctx, r)
+	if err == nil {
+		err = decodeResponse(op, r, response, result)
+	}
+	client.recordMetrics(ctx, op, r, response, err, time.Since(start))
+
+	return err
+}
+
+func decodeResponse(op *operation, request *Request, response *Response, result interface{}) error {
+	if !op.success(response.StatusCode) {
+		endpoint := response.URL
+		if len(endpoint) == 0 {
+			endpoint = request.Path
+		}
+		return newApplicationError(response.StatusCode, response.Header, response.Body, request.Method, endpoint)
+	}
+
+	if result == nil || len(response.Body) == 0 {
+		return nil
+	}
+
+	err := json.Unmarshal(response.Body, result)
+	if err != nil {
+		return fmt.Errorf("decode response of %s failed: %w", op.name, err)
+	}
+
+	return nil
+}
+
+// attempt 执行一次请求。凭证可能在过期之前被吊销或者轮换，平台返回401时丢弃缓存的凭证后重新发送一次；
+// 上传文件的请求体无法重复读取，不重新发送
+func (client *syncClient) attempt(ctx context.Context, op *operation, request *Request) (*Response, error) {
+	httpResponse, err := client.execute(ctx, op, request)
+	if err != nil {
+		return nil, err
+	}
+
+	invalidator := asCredentialsInvalidator(client.credentials)
+	if httpResponse.StatusCode() == http.StatusUnauthorized && request.file == nil && invalidator != nil {
+		invalidator.Invalidate()
+		httpResponse, err = client.execute(ctx, op, request)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return newResponse(httpResponse), nil
+}
+
+func (client *syncClient) execute(ctx context.Context, op *operation, request *Request) (*resty.Response, error) {
+	if client.configErr != nil {
+		return nil, client.configErr
+	}
+
+	err := client.rateLimiter.wait(ctx, op.group)
+	if err != nil {
+		return nil, err
+	}
+
+	projectId, err := client.resolveProjectId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	request.ProjectId = projectId
+
+	rawRequest := client.client.R().
+		SetContext(ctx).
+		SetPathParam("project_id", projectId).
+		SetPathParams(request.PathParams).
+		SetQueryParams(request.QueryParams)
+
+	if request.Body != nil {
+		body, err := marshalRequestBody(request.Body)
+		if err != nil {
+			return nil, fmt.Errorf("marshal request of %s failed: %w", request.Operation, err)
+		}
+
+		rawRequest.
+			SetHeader("Content-Type", "application/json").
+			SetBody(body)
+	}
+
+	for key, values := range request.Header {
+		rawRequest.Header[http.CanonicalHeaderKey(key)] = values
+	}
+
+	if request.file != nil {
+		rawRequest.SetFileReader("file", request.fileName, request.file)
+	}
+
+	return rawRequest.Execute(request.Method, request.Path)
+}
+
+// 请求体统一序列化为[]byte，签名时使用的也是序列化之后的内容
+func marshalRequestBody(body interface{}) ([]byte, error) {
+	switch value := body.(type) {
+	case []byte:
+		return value, nil
+	case string:
+		return []byte(value), nil
+	case json.RawMessage:
+		return value, nil
+	default:
+		return json.Marshal(body)
+	}
+}
+
+const (
+	maxPageLimit  = 50
+	maxPageOffset = 500
+)
+
+// 分页查询参数，limit取值范围为1~50，offset取值范围为0~500。limit为0时使用默认值10，超出范围时取边界值
+func pageQueryParams(limit int, marker string, offset int) map[string]string {
+	switch {
+	case limit <= 0:
+		limit = 10
+	case limit > maxPageLimit:
+		limit = maxPageLimit
+	}
+
+	switch {
+	case offset < 0:
+		offset = 0
+	case offset > maxPageOffset:
+		offset = maxPageOffset
+	}
+
+	queryParams := map[string]string{
+		"limit":  strconv.Itoa(limit),
+		"offset": strconv.Itoa(offset),
+	}
+	if len(marker) != 0 {
+		queryParams["marker"] = marker
+	}
+
+	return queryParams
+}
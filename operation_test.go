@@ -0,0 +1,58 @@
+package iot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestPageQueryParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		limit  int
+		marker string
+		offset int
+		want   map[string]string
+	}{
+		{"default", 0, "", 0, map[string]string{"limit": "10", "offset": "0"}},
+		{"in range", 20, "marker", 100, map[string]string{"limit": "20", "marker": "marker", "offset": "100"}},
+		{"bounds", 50, "", 500, map[string]string{"limit": "50", "offset": "500"}},
+		{"clamp upper bound", 51, "", 501, map[string]string{"limit": "50", "offset": "500"}},
+		{"clamp lower bound", -1, "", -1, map[string]string{"limit": "10", "offset": "0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pageQueryParams(tt.limit, tt.marker, tt.offset); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pageQueryParams() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListAmqpQueuesPageQuery(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		_, _ = w.Write([]byte(`{"queues":[]}`))
+	}))
+	defer server.Close()
+
+	client := CreateSyncIotApplicationClient(*NewApplicationOptions().WithEndpoint(server.URL).
+		SetProjectId("project").SetToken("token"))
+
+	_, err := client.ListAmqpQueues(ListAmqpQueuesRequest{QueueName: "queue", Limit: 100, Offset: "600"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := url.Values{"queue_name": {"queue"}, "limit": {"50"}, "offset": {"500"}}
+	if !reflect.DeepEqual(query, want) {
+		t.Errorf("query = %v, want %v", query, want)
+	}
+
+	if _, err = client.ListAmqpQueues(ListAmqpQueuesRequest{Offset: "abc"}); err == nil {
+		t.Error("ListAmqpQueues() with invalid offset should return error")
+	}
+}
@@ -0,0 +1,122 @@
+package iot
+
+type ServiceCapability struct {
+	ServiceID   string            `json:"service_id"`
+	ServiceType string            `json:"service_type"`
+	Properties  []ServiceProperty `json:"properties,omitempty"`
+	Commands    []ServiceCommand  `json:"commands,omitempty"`
+	Events      []ServiceEvent    `json:"events,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Option      string            `json:"option,omitempty"`
+}
+
+type ServiceProperty struct {
+	PropertyName string      `json:"property_name"`
+	Required     bool        `json:"required,omitempty"`
+	DataType     string      `json:"data_type"`
+	EnumList     []string    `json:"enum_list,omitempty"`
+	Min          string      `json:"min,omitempty"`
+	Max          string      `json:"max,omitempty"`
+	MaxLength    int         `json:"max_length,omitempty"`
+	Step         float64     `json:"step,omitempty"`
+	Unit         string      `json:"unit,omitempty"`
+	Method       string      `json:"method"`
+	Description  string      `json:"description,omitempty"`
+	DefaultValue interface{} `json:"default_value,omitempty"`
+}
+
+type ServiceCommand struct {
+	CommandName string                   `json:"command_name"`
+	Paras       []ServiceCommandPara     `json:"paras,omitempty"`
+	Responses   []ServiceCommandResponse `json:"responses,omitempty"`
+}
+
+type ServiceCommandPara struct {
+	ParaName    string   `json:"para_name"`
+	Required    bool     `json:"required,omitempty"`
+	DataType    string   `json:"data_type"`
+	EnumList    []string `json:"enum_list,omitempty"`
+	Min         string   `json:"min,omitempty"`
+	Max         string   `json:"max,omitempty"`
+	MaxLength   int      `json:"max_length,omitempty"`
+	Step        float64  `json:"step,omitempty"`
+	Unit        string   `json:"unit,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+type ServiceCommandResponse struct {
+	ResponseName string               `json:"response_name"`
+	Paras        []ServiceCommandPara `json:"paras,omitempty"`
+}
+
+type ServiceEvent struct {
+	EventType string               `json:"event_type"`
+	Paras     []ServiceCommandPara `json:"paras,omitempty"`
+}
+
+// 产品管理-创建产品
+type CreateProductRequest struct {
+	ProductID           string              `json:"product_id,omitempty"`
+	Name                string              `json:"name"`
+	DeviceType          string              `json:"device_type"`
+	ProtocolType        string              `json:"protocol_type"`
+	DataFormat          string              `json:"data_format"`
+	ServiceCapabilities []ServiceCapability `json:"service_capabilities"`
+	ManufacturerName    string              `json:"manufacturer_name,omitempty"`
+	Industry            string              `json:"industry,omitempty"`
+	Description         string              `json:"description,omitempty"`
+	AppID               string              `json:"app_id,omitempty"`
+}
+
+type UpdateProductRequest struct {
+	Name                string              `json:"name,omitempty"`
+	DeviceType          string              `json:"device_type,omitempty"`
+	ProtocolType        string              `json:"protocol_type,omitempty"`
+	DataFormat          string              `json:"data_format,omitempty"`
+	ServiceCapabilities []ServiceCapability `json:"service_capabilities,omitempty"`
+	ManufacturerName    string              `json:"manufacturer_name,omitempty"`
+	Industry            string              `json:"industry,omitempty"`
+	Description         string              `json:"description,omitempty"`
+	AppID               string              `json:"app_id,omitempty"`
+}
+
+type ProductDetailResponse struct {
+	AppID               string              `json:"app_id"`
+	AppName             string              `json:"app_name"`
+	ProductID           string              `json:"product_id"`
+	Name                string              `json:"name"`
+	DeviceType          string              `json:"device_type"`
+	ProtocolType        string              `json:"protocol_type"`
+	DataFormat          string              `json:"data_format"`
+	ManufacturerName    string              `json:"manufacturer_name"`
+	Industry            string              `json:"industry"`
+	Description         string              `json:"description"`
+	ServiceCapabilities []ServiceCapability `json:"service_capabilities"`
+	CreateTime          string              `json:"create_time"`
+}
+
+type ListProductsRequest struct {
+	AppId  string `json:"app_id,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+	Marker string `json:"marker,omitempty"`
+	Offset int    `json:"offset,omitempty"`
+}
+
+type ListProductsResponse struct {
+	Products []ProductSummary `json:"products"`
+	Page     Page             `json:"page"`
+}
+
+type ProductSummary struct {
+	AppID            string `json:"app_id"`
+	AppName          string `json:"app_name"`
+	ProductID        string `json:"product_id"`
+	Name             string `json:"name"`
+	DeviceType       string `json:"device_type"`
+	ProtocolType     string `json:"protocol_type"`
+	DataFormat       string `json:"data_format"`
+	ManufacturerName string `json:"manufacturer_name"`
+	Industry         string `json:"industry"`
+	Description      string `json:"description"`
+	CreateTime       string `json:"create_time"`
+}
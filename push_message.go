@@ -0,0 +1,262 @@
+package iot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// 推送消息格式不正确时返回的错误
+var ErrInvalidPushMessage = errors.New("invalid push message")
+
+// 平台推送消息，AMQP和HTTP推送使用相同的消息格式
+type PushMessage struct {
+	Resource    string     `json:"resource"`
+	Event       string     `json:"event"`
+	EventTime   string     `json:"event_time"`
+	EventTimeMs string     `json:"event_time_ms,omitempty"`
+	RequestID   string     `json:"request_id,omitempty"`
+	NotifyData  NotifyData `json:"notify_data"`
+}
+
+type NotifyData struct {
+	Header NotifyHeader    `json:"header"`
+	Body   json.RawMessage `json:"body"`
+}
+
+type NotifyHeader struct {
+	AppID     string     `json:"app_id"`
+	DeviceID  string     `json:"device_id"`
+	NodeID    string     `json:"node_id"`
+	ProductID string     `json:"product_id"`
+	GatewayID string     `json:"gateway_id"`
+	Tags      []TagV5DTO `json:"tags"`
+}
+
+// 设备属性上报，resource为device.property，event为report
+type DevicePropertyReport struct {
+	Services []DeviceServiceData `json:"services"`
+}
+
+type DeviceServiceData struct {
+	ServiceID  string                 `json:"service_id"`
+	Properties map[string]interface{} `json:"properties"`
+	EventTime  string                 `json:"event_time"`
+}
+
+// 设备消息上报，resource为device.message，event为report
+type DeviceMessageReport struct {
+	Topic   string          `json:"topic"`
+	Content json.RawMessage `json:"content"`
+}
+
+// 设备消息状态变更，resource为device.message.status，event为update
+type DeviceMessageStatusChange struct {
+	MessageID string          `json:"message_id"`
+	Name      string          `json:"name"`
+	Status    string          `json:"status"`
+	Timestamp string          `json:"timestamp"`
+	ErrorInfo TaskDetailError `json:"error_info"`
+}
+
+// 设备异步命令状态变更，resource为device.command.status，event为update
+type DeviceCommandStatusChange struct {
+	CommandID     string              `json:"command_id"`
+	CreatedTime   string              `json:"created_time"`
+	SentTime      string              `json:"sent_time"`
+	DeliveredTime string              `json:"delivered_time"`
+	ResponseTime  string              `json:"response_time"`
+	Status        string              `json:"status"`
+	Result        DeviceCommandResult `json:"result"`
+}
+
+type DeviceCommandResult struct {
+	ResultCode   int                    `json:"result_code"`
+	ResponseName string                 `json:"response_name"`
+	Paras        map[string]interface{} `json:"paras"`
+}
+
+// 设备状态变更，resource为device.status，event为update
+type DeviceStatusChange struct {
+	Status         string `json:"status"`
+	LastOnlineTime string `json:"last_online_time"`
+}
+
+// 设备添加、删除和更新，resource为device，event为create、delete或update
+type DeviceLifecycleEvent struct {
+	DeviceDetailResponse
+}
+
+// 批量任务状态变更，resource为batchtask，event为update
+type BatchTaskProgress struct {
+	TaskID       string       `json:"task_id"`
+	Status       string       `json:"status"`
+	StatusDesc   string       `json:"status_desc"`
+	TaskProgress TaskProgress `json:"task_progress"`
+}
+
+// 设备OTA升级结果，resource为device.ota，event为update
+type OtaResult struct {
+	ResultCode  int    `json:"result_code"`
+	Progress    int    `json:"progress"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+}
+
+// 推送消息处理接口，嵌入BasePushMessageHandler后只需要实现关心的方法
+type PushMessageHandler interface {
+	OnDevicePropertyReport(ctx context.Context, message *PushMessage, body *DevicePropertyReport) error
+	OnDeviceMessageReport(ctx context.Context, message *PushMessage, body *DeviceMessageReport) error
+	OnDeviceMessageStatusChange(ctx context.Context, message *PushMessage, body *DeviceMessageStatusChange) error
+	OnDeviceCommandStatusChange(ctx context.Context, message *PushMessage, body *DeviceCommandStatusChange) error
+	OnDeviceStatusChange(ctx context.Context, message *PushMessage, body *DeviceStatusChange) error
+	OnDeviceLifecycleEvent(ctx context.Context, message *PushMessage, body *DeviceLifecycleEvent) error
+	OnBatchTaskProgress(ctx context.Context, message *PushMessage, body *BatchTaskProgress) error
+	OnOtaResult(ctx context.Context, message *PushMessage, body *OtaResult) error
+	OnUnknownMessage(ctx context.Context, message *PushMessage) error
+}
+
+type BasePushMessageHandler struct {
+}
+
+func (BasePushMessageHandler) OnDevicePropertyReport(ctx context.Context, message *PushMessage, body *DevicePropertyReport) error {
+	return nil
+}
+
+func (BasePushMessageHandler) OnDeviceMessageReport(ctx context.Context, message *PushMessage, body *DeviceMessageReport) error {
+	return nil
+}
+
+func (BasePushMessageHandler) OnDeviceMessageStatusChange(ctx context.Context, message *PushMessage, body *DeviceMessageStatusChange) error {
+	return nil
+}
+
+func (BasePushMessageHandler) OnDeviceCommandStatusChange(ctx context.Context, message *PushMessage, body *DeviceCommandStatusChange) error {
+	return nil
+}
+
+func (BasePushMessageHandler) OnDeviceStatusChange(ctx context.Context, message *PushMessage, body *DeviceStatusChange) error {
+	return nil
+}
+
+func (BasePushMessageHandler) OnDeviceLifecycleEvent(ctx context.Context, message *PushMessage, body *DeviceLifecycleEvent) error {
+	return nil
+}
+
+func (BasePushMessageHandler) OnBatchTaskProgress(ctx context.Context, message *PushMessage, body *BatchTaskProgress) error {
+	return nil
+}
+
+func (BasePushMessageHandler) OnOtaResult(ctx context.Context, message *PushMessage, body *OtaResult) error {
+	return nil
+}
+
+func (BasePushMessageHandler) OnUnknownMessage(ctx context.Context, message *PushMessage) error {
+	return nil
+}
+
+func DecodePushMessage(data []byte) (*PushMessage, error) {
+	message := &PushMessage{}
+	err := json.Unmarshal(data, message)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPushMessage, err)
+	}
+
+	if len(message.Resource) == 0 {
+		return nil, fmt.Errorf("%w: resource is empty", ErrInvalidPushMessage)
+	}
+
+	return message, nil
+}
+
+// DispatchPushMessage 解析推送消息并根据resource和event分发到handler对应的方法
+func DispatchPushMessage(ctx context.Context, data []byte, handler PushMessageHandler) error {
+	message, err := DecodePushMessage(data)
+	if err != nil {
+		return err
+	}
+
+	return message.Dispatch(ctx, handler)
+}
+
+func (m *PushMessage) Dispatch(ctx context.Context, handler PushMessageHandler) error {
+	switch {
+	case m.Resource == RuleResourceDeviceProperty && m.Event == RuleEventReport:
+		body := &DevicePropertyReport{}
+		if err := m.decodeBody(body); err != nil {
+			return err
+		}
+		return handler.OnDevicePropertyReport(ctx, m, body)
+	case m.Resource == RuleResourceDeviceMessage && m.Event == RuleEventReport:
+		body := &DeviceMessageReport{}
+		if err := m.decodeBody(body); err != nil {
+			return err
+		}
+		return handler.OnDeviceMessageReport(ctx, m, body)
+	case m.Resource == RuleResourceDeviceMessageStatus && m.Event == RuleEventUpdate:
+		body := &DeviceMessageStatusChange{}
+		if err := m.decodeBody(body); err != nil {
+			return err
+		}
+		return handler.OnDeviceMessageStatusChange(ctx, m, body)
+	case m.Resource == RuleResourceDeviceCommandStatus && m.Event == RuleEventUpdate:
+		body := &DeviceCommandStatusChange{}
+		if err := m.decodeBody(body); err != nil {
+			return err
+		}
+		return handler.OnDeviceCommandStatusChange(ctx, m, body)
+	case m.Resource == RuleResourceDeviceStatus && m.Event == RuleEventUpdate:
+		body := &DeviceStatusChange{}
+		if err := m.decodeBody(body); err != nil {
+			return err
+		}
+		return handler.OnDeviceStatusChange(ctx, m, body)
+	case m.Resource == RuleResourceDevice &&
+		(m.Event == RuleEventCreate || m.Event == RuleEventDelete || m.Event == RuleEventUpdate):
+		body := &DeviceLifecycleEvent{}
+		if err := m.decodeBody(body); err != nil {
+			return err
+		}
+		return handler.OnDeviceLifecycleEvent(ctx, m, body)
+	case m.Resource == RuleResourceBatchTask && m.Event == RuleEventUpdate:
+		body := &BatchTaskProgress{}
+		if err := m.decodeBody(body); err != nil {
+			return err
+		}
+		return handler.OnBatchTaskProgress(ctx, m, body)
+	case m.Resource == RuleResourceOta && m.Event == RuleEventUpdate:
+		body := &OtaResult{}
+		if err := m.decodeBody(body); err != nil {
+			return err
+		}
+		return handler.OnOtaResult(ctx, m, body)
+	default:
+		return handler.OnUnknownMessage(ctx, m)
+	}
+}
+
+func (m *PushMessage) decodeBody(body interface{}) error {
+	if len(m.NotifyData.Body) == 0 {
+		return nil
+	}
+
+	err := json.Unmarshal(m.NotifyData.Body, body)
+	if err != nil {
+		return fmt.Errorf("%w: decode body of %s/%s failed: %v", ErrInvalidPushMessage, m.Resource, m.Event, err)
+	}
+
+	return nil
+}
+
+// NewAmqpPushMessageHandler 将PushMessageHandler适配为AmqpConsumer使用的处理函数，无法解析的消息会被拒绝
+func NewAmqpPushMessageHandler(handler PushMessageHandler) AmqpMessageHandler {
+	return func(ctx context.Context, message *AmqpMessage) error {
+		err := DispatchPushMessage(ctx, message.Body, handler)
+		if errors.Is(err, ErrInvalidPushMessage) {
+			return fmt.Errorf("%w: %v", ErrAmqpMessageRejected, err)
+		}
+
+		return err
+	}
+}
@@ -0,0 +1,211 @@
+package iot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// 记录被调用的方法和解析出的消息体
+type dispatchRecorder struct {
+	method  string
+	message *PushMessage
+	body    interface{}
+}
+
+func (r *dispatchRecorder) record(method string, message *PushMessage, body interface{}) error {
+	r.method = method
+	r.message = message
+	r.body = body
+	return nil
+}
+
+func (r *dispatchRecorder) OnDevicePropertyReport(ctx context.Context, message *PushMessage, body *DevicePropertyReport) error {
+	return r.record("OnDevicePropertyReport", message, body)
+}
+
+func (r *dispatchRecorder) OnDeviceMessageReport(ctx context.Context, message *PushMessage, body *DeviceMessageReport) error {
+	return r.record("OnDeviceMessageReport", message, body)
+}
+
+func (r *dispatchRecorder) OnDeviceMessageStatusChange(ctx context.Context, message *PushMessage, body *DeviceMessageStatusChange) error {
+	return r.record("OnDeviceMessageStatusChange", message, body)
+}
+
+func (r *dispatchRecorder) OnDeviceCommandStatusChange(ctx context.Context, message *PushMessage, body *DeviceCommandStatusChange) error {
+	return r.record("OnDeviceCommandStatusChange", message, body)
+}
+
+func (r *dispatchRecorder) OnDeviceStatusChange(ctx context.Context, message *PushMessage, body *DeviceStatusChange) error {
+	return r.record("OnDeviceStatusChange", message, body)
+}
+
+func (r *dispatchRecorder) OnDeviceLifecycleEvent(ctx context.Context, message *PushMessage, body *DeviceLifecycleEvent) error {
+	return r.record("OnDeviceLifecycleEvent", message, body)
+}
+
+func (r *dispatchRecorder) OnBatchTaskProgress(ctx context.Context, message *PushMessage, body *BatchTaskProgress) error {
+	return r.record("OnBatchTaskProgress", message, body)
+}
+
+func (r *dispatchRecorder) OnOtaResult(ctx context.Context, message *PushMessage, body *OtaResult) error {
+	return r.record("OnOtaResult", message, body)
+}
+
+func (r *dispatchRecorder) OnUnknownMessage(ctx context.Context, message *PushMessage) error {
+	return r.record("OnUnknownMessage", message, nil)
+}
+
+func testPushMessage(resource, event, body string) []byte {
+	return []byte(`{"resource":"` + resource + `","event":"` + event + `","event_time":"20151212T121212Z",` +
+		`"notify_data":{"header":{"device_id":"product_node","product_id":"product","node_id":"node"},"body":` + body + `}}`)
+}
+
+func TestDispatchPushMessage(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   []byte
+		method string
+		body   interface{}
+	}{
+		{
+			name: "property report",
+			data: testPushMessage(RuleResourceDeviceProperty, RuleEventReport,
+				`{"services":[{"service_id":"temperature","properties":{"value":25.5},"event_time":"20151212T121212Z"}]}`),
+			method: "OnDevicePropertyReport",
+			body: &DevicePropertyReport{Services: []DeviceServiceData{
+				{ServiceID: "temperature", Properties: map[string]interface{}{"value": 25.5}, EventTime: "20151212T121212Z"},
+			}},
+		},
+		{
+			name:   "message report",
+			data:   testPushMessage(RuleResourceDeviceMessage, RuleEventReport, `{"topic":"t","content":"hello"}`),
+			method: "OnDeviceMessageReport",
+			body:   &DeviceMessageReport{Topic: "t", Content: json.RawMessage(`"hello"`)},
+		},
+		{
+			name: "message status",
+			data: testPushMessage(RuleResourceDeviceMessageStatus, RuleEventUpdate,
+				`{"message_id":"message-1","status":"FAILED","error_info":{"error_code":"IOTDA.014016","error_msg":"device offline"}}`),
+			method: "OnDeviceMessageStatusChange",
+			body: &DeviceMessageStatusChange{MessageID: "message-1", Status: "FAILED",
+				ErrorInfo: TaskDetailError{ErrorCode: "IOTDA.014016", ErrorMsg: "device offline"}},
+		},
+		{
+			name: "command status",
+			data: testPushMessage(RuleResourceDeviceCommandStatus, RuleEventUpdate,
+				`{"command_id":"command-1","status":"SUCCESSFUL","result":{"result_code":0,"response_name":"ok","paras":{"result":"done"}}}`),
+			method: "OnDeviceCommandStatusChange",
+			body: &DeviceCommandStatusChange{CommandID: "command-1", Status: "SUCCESSFUL",
+				Result: DeviceCommandResult{ResponseName: "ok", Paras: map[string]interface{}{"result": "done"}}},
+		},
+		{
+			name:   "device status",
+			data:   testPushMessage(RuleResourceDeviceStatus, RuleEventUpdate, `{"status":"ONLINE","last_online_time":"20151212T121212Z"}`),
+			method: "OnDeviceStatusChange",
+			body:   &DeviceStatusChange{Status: "ONLINE", LastOnlineTime: "20151212T121212Z"},
+		},
+		{
+			name:   "device added",
+			data:   testPushMessage(RuleResourceDevice, RuleEventCreate, `{"device_id":"product_node","node_id":"node"}`),
+			method: "OnDeviceLifecycleEvent",
+			body:   &DeviceLifecycleEvent{DeviceDetailResponse{DeviceID: "product_node", NodeID: "node"}},
+		},
+		{
+			name:   "device updated",
+			data:   testPushMessage(RuleResourceDevice, RuleEventUpdate, `{"device_id":"product_node","device_name":"renamed"}`),
+			method: "OnDeviceLifecycleEvent",
+			body:   &DeviceLifecycleEvent{DeviceDetailResponse{DeviceID: "product_node", DeviceName: "renamed"}},
+		},
+		{
+			name:   "device deleted",
+			data:   testPushMessage(RuleResourceDevice, RuleEventDelete, `{"device_id":"product_node"}`),
+			method: "OnDeviceLifecycleEvent",
+			body:   &DeviceLifecycleEvent{DeviceDetailResponse{DeviceID: "product_node"}},
+		},
+		{
+			name: "batch task status",
+			data: testPushMessage(RuleResourceBatchTask, RuleEventUpdate,
+				`{"task_id":"task-1","status":"Success","task_progress":{"total":2,"success":2}}`),
+			method: "OnBatchTaskProgress",
+			body:   &BatchTaskProgress{TaskID: "task-1", Status: "Success", TaskProgress: TaskProgress{Total: 2, Success: 2}},
+		},
+		{
+			name:   "ota",
+			data:   testPushMessage(RuleResourceOta, RuleEventUpdate, `{"result_code":0,"progress":100,"version":"v2"}`),
+			method: "OnOtaResult",
+			body:   &OtaResult{Progress: 100, Version: "v2"},
+		},
+		{
+			name:   "empty body",
+			data:   testPushMessage(RuleResourceDeviceStatus, RuleEventUpdate, `null`),
+			method: "OnDeviceStatusChange",
+			body:   &DeviceStatusChange{},
+		},
+		{
+			name:   "unknown resource",
+			data:   testPushMessage(RuleResourceProduct, RuleEventCreate, `{"product_id":"product"}`),
+			method: "OnUnknownMessage",
+		},
+		{
+			name:   "unknown event",
+			data:   testPushMessage(RuleResourceDeviceProperty, RuleEventUpdate, `{}`),
+			method: "OnUnknownMessage",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &dispatchRecorder{}
+			if err := DispatchPushMessage(context.Background(), tt.data, recorder); err != nil {
+				t.Fatal(err)
+			}
+
+			if recorder.method != tt.method {
+				t.Errorf("method = %s, want %s", recorder.method, tt.method)
+			}
+			if !reflect.DeepEqual(recorder.body, tt.body) {
+				t.Errorf("body = %#v, want %#v", recorder.body, tt.body)
+			}
+			if recorder.message.NotifyData.Header.DeviceID != "product_node" || recorder.message.EventTime != "20151212T121212Z" {
+				t.Errorf("message = %+v", recorder.message)
+			}
+		})
+	}
+}
+
+func TestDispatchPushMessageInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"not json", []byte(`not json`)},
+		{"empty resource", []byte(`{"event":"report","notify_data":{}}`)},
+		{"malformed body", testPushMessage(RuleResourceDeviceProperty, RuleEventReport, `{"services":"not a list"}`)},
+		{"malformed lifecycle body", testPushMessage(RuleResourceDevice, RuleEventCreate, `[1, 2]`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &dispatchRecorder{}
+			err := DispatchPushMessage(context.Background(), tt.data, recorder)
+			if !errors.Is(err, ErrInvalidPushMessage) {
+				t.Errorf("DispatchPushMessage() error = %v, want ErrInvalidPushMessage", err)
+			}
+			if len(recorder.method) != 0 {
+				t.Errorf("%s is called for an invalid message", recorder.method)
+			}
+		})
+	}
+}
+
+func TestDispatchPushMessageHandlerError(t *testing.T) {
+	handlerErr := errors.New("handle failed")
+	handler := &recordingPushHandler{err: handlerErr}
+
+	if err := DispatchPushMessage(context.Background(), []byte(testDeviceMessage), handler); err != handlerErr {
+		t.Errorf("DispatchPushMessage() error = %v, want the handler error", err)
+	}
+}
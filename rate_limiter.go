@@ -0,0 +1,163 @@
+package iot
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ApiGroup 平台按照API分组进行流控，同一个分组内的API共享配额
+type ApiGroup string
+
+const (
+	ApiGroupProduct     ApiGroup = "product"
+	ApiGroupDevice      ApiGroup = "device"
+	ApiGroupMessage     ApiGroup = "message"
+	ApiGroupCommand     ApiGroup = "command"
+	ApiGroupProperty    ApiGroup = "property"
+	ApiGroupShadow      ApiGroup = "shadow"
+	ApiGroupAmqp        ApiGroup = "amqp"
+	ApiGroupRoutingRule ApiGroup = "routing_rule"
+	ApiGroupDeviceGroup ApiGroup = "device_group"
+	ApiGroupTag         ApiGroup = "tag"
+	ApiGroupApplication ApiGroup = "application"
+	ApiGroupBatchTask   ApiGroup = "batch_task"
+	ApiGroupCertificate ApiGroup = "certificate"
+)
+
+// RateLimitMode 超过配额时的处理方式
+type RateLimitMode int
+
+const (
+	// 等待直到获得令牌或者ctx结束
+	RateLimitBlock RateLimitMode = iota
+	// 立即返回ErrRateLimited
+	RateLimitFailFast
+)
+
+// 客户端流控拒绝请求时返回的错误，IsThrottled对该错误同样返回true
+var ErrRateLimited = errors.New("client side rate limit exceeded")
+
+// RateLimit 令牌桶的配置，Rate为每秒请求数，Burst为允许的突发请求数，默认等于Rate
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// 令牌桶，令牌可以透支，透支的请求按顺序等待
+type tokenBucket struct {
+	lock   sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	burst := float64(limit.Burst)
+	if burst < 1 {
+		burst = limit.Rate
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &tokenBucket{
+		rate:   limit.Rate,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+func (b *tokenBucket) advance(now time.Time) {
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+}
+
+// 取走一个令牌，返回需要等待的时间；failFast为true并且需要等待时不取令牌
+func (b *tokenBucket) take(failFast bool) (time.Duration, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.advance(time.Now())
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+	if failFast {
+		return 0, false
+	}
+
+	b.tokens--
+	return time.Duration(-b.tokens / b.rate * float64(time.Second)), true
+}
+
+// 等待被取消时归还令牌
+func (b *tokenBucket) giveBack() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.tokens++
+}
+
+func (b *tokenBucket) wait(ctx context.Context, mode RateLimitMode) error {
+	delay, ok := b.take(mode == RateLimitFailFast)
+	if !ok {
+		return ErrRateLimited
+	}
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		b.giveBack()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type rateLimiter struct {
+	mode    RateLimitMode
+	buckets map[ApiGroup]*tokenBucket
+}
+
+// 没有配置任何分组时返回nil，不做流控
+func newRateLimiter(limits map[ApiGroup]RateLimit, mode RateLimitMode) *rateLimiter {
+	buckets := map[ApiGroup]*tokenBucket{}
+	for group, limit := range limits {
+		if limit.Rate > 0 {
+			buckets[group] = newTokenBucket(limit)
+		}
+	}
+	if len(buckets) == 0 {
+		return nil
+	}
+
+	return &rateLimiter{
+		mode:    mode,
+		buckets: buckets,
+	}
+}
+
+func (l *rateLimiter) wait(ctx context.Context, group ApiGroup) error {
+	if l == nil {
+		return nil
+	}
+
+	bucket, ok := l.buckets[group]
+	if !ok {
+		return nil
+	}
+
+	return bucket.wait(ctx, l.mode)
+}
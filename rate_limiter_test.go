@@ -0,0 +1,164 @@
+package iot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketRefill(t *testing.T) {
+	bucket := newTokenBucket(RateLimit{Rate: 10, Burst: 3})
+	start := bucket.last
+
+	for i := 0; i < 3; i++ {
+		if _, ok := bucket.take(true); !ok {
+			t.Fatalf("take %d within burst failed", i+1)
+		}
+	}
+	if _, ok := bucket.take(true); ok {
+		t.Fatal("take beyond burst succeeded")
+	}
+
+	bucket.last = start
+	bucket.advance(start.Add(200 * time.Millisecond))
+	if bucket.tokens < 1.99 || bucket.tokens > 2.01 {
+		t.Errorf("tokens after 200ms = %v, want 2", bucket.tokens)
+	}
+
+	bucket.advance(start.Add(10 * time.Second))
+	if bucket.tokens != 3 {
+		t.Errorf("tokens after 10s = %v, want burst 3", bucket.tokens)
+	}
+}
+
+func TestTokenBucketDefaultBurst(t *testing.T) {
+	for _, tt := range []struct {
+		limit RateLimit
+		want  float64
+	}{
+		{RateLimit{Rate: 5}, 5},
+		{RateLimit{Rate: 5, Burst: 2}, 2},
+		{RateLimit{Rate: 0.5}, 1},
+	} {
+		if got := newTokenBucket(tt.limit).burst; got != tt.want {
+			t.Errorf("burst of %+v = %v, want %v", tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestTokenBucketBlock(t *testing.T) {
+	bucket := newTokenBucket(RateLimit{Rate: 20, Burst: 1})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := bucket.wait(context.Background(), RateLimitBlock); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 第一个请求使用突发的令牌，后两个请求各等待50ms
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("3 requests at 20/s with burst 1 took %v, want about 100ms", elapsed)
+	}
+}
+
+func TestTokenBucketFailFast(t *testing.T) {
+	bucket := newTokenBucket(RateLimit{Rate: 1, Burst: 1})
+
+	if err := bucket.wait(context.Background(), RateLimitFailFast); err != nil {
+		t.Fatal(err)
+	}
+	err := bucket.wait(context.Background(), RateLimitFailFast)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("wait() error = %v, want ErrRateLimited", err)
+	}
+
+	// 快速失败不透支令牌
+	if bucket.tokens < 0 {
+		t.Errorf("tokens = %v, fail fast must not borrow tokens", bucket.tokens)
+	}
+}
+
+func TestTokenBucketContextCanceled(t *testing.T) {
+	bucket := newTokenBucket(RateLimit{Rate: 0.1, Burst: 1})
+	if err := bucket.wait(context.Background(), RateLimitBlock); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := bucket.wait(ctx, RateLimitBlock); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("wait() error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("canceled wait returned after %v", elapsed)
+	}
+
+	// 取消的请求归还透支的令牌，不影响后续请求的等待时间
+	bucket.lock.Lock()
+	tokens := bucket.tokens
+	bucket.lock.Unlock()
+	if tokens < -0.01 {
+		t.Errorf("tokens = %v, canceled wait should give the token back", tokens)
+	}
+}
+
+func TestRateLimiterGroups(t *testing.T) {
+	if newRateLimiter(nil, RateLimitBlock) != nil || newRateLimiter(map[ApiGroup]RateLimit{ApiGroupDevice: {}}, RateLimitBlock) != nil {
+		t.Error("rate limiter without valid limits should be nil")
+	}
+
+	limiter := newRateLimiter(map[ApiGroup]RateLimit{
+		ApiGroupDevice:  {Rate: 1, Burst: 1},
+		ApiGroupMessage: {Rate: 1, Burst: 1},
+	}, RateLimitFailFast)
+
+	ctx := context.Background()
+	if err := limiter.wait(ctx, ApiGroupDevice); err != nil {
+		t.Fatal(err)
+	}
+	if err := limiter.wait(ctx, ApiGroupDevice); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("second device request error = %v, want ErrRateLimited", err)
+	}
+	if err := limiter.wait(ctx, ApiGroupMessage); err != nil {
+		t.Errorf("message group should not share the device quota: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := limiter.wait(ctx, ApiGroupProduct); err != nil {
+			t.Fatalf("unconfigured group should not be limited: %v", err)
+		}
+	}
+}
+
+func TestIsThrottledRateLimited(t *testing.T) {
+	if !IsThrottled(ErrRateLimited) || !IsThrottled(fmt.Errorf("wrapped: %w", ErrRateLimited)) {
+		t.Error("IsThrottled(ErrRateLimited) = false")
+	}
+}
+
+func TestClientRateLimitFailFast(t *testing.T) {
+	server, count := newStatusServer(http.StatusOK, nil)
+	defer server.Close()
+
+	client := CreateSyncIotApplicationClient(*NewApplicationOptions().WithEndpoint(server.URL).
+		SetProjectId("project").SetToken("token").
+		SetRateLimit(ApiGroupDevice, 0.1, 1).
+		SetRateLimitMode(RateLimitFailFast))
+
+	if _, err := client.ShowDevice("device"); err != nil {
+		t.Fatal(err)
+	}
+	_, err := client.ShowDevice("device")
+	if !errors.Is(err, ErrRateLimited) || !IsThrottled(err) {
+		t.Errorf("error = %v, want ErrRateLimited", err)
+	}
+	if got := atomic.LoadInt32(count); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
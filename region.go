@@ -0,0 +1,260 @@
+package iot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultRegion = "cn-north-4"
+
+// Region 华为云区域，Endpoint为基础版实例的接入地址，标准版和企业版实例使用控制台上的应用侧接入地址
+type Region struct {
+	Id          string
+	Endpoint    string
+	IamEndpoint string
+}
+
+func newRegion(id string) *Region {
+	return &Region{
+		Id:          id,
+		Endpoint:    "https://iotda." + id + ".myhuaweicloud.com",
+		IamEndpoint: "https://iam." + id + ".myhuaweicloud.com",
+	}
+}
+
+var (
+	regionLock = sync.RWMutex{}
+	regions    = map[string]*Region{
+		"cn-north-4":     newRegion("cn-north-4"),
+		"cn-north-1":     newRegion("cn-north-1"),
+		"cn-east-3":      newRegion("cn-east-3"),
+		"cn-south-1":     newRegion("cn-south-1"),
+		"ap-southeast-1": newRegion("ap-southeast-1"),
+		"ap-southeast-2": newRegion("ap-southeast-2"),
+		"ap-southeast-3": newRegion("ap-southeast-3"),
+		"af-south-1":     newRegion("af-south-1"),
+	}
+)
+
+// LookupRegion 从内置的区域目录中查找区域
+func LookupRegion(id string) (*Region, bool) {
+	regionLock.RLock()
+	defer regionLock.RUnlock()
+
+	region, ok := regions[id]
+	return region, ok
+}
+
+// RegisterRegion 添加或者覆盖区域目录中的区域，用于SDK尚未内置的新区域或者专属云
+func RegisterRegion(region *Region) {
+	regionLock.Lock()
+	defer regionLock.Unlock()
+
+	regions[region.Id] = region
+}
+
+// 区域目录中不存在时按照华为云公有云的域名规则生成地址
+func resolveRegion(id string) *Region {
+	if region, ok := LookupRegion(id); ok {
+		return region
+	}
+
+	return newRegion(id)
+}
+
+// 接入地址的优先级：Endpoint > ServerAddress > Region，都没有设置时使用cn-north-4
+func resolveEndpoint(options ApplicationOptions) (string, error) {
+	if len(options.Endpoint) != 0 {
+		return normalizeEndpoint(options.Endpoint, 0)
+	}
+
+	if len(options.ServerAddress) != 0 {
+		return normalizeEndpoint(options.ServerAddress, serverPort(options))
+	}
+
+	region := options.Region
+	if len(region) == 0 {
+		region = defaultRegion
+	}
+
+	return resolveRegion(region).Endpoint, nil
+}
+
+// 默认端口443只用于https，避免http://127.0.0.1被解析为http://127.0.0.1:443
+func serverPort(options ApplicationOptions) int {
+	if options.serverPortSet || options.ServerPort != defaultServerPort {
+		return options.ServerPort
+	}
+
+	if strings.HasPrefix(strings.ToLower(options.ServerAddress), "http://") {
+		return 0
+	}
+
+	return options.ServerPort
+}
+
+// 没有协议时使用https，地址中没有端口并且port大于0时使用port
+func normalizeEndpoint(endpoint string, port int) (string, error) {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint %s: %w", endpoint, err)
+	}
+	if len(u.Host) == 0 {
+		return "", fmt.Errorf("invalid endpoint %s: host is empty", endpoint)
+	}
+
+	if len(u.Port()) == 0 && port > 0 {
+		u.Host = u.Host + ":" + strconv.Itoa(port)
+	}
+
+	return strings.TrimSuffix(u.Scheme+"://"+u.Host+u.Path, "/"), nil
+}
+
+// 没有设置超时时间时解析项目ID的超时时间
+const projectIdResolveTimeout = 30 * time.Second
+
+type projectIdResolution struct {
+	done      chan struct{}
+	projectId string
+	err       error
+}
+
+// 没有配置ProjectId时，优先使用IamTokenProvider获取Token时返回的项目，其次根据区域从IAM查询，结果会被缓存。
+// 解析时不持有锁，并发的调用共享同一次解析，每个调用只等待到自己的ctx结束，失败的结果不会被缓存
+func (client *syncClient) resolveProjectId(ctx context.Context) (string, error) {
+	if len(client.options.ProjectId) != 0 {
+		return client.options.ProjectId, nil
+	}
+
+	client.projectLock.Lock()
+	if len(client.projectId) != 0 {
+		projectId := client.projectId
+		client.projectLock.Unlock()
+		return projectId, nil
+	}
+
+	resolution := client.projectResolving
+	if resolution == nil {
+		resolution = &projectIdResolution{done: make(chan struct{})}
+		client.projectResolving = resolution
+		// 第一个调用者的ctx被取消时不影响其他等待的调用
+		go client.runProjectIdResolution(resolution)
+	}
+	client.projectLock.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case <-resolution.done:
+		return resolution.projectId, resolution.err
+	}
+}
+
+func (client *syncClient) runProjectIdResolution(resolution *projectIdResolution) {
+	timeout := client.options.Timeout
+	if timeout <= 0 {
+		timeout = projectIdResolveTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	projectId, err := client.deriveProjectId(ctx)
+
+	client.projectLock.Lock()
+	if err == nil {
+		client.projectId = projectId
+	}
+	client.projectResolving = nil
+	client.projectLock.Unlock()
+
+	resolution.projectId = projectId
+	resolution.err = err
+	close(resolution.done)
+}
+
+func (client *syncClient) deriveProjectId(ctx context.Context) (string, error) {
+	var projectId string
+	if provider := iamTokenProviderOf(client.credentials); provider != nil {
+		if _, err := provider.Token(ctx); err != nil {
+			return "", err
+		}
+		projectId = provider.ProjectId()
+	}
+
+	if len(projectId) == 0 && len(client.options.Region) != 0 {
+		return client.queryProjectId(ctx, resolveRegion(client.options.Region))
+	}
+
+	if len(projectId) == 0 {
+		return "", fmt.Errorf("%w: project id is not configured and can not be derived from region", ErrInvalidConfig)
+	}
+
+	return projectId, nil
+}
+
+// 返回配置的或者已经解析出的项目ID，不会触发解析，也不会等待正在进行的解析
+func (client *syncClient) currentProjectId() string {
+	if len(client.options.ProjectId) != 0 {
+		return client.options.ProjectId
+	}
+
+	client.projectLock.Lock()
+	defer client.projectLock.Unlock()
+
+	return client.projectId
+}
+
+// 使用Client的凭证从IAM查询区域对应的项目ID
+func (client *syncClient) queryProjectId(ctx context.Context, region *Region) (string, error) {
+	response, err := client.client.R().
+		SetContext(ctx).
+		SetQueryParam("name", region.Id).
+		Get(region.IamEndpoint + "/v3/projects")
+	if err != nil {
+		return "", err
+	}
+
+	if response.StatusCode() != http.StatusOK {
+		return "", convertIamResponseToApplicationError(response)
+	}
+
+	projects := &struct {
+		Projects []iamProject `json:"projects"`
+	}{}
+	err = json.Unmarshal(response.Body(), projects)
+	if err != nil {
+		return "", fmt.Errorf("decode iam projects failed: %w", err)
+	}
+
+	for _, project := range projects.Projects {
+		if project.Name == region.Id {
+			return project.Id, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: no iam project found for region %s", ErrInvalidConfig, region.Id)
+}
+
+func iamTokenProviderOf(provider CredentialsProvider) *IamTokenProvider {
+	switch p := provider.(type) {
+	case *IamTokenProvider:
+		return p
+	case *Credentials:
+		if !p.UseAkSk {
+			return p.TokenProvider
+		}
+	}
+
+	return nil
+}
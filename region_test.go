@@ -0,0 +1,229 @@
+package iot
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResolveEndpoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		options *ApplicationOptions
+		want    string
+	}{
+		{"default region", NewApplicationOptions(), "https://iotda.cn-north-4.myhuaweicloud.com"},
+		{"region", NewApplicationOptions().WithRegion("cn-east-3"), "https://iotda.cn-east-3.myhuaweicloud.com"},
+		{"unknown region", NewApplicationOptions().WithRegion("cn-new-1"), "https://iotda.cn-new-1.myhuaweicloud.com"},
+		{"server address over region",
+			NewApplicationOptions().WithRegion("cn-east-3").AddServer("iotda.cn-north-4.myhuaweicloud.com"),
+			"https://iotda.cn-north-4.myhuaweicloud.com:443"},
+		{"endpoint over server address",
+			NewApplicationOptions().AddServer("iotda.cn-north-4.myhuaweicloud.com").WithEndpoint("https://xxx.iotda-app.cn-north-4.myhuaweicloud.com"),
+			"https://xxx.iotda-app.cn-north-4.myhuaweicloud.com"},
+		{"endpoint without scheme", NewApplicationOptions().WithEndpoint("xxx.iotda-app.cn-north-4.myhuaweicloud.com"),
+			"https://xxx.iotda-app.cn-north-4.myhuaweicloud.com"},
+		{"endpoint ignores server port", NewApplicationOptions().WithEndpoint("http://127.0.0.1").AddServerPort(8080),
+			"http://127.0.0.1"},
+		{"endpoint with port and trailing slash", NewApplicationOptions().WithEndpoint("http://127.0.0.1:8080/"),
+			"http://127.0.0.1:8080"},
+		{"endpoint with path", NewApplicationOptions().WithEndpoint("http://127.0.0.1:8080/iot/"),
+			"http://127.0.0.1:8080/iot"},
+		{"server address with port", NewApplicationOptions().AddServer("iotda.cn-north-4.myhuaweicloud.com:8443"),
+			"https://iotda.cn-north-4.myhuaweicloud.com:8443"},
+		{"server address with trailing slash", NewApplicationOptions().AddServer("https://iotda.cn-north-4.myhuaweicloud.com/"),
+			"https://iotda.cn-north-4.myhuaweicloud.com:443"},
+		{"http server address ignores default port", NewApplicationOptions().AddServer("http://127.0.0.1"),
+			"http://127.0.0.1"},
+		{"http server address with explicit port", NewApplicationOptions().AddServer("http://127.0.0.1").AddServerPort(443),
+			"http://127.0.0.1:443"},
+		{"http server address with non default port", &ApplicationOptions{ServerAddress: "http://127.0.0.1", ServerPort: 8080},
+			"http://127.0.0.1:8080"},
+		{"http server address keeps its port", NewApplicationOptions().AddServer("http://127.0.0.1:8080").AddServerPort(9090),
+			"http://127.0.0.1:8080"},
+		{"server address without port", &ApplicationOptions{ServerAddress: "iotda.cn-north-4.myhuaweicloud.com"},
+			"https://iotda.cn-north-4.myhuaweicloud.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveEndpoint(*tt.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("resolveEndpoint() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveEndpointInvalid(t *testing.T) {
+	for _, endpoint := range []string{"http://", "https://[::1", "http://%zz"} {
+		if got, err := resolveEndpoint(*NewApplicationOptions().WithEndpoint(endpoint)); err == nil {
+			t.Errorf("resolveEndpoint(%s) = %s, want error", endpoint, got)
+		}
+	}
+}
+
+func TestRegisterRegion(t *testing.T) {
+	RegisterRegion(&Region{Id: "test-region-1", Endpoint: "https://iotda.example.com", IamEndpoint: "https://iam.example.com"})
+
+	got, err := resolveEndpoint(*NewApplicationOptions().WithRegion("test-region-1"))
+	if err != nil || got != "https://iotda.example.com" {
+		t.Errorf("resolveEndpoint() = %s, %v", got, err)
+	}
+}
+
+func TestResolveProjectIdNotConfigured(t *testing.T) {
+	client := CreateSyncIotApplicationClient(*NewApplicationOptions().AddAk("ak").AddSk("sk").IsUseAkSk(true))
+
+	_, err := client.resolveProjectId(context.Background())
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("resolveProjectId() error = %v, want ErrInvalidConfig", err)
+	}
+}
+
+func TestResolveProjectIdFromIam(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v3/projects" || len(r.Header.Get("Authorization")) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.URL.Query().Get("name") == "iam-region-1" {
+			_, _ = w.Write([]byte(`{"projects":[{"id":"project-1","name":"iam-region-1"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"projects":[]}`))
+	}))
+	defer server.Close()
+
+	for _, id := range []string{"iam-region-1", "iam-region-2"} {
+		RegisterRegion(&Region{Id: id, Endpoint: server.URL, IamEndpoint: server.URL})
+	}
+
+	client := CreateSyncIotApplicationClient(*NewApplicationOptions().WithRegion("iam-region-1").
+		AddAk("ak").AddSk("sk").IsUseAkSk(true))
+	projectId, err := client.resolveProjectId(context.Background())
+	if err != nil || projectId != "project-1" {
+		t.Errorf("resolveProjectId() = %s, %v, want project-1", projectId, err)
+	}
+
+	client = CreateSyncIotApplicationClient(*NewApplicationOptions().WithRegion("iam-region-2").
+		AddAk("ak").AddSk("sk").IsUseAkSk(true))
+	_, err = client.resolveProjectId(context.Background())
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("resolveProjectId() error = %v, want ErrInvalidConfig", err)
+	}
+}
+
+// 阻塞的IAM项目查询接口，release关闭之前不返回
+type blockingProjectsStub struct {
+	*httptest.Server
+	count   int32
+	arrived chan struct{}
+	release chan struct{}
+}
+
+func newBlockingProjectsStub(region string) *blockingProjectsStub {
+	stub := &blockingProjectsStub{arrived: make(chan struct{}, 100), release: make(chan struct{})}
+	stub.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&stub.count, 1)
+		stub.arrived <- struct{}{}
+		<-stub.release
+		_, _ = w.Write([]byte(`{"projects":[{"id":"project-1","name":"` + region + `"}]}`))
+	}))
+	RegisterRegion(&Region{Id: region, Endpoint: stub.URL, IamEndpoint: stub.URL})
+
+	return stub
+}
+
+func TestResolveProjectIdConcurrentFirstCall(t *testing.T) {
+	stub := newBlockingProjectsStub("iam-region-concurrent")
+	defer stub.Close()
+
+	client := CreateSyncIotApplicationClient(*NewApplicationOptions().WithRegion("iam-region-concurrent").
+		AddAk("ak").AddSk("sk").IsUseAkSk(true))
+
+	var wg sync.WaitGroup
+	projectIds := make([]string, 10)
+	errs := make([]error, len(projectIds))
+	for i := range projectIds {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			projectIds[i], errs[i] = client.resolveProjectId(context.Background())
+		}(i)
+	}
+
+	// 解析期间不持有锁，currentProjectId不等待解析
+	<-stub.arrived
+	done := make(chan string)
+	go func() {
+		done <- client.currentProjectId()
+	}()
+	select {
+	case projectId := <-done:
+		if len(projectId) != 0 {
+			t.Errorf("currentProjectId() during resolution = %s, want empty", projectId)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("currentProjectId() waits for the resolution")
+	}
+
+	close(stub.release)
+	wg.Wait()
+
+	for i := range projectIds {
+		if projectIds[i] != "project-1" || errs[i] != nil {
+			t.Errorf("resolveProjectId() = %s, %v, want project-1", projectIds[i], errs[i])
+		}
+	}
+	if count := atomic.LoadInt32(&stub.count); count != 1 {
+		t.Errorf("iam requests = %d, want 1", count)
+	}
+	if client.currentProjectId() != "project-1" {
+		t.Errorf("currentProjectId() = %s, want project-1", client.currentProjectId())
+	}
+}
+
+func TestResolveProjectIdCanceled(t *testing.T) {
+	stub := newBlockingProjectsStub("iam-region-canceled")
+	defer stub.Close()
+
+	client := CreateSyncIotApplicationClient(*NewApplicationOptions().WithRegion("iam-region-canceled").
+		AddAk("ak").AddSk("sk").IsUseAkSk(true))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		_, err := client.resolveProjectId(ctx)
+		errs <- err
+	}()
+
+	<-stub.arrived
+	cancel()
+	select {
+	case err := <-errs:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("resolveProjectId() error = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("resolveProjectId() ignores ctx cancel")
+	}
+
+	// 取消的调用不影响正在进行的解析
+	close(stub.release)
+	projectId, err := client.resolveProjectId(context.Background())
+	if err != nil || projectId != "project-1" {
+		t.Errorf("resolveProjectId() = %s, %v, want project-1", projectId, err)
+	}
+	if count := atomic.LoadInt32(&stub.count); count != 1 {
+		t.Errorf("iam requests = %d, want 1", count)
+	}
+}
@@ -0,0 +1,210 @@
+package iot
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy 请求失败后的重试策略，重试间隔按指数增长并加入随机抖动，平台返回Retry-After时使用Retry-After
+type RetryPolicy struct {
+	// 最大尝试次数，包含第一次请求，1表示不重试
+	MaxAttempts int
+	// 第一次重试之前的等待时间，之后每次乘以Multiplier，最长不超过MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	// 随机抖动的比例，取值0~1，0.2表示等待时间在计算值的±20%之间随机
+	Jitter float64
+
+	// 幂等操作遇到这些状态码或者网络错误时重试，默认为500、502、503、504
+	RetryableStatusCodes []int
+	// 覆盖操作默认的幂等性，key为操作名称，例如CreateDevice。非幂等操作只在平台返回429时重试
+	Idempotent map[string]bool
+
+	// 每次尝试结束后调用，可以用于记录日志或者统计
+	OnAttempt func(attempt RetryAttempt)
+}
+
+// RetryAttempt 一次尝试的结果
+type RetryAttempt struct {
+	Operation string
+	// 从1开始
+	Attempt    int
+	StatusCode int
+	Err        error
+	// 是否还会重试以及重试之前的等待时间
+	WillRetry bool
+	Delay     time.Duration
+}
+
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:          3,
+		InitialBackoff:       100 * time.Millisecond,
+		MaxBackoff:           5 * time.Second,
+		Multiplier:           2,
+		Jitter:               0.2,
+		RetryableStatusCodes: []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+	}
+}
+
+// NoRetryPolicy 不重试
+func NoRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 1,
+	}
+}
+
+// 语义上幂等的POST操作，重复执行结果不变
+var idempotentOperations = map[*operation]bool{
+	opFreezeDevice:             true,
+	opUnFreezeDevice:           true,
+	opDeviceBindTags:           true,
+	opDeviceUnBindTags:         true,
+	opListDeviceByTags:         true,
+	opVerifyDeviceCertificates: true,
+}
+
+// 未设置的字段使用默认值
+func (p RetryPolicy) withDefaults() *RetryPolicy {
+	defaults := DefaultRetryPolicy()
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = defaults.MaxAttempts
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = defaults.InitialBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = defaults.MaxBackoff
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = defaults.Multiplier
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		p.Jitter = defaults.Jitter
+	}
+	if p.RetryableStatusCodes == nil {
+		p.RetryableStatusCodes = defaults.RetryableStatusCodes
+	}
+
+	return &p
+}
+
+func (p *RetryPolicy) idempotent(op *operation) bool {
+	if idempotent, ok := p.Idempotent[op.name]; ok {
+		return idempotent
+	}
+
+	return op.method != http.MethodPost || idempotentOperations[op]
+}
+
+// 429表示请求被流控，没有被平台处理，所有操作都可以重试；5xx和网络错误只重试幂等操作
+func (p *RetryPolicy) retryable(op *operation, response *Response, err error) bool {
+	// 只有发送请求时的网络错误可以重试，构造请求、获取凭证等错误重试也不会成功
+	if err != nil {
+		var urlError *url.Error
+		return errors.As(err, &urlError) && p.idempotent(op)
+	}
+
+	if response.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+
+	for _, code := range p.RetryableStatusCodes {
+		if code == response.StatusCode {
+			return p.idempotent(op)
+		}
+	}
+
+	return false
+}
+
+func (p *RetryPolicy) backoff(attempt int, response *Response) time.Duration {
+	delay := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	if delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+	delay = delay * (1 + p.Jitter*(2*rand.Float64()-1))
+
+	if retryAfter := parseRetryAfter(response); retryAfter > time.Duration(delay) {
+		return retryAfter
+	}
+
+	return time.Duration(delay)
+}
+
+// Retry-After可以是秒数或者HTTP日期
+func parseRetryAfter(response *Response) time.Duration {
+	if response == nil {
+		return 0
+	}
+
+	value := response.Header.Get("Retry-After")
+	if len(value) == 0 {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+
+	return 0
+}
+
+// 按照重试策略执行请求，返回最后一次尝试的结果。上传文件的请求体无法重复读取，不重试
+func (client *syncClient) executeWithRetry(ctx context.Context, op *operation, request *Request) (*Response, error) {
+	policy := client.retryPolicy
+
+	for attempt := 1; ; attempt++ {
+		request.attempts = attempt
+		response, err := client.attempt(ctx, op, request)
+
+		result := RetryAttempt{
+			Operation: op.name,
+			Attempt:   attempt,
+			Err:       err,
+		}
+		if response != nil {
+			result.StatusCode = response.StatusCode
+		}
+
+		if attempt < policy.MaxAttempts && request.file == nil && ctx.Err() == nil && policy.retryable(op, response, err) {
+			// 等待时间超过ctx的截止时间时不再重试
+			delay := policy.backoff(attempt, response)
+			if deadline, ok := ctx.Deadline(); !ok || time.Now().Add(delay).Before(deadline) {
+				result.WillRetry = true
+				result.Delay = delay
+			}
+		}
+
+		if policy.OnAttempt != nil {
+			policy.OnAttempt(result)
+		}
+
+		if result.WillRetry {
+			client.logger.Info("retry request", "operation", op.name, "attempt", attempt, "status_code", result.StatusCode, "error", err, "delay", result.Delay)
+		}
+
+		if !result.WillRetry {
+			return response, err
+		}
+
+		timer := time.NewTimer(result.Delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package iot
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+
+	for attempt, base := range map[int]time.Duration{
+		1: 100 * time.Millisecond,
+		2: 200 * time.Millisecond,
+		3: 400 * time.Millisecond,
+		4: 800 * time.Millisecond,
+		5: time.Second,
+		9: time.Second,
+	} {
+		min, max := time.Duration(float64(base)*0.8), time.Duration(float64(base)*1.2)
+		for i := 0; i < 100; i++ {
+			if delay := policy.backoff(attempt, nil); delay < min || delay > max {
+				t.Fatalf("backoff(%d) = %v, want between %v and %v", attempt, delay, min, max)
+			}
+		}
+	}
+
+	policy.Jitter = 0
+	if delay := policy.backoff(3, nil); delay != 400*time.Millisecond {
+		t.Errorf("backoff without jitter = %v, want 400ms", delay)
+	}
+}
+
+func TestBackoffRetryAfter(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}
+
+	response := &Response{Header: http.Header{"Retry-After": {"3"}}}
+	if delay := policy.backoff(1, response); delay != 3*time.Second {
+		t.Errorf("backoff with Retry-After = %v, want 3s", delay)
+	}
+
+	// Retry-After小于计算的等待时间时使用计算值
+	response = &Response{Header: http.Header{"Retry-After": {"1"}}}
+	if delay := policy.backoff(10, response); delay != time.Second {
+		t.Errorf("backoff with short Retry-After = %v, want 1s", delay)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if got := parseRetryAfter(nil); got != 0 {
+		t.Errorf("parseRetryAfter(nil) = %v", got)
+	}
+
+	tests := []struct {
+		value    string
+		min, max time.Duration
+	}{
+		{"", 0, 0},
+		{"5", 5 * time.Second, 5 * time.Second},
+		{"0", 0, 0},
+		{"-1", 0, 0},
+		{"soon", 0, 0},
+		{time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat), 8 * time.Second, 10 * time.Second},
+		{time.Now().Add(10 * time.Second).UTC().Format(time.RFC850), 8 * time.Second, 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		got := parseRetryAfter(&Response{Header: http.Header{"Retry-After": {tt.value}}})
+		if got < tt.min || got > tt.max {
+			t.Errorf("parseRetryAfter(%q) = %v, want between %v and %v", tt.value, got, tt.min, tt.max)
+		}
+	}
+
+	if got := parseRetryAfter(&Response{Header: http.Header{"Retry-After": {time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)}}}); got > 0 {
+		t.Errorf("parseRetryAfter(past date) = %v, want <= 0", got)
+	}
+}
+
+// 返回固定状态码的服务器，记录收到的请求数
+func newStatusServer(statusCode int, header http.Header) (*httptest.Server, *int32) {
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+		for key, values := range header {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(statusCode)
+		_, _ = w.Write([]byte(`{"error_code":"IOTDA.000000","error_msg":"error"}`))
+	}))
+
+	return server, &count
+}
+
+func newRetryTestClient(endpoint string, policy *RetryPolicy) *syncClient {
+	return CreateSyncIotApplicationClient(*NewApplicationOptions().WithEndpoint(endpoint).
+		SetProjectId("project").SetToken("token").SetRetryPolicy(policy))
+}
+
+func fastRetryPolicy() *RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	policy.MaxBackoff = 5 * time.Millisecond
+	return policy
+}
+
+func TestRetryByOperation(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		idempotent map[string]bool
+		create     bool
+		wantCount  int32
+	}{
+		{"429 retried for idempotent operation", http.StatusTooManyRequests, nil, false, 3},
+		{"429 retried for non idempotent operation", http.StatusTooManyRequests, nil, true, 3},
+		{"503 retried for idempotent operation", http.StatusServiceUnavailable, nil, false, 3},
+		{"503 not retried for non idempotent operation", http.StatusServiceUnavailable, nil, true, 1},
+		{"501 not retried", http.StatusNotImplemented, nil, false, 1},
+		{"400 not retried", http.StatusBadRequest, nil, false, 1},
+		{"override create device as idempotent", http.StatusServiceUnavailable, map[string]bool{"CreateDevice": true}, true, 3},
+		{"override show device as non idempotent", http.StatusServiceUnavailable, map[string]bool{"ShowDevice": false}, false, 1},
+		{"429 retried even if overridden", http.StatusTooManyRequests, map[string]bool{"ShowDevice": false}, false, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, count := newStatusServer(tt.statusCode, nil)
+			defer server.Close()
+
+			policy := fastRetryPolicy()
+			policy.Idempotent = tt.idempotent
+			client := newRetryTestClient(server.URL, policy)
+
+			var err error
+			if tt.create {
+				_, err = client.CreateDevice(CreateDeviceRequest{})
+			} else {
+				_, err = client.ShowDevice("device")
+			}
+
+			var ae *ApplicationError
+			if !errors.As(err, &ae) || ae.StatusCode != tt.statusCode {
+				t.Errorf("error = %v, want status code %d", err, tt.statusCode)
+			}
+			if got := atomic.LoadInt32(count); got != tt.wantCount {
+				t.Errorf("requests = %d, want %d", got, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestRetryNetworkError(t *testing.T) {
+	server, _ := newStatusServer(http.StatusOK, nil)
+	server.Close()
+
+	for _, create := range []bool{false, true} {
+		var attempts []RetryAttempt
+		policy := fastRetryPolicy()
+		policy.OnAttempt = func(attempt RetryAttempt) {
+			attempts = append(attempts, attempt)
+		}
+		client := newRetryTestClient(server.URL, policy)
+
+		var err error
+		if create {
+			_, err = client.CreateDevice(CreateDeviceRequest{})
+		} else {
+			_, err = client.ShowDevice("device")
+		}
+		if err == nil {
+			t.Fatal("expected network error")
+		}
+
+		want := 3
+		if create {
+			want = 1
+		}
+		if len(attempts) != want {
+			t.Errorf("create = %v, attempts = %d, want %d", create, len(attempts), want)
+		}
+	}
+}
+
+func TestRetryOnAttempt(t *testing.T) {
+	server, _ := newStatusServer(http.StatusServiceUnavailable, nil)
+	defer server.Close()
+
+	var attempts []RetryAttempt
+	policy := fastRetryPolicy()
+	policy.OnAttempt = func(attempt RetryAttempt) {
+		attempts = append(attempts, attempt)
+	}
+	_, _ = newRetryTestClient(server.URL, policy).ShowDevice("device")
+
+	if len(attempts) != 3 {
+		t.Fatalf("attempts = %d, want 3", len(attempts))
+	}
+	for i, attempt := range attempts {
+		last := i == len(attempts)-1
+		if attempt.Operation != "ShowDevice" || attempt.Attempt != i+1 || attempt.StatusCode != http.StatusServiceUnavailable ||
+			attempt.Err != nil || attempt.WillRetry == last || (attempt.Delay > 0) == last {
+			t.Errorf("attempt %d = %+v", i+1, attempt)
+		}
+	}
+}
+
+func TestRetryContextCanceledDuringBackoff(t *testing.T) {
+	server, count := newStatusServer(http.StatusTooManyRequests, http.Header{"Retry-After": {"10"}})
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := fastRetryPolicy()
+	policy.OnAttempt = func(attempt RetryAttempt) {
+		if attempt.WillRetry {
+			cancel()
+		}
+	}
+
+	start := time.Now()
+	_, err := newRetryTestClient(server.URL, policy).ShowDeviceCtx(ctx, "device")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("canceled request returned after %v", elapsed)
+	}
+	if got := atomic.LoadInt32(count); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+func TestRetryDelayBeyondDeadline(t *testing.T) {
+	server, count := newStatusServer(http.StatusTooManyRequests, http.Header{"Retry-After": {"10"}})
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := newRetryTestClient(server.URL, fastRetryPolicy()).ShowDeviceCtx(ctx, "device")
+	if !IsThrottled(err) {
+		t.Errorf("error = %v, want throttled", err)
+	}
+	if got := atomic.LoadInt32(count); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+func TestNoRetryPolicy(t *testing.T) {
+	server, count := newStatusServer(http.StatusServiceUnavailable, nil)
+	defer server.Close()
+
+	_, _ = newRetryTestClient(server.URL, NoRetryPolicy()).ShowDevice("device")
+	if got := atomic.LoadInt32(count); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
@@ -0,0 +1,177 @@
+package iot
+
+// 数据流转规则的资源类型
+const (
+	RuleResourceDevice              = "device"
+	RuleResourceDeviceProperty      = "device.property"
+	RuleResourceDeviceMessage       = "device.message"
+	RuleResourceDeviceMessageStatus = "device.message.status"
+	RuleResourceDeviceStatus        = "device.status"
+	RuleResourceDeviceCommandStatus = "device.command.status"
+	RuleResourceBatchTask           = "batchtask"
+	RuleResourceProduct             = "product"
+	RuleResourceOta                 = "device.ota"
+)
+
+// 数据流转规则的触发事件
+const (
+	RuleEventCreate       = "create"
+	RuleEventDelete       = "delete"
+	RuleEventUpdate       = "update"
+	RuleEventReport       = "report"
+	RuleEventStatusUpdate = "statusUpdate"
+)
+
+// 数据流转规则的生效范围
+const (
+	RuleAppTypeApp    = "APP"
+	RuleAppTypeGlobal = "GLOBAL"
+)
+
+// 数据流转规则动作的转发通道
+const (
+	ChannelHttpForwarding     = "HTTP_FORWARDING"
+	ChannelDisForwarding      = "DIS_FORWARDING"
+	ChannelObsForwarding      = "OBS_FORWARDING"
+	ChannelAmqpForwarding     = "AMQP_FORWARDING"
+	ChannelDmsKafkaForwarding = "DMS_KAFKA_FORWARDING"
+)
+
+// 规则的主题，由资源类型和触发事件组成
+type RoutingRuleSubject struct {
+	Resource string `json:"resource"`
+	Event    string `json:"event"`
+}
+
+// 数据流转规则管理-创建规则
+// Select和Where为平台支持的类SQL语句，例如：Select为"*"，Where为"notify_data.body.status='ONLINE'"
+type CreateRoutingRuleRequest struct {
+	RuleName    string             `json:"rule_name,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Subject     RoutingRuleSubject `json:"subject"`
+	AppType     string             `json:"app_type,omitempty"`
+	AppID       string             `json:"app_id,omitempty"`
+	Select      string             `json:"select,omitempty"`
+	Where       string             `json:"where,omitempty"`
+}
+
+type UpdateRoutingRuleRequest struct {
+	RuleName    string `json:"rule_name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Select      string `json:"select,omitempty"`
+	Where       string `json:"where,omitempty"`
+	Active      *bool  `json:"active,omitempty"`
+}
+
+type RoutingRuleResponse struct {
+	RuleID      string             `json:"rule_id"`
+	RuleName    string             `json:"rule_name"`
+	Description string             `json:"description"`
+	Subject     RoutingRuleSubject `json:"subject"`
+	AppType     string             `json:"app_type"`
+	AppID       string             `json:"app_id"`
+	Select      string             `json:"select"`
+	Where       string             `json:"where"`
+	Active      bool               `json:"active"`
+}
+
+type ListRoutingRulesRequest struct {
+	Resource string `json:"resource,omitempty"`
+	Event    string `json:"event,omitempty"`
+	AppType  string `json:"app_type,omitempty"`
+	AppId    string `json:"app_id,omitempty"`
+	RuleName string `json:"rule_name,omitempty"`
+	Active   *bool  `json:"active,omitempty"`
+	Limit    int    `json:"limit,omitempty"`
+	Marker   string `json:"marker,omitempty"`
+	Offset   int    `json:"offset,omitempty"`
+}
+
+type ListRoutingRulesResponse struct {
+	Rules  []RoutingRuleResponse `json:"rules"`
+	Count  int                   `json:"count"`
+	Marker string                `json:"marker"`
+}
+
+// 数据流转规则管理-规则动作
+type ChannelDetail struct {
+	HttpForwarding     *HttpForwarding     `json:"http_forwarding,omitempty"`
+	DisForwarding      *DisForwarding      `json:"dis_forwarding,omitempty"`
+	ObsForwarding      *ObsForwarding      `json:"obs_forwarding,omitempty"`
+	AmqpForwarding     *AmqpForwarding     `json:"amqp_forwarding,omitempty"`
+	DmsKafkaForwarding *DmsKafkaForwarding `json:"dms_kafka_forwarding,omitempty"`
+}
+
+type HttpForwarding struct {
+	Url string `json:"url"`
+}
+
+type DisForwarding struct {
+	RegionName string `json:"region_name"`
+	ProjectID  string `json:"project_id"`
+	StreamName string `json:"stream_name,omitempty"`
+	StreamID   string `json:"stream_id,omitempty"`
+}
+
+type ObsForwarding struct {
+	RegionName string `json:"region_name"`
+	ProjectID  string `json:"project_id"`
+	BucketName string `json:"bucket_name"`
+	Location   string `json:"location,omitempty"`
+	FilePath   string `json:"file_path,omitempty"`
+}
+
+type AmqpForwarding struct {
+	QueueName string `json:"queue_name"`
+}
+
+type DmsKafkaForwarding struct {
+	RegionName       string       `json:"region_name"`
+	ProjectID        string       `json:"project_id"`
+	Addresses        []NetAddress `json:"addresses"`
+	Topic            string       `json:"topic"`
+	Username         string       `json:"username,omitempty"`
+	Password         string       `json:"password,omitempty"`
+	Mechanism        string       `json:"mechanism,omitempty"`
+	SecurityProtocol string       `json:"security_protocol,omitempty"`
+}
+
+type NetAddress struct {
+	Ip     string `json:"ip,omitempty"`
+	Port   int    `json:"port,omitempty"`
+	Domain string `json:"domain,omitempty"`
+}
+
+type CreateRuleActionRequest struct {
+	RuleID        string        `json:"rule_id"`
+	Channel       string        `json:"channel"`
+	ChannelDetail ChannelDetail `json:"channel_detail"`
+}
+
+type UpdateRuleActionRequest struct {
+	Channel       string        `json:"channel,omitempty"`
+	ChannelDetail ChannelDetail `json:"channel_detail"`
+}
+
+type RuleActionResponse struct {
+	ActionID      string        `json:"action_id"`
+	RuleID        string        `json:"rule_id"`
+	Channel       string        `json:"channel"`
+	ChannelDetail ChannelDetail `json:"channel_detail"`
+}
+
+type ListRuleActionsRequest struct {
+	RuleID  string `json:"rule_id,omitempty"`
+	Channel string `json:"channel,omitempty"`
+	AppType string `json:"app_type,omitempty"`
+	AppId   string `json:"app_id,omitempty"`
+	Limit   int    `json:"limit,omitempty"`
+	Marker  string `json:"marker,omitempty"`
+	Offset  int    `json:"offset,omitempty"`
+}
+
+type ListRuleActionsResponse struct {
+	Actions []RuleActionResponse `json:"actions"`
+	Count   int                  `json:"count"`
+	Marker  string               `json:"marker"`
+}
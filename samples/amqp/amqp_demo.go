@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	iot "huaweicloud-iot-application-sdk-go"
+	"os"
+	"os/signal"
 )
 
 func main() {
@@ -29,4 +32,45 @@ func main() {
 
 	fmt.Println(queues.Queues)
 
+	accessCode, err := client.CreateAccessCode("AMQP")
+	if err != nil {
+		fmt.Println(err)
+		panic(1)
+	}
+
+	consumerOptions := iot.NewAmqpConsumerOptions().
+		SetAddress("amqps://xxx.iot-amqps.cn-north-4.myhuaweicloud.com:5671").
+		SetAccessCode(accessCode).
+		SetQueueName("DefaultQueue")
+
+	consumer, err := iot.NewAmqpConsumer(*consumerOptions, iot.NewAmqpPushMessageHandler(&pushMessageHandler{}))
+	if err != nil {
+		fmt.Println(err)
+		panic(1)
+	}
+
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt)
+		<-signals
+		_ = consumer.Close()
+	}()
+
+	fmt.Println(consumer.Run(context.Background()))
+}
+
+type pushMessageHandler struct {
+	iot.BasePushMessageHandler
+}
+
+func (h *pushMessageHandler) OnDevicePropertyReport(ctx context.Context, message *iot.PushMessage, body *iot.DevicePropertyReport) error {
+	for _, service := range body.Services {
+		fmt.Printf("device %s reported service %s: %v\n", message.NotifyData.Header.DeviceID, service.ServiceID, service.Properties)
+	}
+	return nil
+}
+
+func (h *pushMessageHandler) OnDeviceStatusChange(ctx context.Context, message *iot.PushMessage, body *iot.DeviceStatusChange) error {
+	fmt.Printf("device %s is %s\n", message.NotifyData.Header.DeviceID, body.Status)
+	return nil
 }
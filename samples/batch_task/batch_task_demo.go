@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	iot "huaweicloud-iot-application-sdk-go"
+	"os"
+	"time"
+)
+
+func main() {
+	options := iot.ApplicationOptions{
+		ServerPort:    443,
+		ServerAddress: "iotda.cn-north-4.myhuaweicloud.com",
+		InstanceId:    "",
+		ProjectId:     "25e1be7c374749e9b6a25bc4ad53393a",
+
+		Credential: &iot.Credentials{
+			Ak:      "xxx",
+			Sk:      "xxx",
+			UseAkSk: true,
+		},
+	}
+
+	client := iot.CreateSyncIotApplicationClient(options)
+
+	// 批量注册设备的文件使用控制台提供的模板填写
+	file, err := os.Open("BatchCreateDevices_Template.xlsx")
+	if err != nil {
+		fmt.Println(err)
+		panic(1)
+	}
+	defer file.Close()
+
+	uploadResponse, err := client.UploadBatchTaskFile("BatchCreateDevices_Template.xlsx", file)
+	if err != nil {
+		fmt.Println(err)
+		panic(1)
+	}
+
+	task, err := client.CreateBatchTask(iot.CreateBatchTaskRequest{
+		AppID:       "a04cafa7d2714e9eaff4fe9b210ccec0",
+		TaskName:    "create-sub-devices",
+		TaskType:    iot.BatchTaskCreateDevices,
+		TargetsFile: uploadResponse.FileID,
+	})
+	if err != nil {
+		fmt.Println(err)
+		panic(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	result, err := iot.WaitBatchTask(ctx, client, task.TaskID, 5*time.Second)
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	if result != nil {
+		fmt.Printf("task status is %s, success %d, fail %d\n", result.Task.Status,
+			result.Task.TaskProgress.Success, result.Task.TaskProgress.Fail)
+		for _, failure := range result.Failures {
+			fmt.Printf("%s failed: %s\n", failure.Target, failure.Error.ErrorMsg)
+		}
+	}
+}
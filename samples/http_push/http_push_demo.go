@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	iot "huaweicloud-iot-application-sdk-go"
+	"net/http"
+)
+
+func main() {
+	options := iot.NewHttpPushReceiverOptions().
+		SetToken("xxx")
+
+	receiver, err := iot.NewHttpPushReceiver(*options, &pushMessageHandler{})
+	if err != nil {
+		fmt.Println(err)
+		panic(1)
+	}
+
+	tlsConfig, err := iot.NewHttpPushTLSConfig("server.crt", "server.key", "")
+	if err != nil {
+		fmt.Println(err)
+		panic(1)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/iot/push", receiver)
+
+	server := &http.Server{
+		Addr:      ":8443",
+		Handler:   mux,
+		TLSConfig: tlsConfig,
+	}
+
+	fmt.Println(server.ListenAndServeTLS("", ""))
+}
+
+type pushMessageHandler struct {
+	iot.BasePushMessageHandler
+}
+
+func (h *pushMessageHandler) OnDeviceMessageReport(ctx context.Context, message *iot.PushMessage, body *iot.DeviceMessageReport) error {
+	fmt.Printf("device %s sent message %s\n", message.NotifyData.Header.DeviceID, string(body.Content))
+	return nil
+}
+
+func (h *pushMessageHandler) OnDeviceCommandStatusChange(ctx context.Context, message *iot.PushMessage, body *iot.DeviceCommandStatusChange) error {
+	fmt.Printf("command %s of device %s is %s\n", body.CommandID, message.NotifyData.Header.DeviceID, body.Status)
+	return nil
+}
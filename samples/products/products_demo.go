@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	iot "huaweicloud-iot-application-sdk-go"
+)
+
+func main() {
+	options := iot.ApplicationOptions{
+		ServerPort:    443,
+		ServerAddress: "iotda.cn-north-4.myhuaweicloud.com",
+		InstanceId:    "",
+		ProjectId:     "25e1be7c374749e9b6a25bc4ad53393a",
+
+		Credential: &iot.Credentials{
+			Ak:      "xxx",
+			Sk:      "xxx",
+			UseAkSk: true,
+		},
+	}
+
+	client := iot.CreateSyncIotApplicationClient(options)
+
+	product, err := client.CreateProduct(iot.CreateProductRequest{
+		Name:         "go-sdk-product",
+		DeviceType:   "SmokeDetector",
+		ProtocolType: "MQTT",
+		DataFormat:   "json",
+		AppID:        "a04cafa7d2714e9eaff4fe9b210ccec0",
+		ServiceCapabilities: []iot.ServiceCapability{
+			{
+				ServiceID:   "smokeDetector",
+				ServiceType: "smokeDetector",
+				Properties: []iot.ServiceProperty{
+					{
+						PropertyName: "alarm",
+						DataType:     "int",
+						Min:          "0",
+						Max:          "1",
+						Method:       "RW",
+					},
+				},
+				Commands: []iot.ServiceCommand{
+					{
+						CommandName: "ringAlarm",
+						Paras: []iot.ServiceCommandPara{
+							{
+								ParaName: "duration",
+								DataType: "int",
+								Min:      "0",
+								Max:      "3600",
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		fmt.Println(err)
+		panic(1)
+	}
+
+	fmt.Printf("product id is %s\n", product.ProductID)
+
+	products, err := client.ListProducts(iot.ListProductsRequest{
+		AppId: "a04cafa7d2714e9eaff4fe9b210ccec0",
+	})
+	if err != nil {
+		fmt.Println(err)
+		panic(1)
+	}
+
+	fmt.Println(products.Products)
+
+	fmt.Println(client.DeleteProduct(product.ProductID, "a04cafa7d2714e9eaff4fe9b210ccec0"))
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	iot "huaweicloud-iot-application-sdk-go"
+)
+
+func main() {
+	options := iot.ApplicationOptions{
+		ServerPort:    443,
+		ServerAddress: "iotda.cn-north-4.myhuaweicloud.com",
+		InstanceId:    "",
+		ProjectId:     "25e1be7c374749e9b6a25bc4ad53393a",
+
+		Credential: &iot.Credentials{
+			Ak:      "xxx",
+			Sk:      "xxx",
+			UseAkSk: true,
+		},
+	}
+
+	client := iot.CreateSyncIotApplicationClient(options)
+
+	rule, err := client.CreateRoutingRule(iot.CreateRoutingRuleRequest{
+		RuleName: "device-status-to-amqp",
+		Subject: iot.RoutingRuleSubject{
+			Resource: iot.RuleResourceDeviceStatus,
+			Event:    iot.RuleEventUpdate,
+		},
+		AppType: iot.RuleAppTypeApp,
+		AppID:   "a04cafa7d2714e9eaff4fe9b210ccec0",
+		Select:  "*",
+		Where:   "notify_data.body.status='ONLINE'",
+	})
+	if err != nil {
+		fmt.Println(err)
+		panic(1)
+	}
+
+	action, err := client.CreateRuleAction(iot.CreateRuleActionRequest{
+		RuleID:  rule.RuleID,
+		Channel: iot.ChannelAmqpForwarding,
+		ChannelDetail: iot.ChannelDetail{
+			AmqpForwarding: &iot.AmqpForwarding{
+				QueueName: "go-sdk-queue",
+			},
+		},
+	})
+	if err != nil {
+		fmt.Println(err)
+		panic(1)
+	}
+
+	fmt.Printf("action id is %s\n", action.ActionID)
+
+	active := true
+	fmt.Println(client.UpdateRoutingRule(rule.RuleID, iot.UpdateRoutingRuleRequest{
+		Active: &active,
+	}))
+}
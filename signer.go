@@ -4,136 +4,282 @@ import (
 	"bytes"
 	"crypto/hmac"
 	"crypto/sha256"
-	"encoding/gob"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"github.com/go-resty/resty/v2"
-	"net/url"
+	"io/ioutil"
+	"net/http"
 	"sort"
 	"strings"
+	"time"
 )
 
-func SignMessage(request *resty.Request, sk, ak string) string {
-	headerKeys := getSortedHeaders(request)
+const (
+	SignAlgorithm          = "SDK-HMAC-SHA256"
+	HeaderSdkDate          = "X-Sdk-Date"
+	HeaderSdkContentSha256 = "X-Sdk-Content-Sha256"
+	UnsignedPayload        = "UNSIGNED-PAYLOAD"
+	SdkDateFormat          = "20060102T150405Z"
+	headerHost             = "host"
+	headerAuthorization    = "Authorization"
+	headerSdkDateLowerCase = "x-sdk-date"
+
+	headerSdkContentSha256LowerCase = "x-sdk-content-sha256"
+)
 
-	headers := strings.ToLower(strings.Join(headerKeys, ";"))
-	signature := sign(request, sk)
+// 默认不参与签名的消息头，这些消息头可能被HTTP客户端、代理或者网关修改
+var unsignedHeaders = map[string]bool{
+	"authorization":       true,
+	"user-agent":          true,
+	"accept-encoding":     true,
+	"content-length":      true,
+	"connection":          true,
+	"keep-alive":          true,
+	"transfer-encoding":   true,
+	"te":                  true,
+	"upgrade":             true,
+	"expect":              true,
+	"proxy-authorization": true,
+}
 
-	return "SDK-HMAC-SHA256 " + "Access=" + ak + ", " + "SignedHeaders=" + headers + "," +
-		"Signature=" + signature
+// Signer 使用华为云API网关的SDK-HMAC-SHA256算法对*http.Request签名，可以用于任意HTTP客户端
+type Signer struct {
+	Ak string
+	Sk string
 
+	// 参与签名的消息头，不区分大小写。为空时对除了User-Agent、Content-Length等易变消息头以外的所有消息头签名，
+	// host、x-sdk-date以及请求中存在的x-sdk-content-sha256总是参与签名
+	SignedHeaders []string
 }
 
-func sign(request *resty.Request, sk string) string {
-	// 计算签名
-	h := hmac.New(sha256.New, []byte(sk))
-	if _, err := h.Write([]byte(stringToSign(request))); err != nil {
-		fmt.Println("error")
+func NewSigner(ak, sk string) *Signer {
+	return &Signer{
+		Ak: ak,
+		Sk: sk,
 	}
+}
 
-	signature := hex.EncodeToString(h.Sum(nil))
-	return signature
+func (s *Signer) SetSignedHeaders(headers ...string) *Signer {
+	s.SignedHeaders = headers
+	return s
 }
 
-func stringToSign(request *resty.Request) string {
-	stringToSigns := make([]string, 0)
-	stringToSigns = append(stringToSigns, "SDK-HMAC-SHA256")
-	stringToSigns = append(stringToSigns, request.Header.Get("X-Sdk-Date"))
-	stringToSigns = append(stringToSigns, constructCanonicalRequest(request))
+// Sign 对请求签名并设置Authorization消息头，X-Sdk-Date消息头不存在时使用当前时间。
+// body为请求体的原始字节，签名不会读取request.Body。
+func (s *Signer) Sign(request *http.Request, body []byte) error {
+	if len(s.Ak) == 0 || len(s.Sk) == 0 {
+		return errors.New("signer ak or sk is empty")
+	}
 
-	return strings.Join(stringToSigns, "\n")
+	if len(request.Header.Get(HeaderSdkDate)) == 0 {
+		request.Header.Set(HeaderSdkDate, time.Now().UTC().Format(SdkDateFormat))
+	}
+
+	signedHeaders := s.signedHeaders(request)
+	signature := computeSignature(s.Sk, request, body, signedHeaders)
+
+	request.Header.Set(headerAuthorization, fmt.Sprintf("%s Access=%s, SignedHeaders=%s, Signature=%s",
+		SignAlgorithm, s.Ak, strings.Join(signedHeaders, ";"), signature))
+
+	return nil
 }
 
-// 构造规范请求
-func constructCanonicalRequest(request *resty.Request) string {
-	crParts := make([]string, 0)
+func (s *Signer) signedHeaders(request *http.Request) []string {
+	headers := map[string]bool{
+		headerHost:             true,
+		headerSdkDateLowerCase: true,
+	}
+	if len(request.Header.Values(HeaderSdkContentSha256)) != 0 {
+		headers[headerSdkContentSha256LowerCase] = true
+	}
 
-	// 构造HTTP请求方法
-	httpRequestMethod := strings.ToUpper(request.Method)
-	crParts = append(crParts, httpRequestMethod)
+	if len(s.SignedHeaders) != 0 {
+		for _, key := range s.SignedHeaders {
+			headers[strings.ToLower(key)] = true
+		}
+	} else {
+		for key := range request.Header {
+			if key = strings.ToLower(key); !unsignedHeaders[key] {
+				headers[key] = true
+			}
+		}
+	}
 
-	// 添加规范URI参数
-	uri, _ := url.Parse(request.URL)
-	path := uri.Path
-	if len(path) == 0 {
-		path = "/"
+	signedHeaders := make([]string, 0, len(headers))
+	for key := range headers {
+		signedHeaders = append(signedHeaders, key)
 	}
-	if path[len(path)-1] != '/' {
-		path += "/"
+	sort.Strings(signedHeaders)
+
+	return signedHeaders
+}
+
+func computeSignature(sk string, request *http.Request, body []byte, signedHeaders []string) string {
+	canonicalRequest := buildCanonicalRequest(request, body, signedHeaders)
+	stringToSign := strings.Join([]string{
+		SignAlgorithm,
+		request.Header.Get(HeaderSdkDate),
+		hexSha256([]byte(canonicalRequest)),
+	}, "\n")
+
+	h := hmac.New(sha256.New, []byte(sk))
+	h.Write([]byte(stringToSign))
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// 规范请求：HTTPRequestMethod\nCanonicalURI\nCanonicalQueryString\nCanonicalHeaders\nSignedHeaders\nHexEncode(Hash(RequestPayload))
+func buildCanonicalRequest(request *http.Request, body []byte, signedHeaders []string) string {
+	payloadHash := request.Header.Get(HeaderSdkContentSha256)
+	if payloadHash != UnsignedPayload {
+		payloadHash = hexSha256(body)
 	}
-	canonicalURI := path
-	crParts = append(crParts, canonicalURI)
 
-	// 添加规范查询字符串（CanonicalQueryString），以换行符结束。
-	queryKeys := make([]string, 0, len(uri.Query()))
-	for key := range uri.Query() {
-		queryKeys = append(queryKeys, key)
+	return strings.Join([]string{
+		strings.ToUpper(request.Method),
+		canonicalURI(request),
+		canonicalQueryString(request),
+		canonicalHeaders(request, signedHeaders),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+}
+
+// API网关要求规范URI以/结尾，这里只影响参与签名的字符串，不会修改请求的路径
+func canonicalURI(request *http.Request) string {
+	segments := strings.Split(request.URL.Path, "/")
+	for i, segment := range segments {
+		segments[i] = signEscape(segment)
 	}
-	sort.Strings(queryKeys)
 
-	queryKeysAndValues := make([]string, len(queryKeys))
-	for i, key := range queryKeys {
-		k := strings.Replace(url.QueryEscape(key), "+", "%20", -1)
-		v := strings.Replace(url.QueryEscape(uri.Query().Get(key)), "+", "%20", -1)
-		queryKeysAndValues[i] = k + "=" + v
+	uri := strings.Join(segments, "/")
+	if len(uri) == 0 || uri[len(uri)-1] != '/' {
+		uri += "/"
 	}
 
-	query := strings.Join(queryKeysAndValues, "&")
-	crParts = append(crParts, query)
+	return uri
+}
 
-	// 4 添加规范消息头（CanonicalHeaders），以换行符结束
-	headerKeys := getSortedHeaders(request)
+func canonicalQueryString(request *http.Request) string {
+	query := request.URL.Query()
 
-	headerKeysAndValues := make([]string, len(headerKeys))
-	for i, key := range headerKeys {
-		v := request.Header.Get(key)
-		k := strings.ToLower(key)
-		headerKeysAndValues[i] = k + ":" + v
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
 
-	crParts = append(crParts, headerKeysAndValues...)
-	crParts = append(crParts, "")
-	crParts = append(crParts, strings.ToLower(strings.Join(headerKeys, ";")))
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, signEscape(key)+"="+signEscape(value))
+		}
+	}
 
-	// 使用SHA 256哈希函数以基于HTTP或HTTPS请求正文中的body体（RequestPayload），创建哈希值。
-	requestBody, err := getBytes(request.Body)
-	if err != nil {
-		requestBody = []byte("")
+	return strings.Join(pairs, "&")
+}
+
+// 规范消息头以换行符结束，因此与SignedHeaders之间有一个空行
+func canonicalHeaders(request *http.Request, signedHeaders []string) string {
+	headers := make(map[string][]string, len(request.Header))
+	for key, values := range request.Header {
+		key = strings.ToLower(key)
+		headers[key] = append(headers[key], values...)
 	}
-	encodedRequestBody := hashRequestBody(requestBody)
-	crParts = append(crParts, encodedRequestBody)
-	//crParts = append(crParts,"\n")
 
-	stringToHash := strings.Join(crParts, "\n")
-	return hashRequestBody([]byte(stringToHash))
+	lines := make([]string, 0, len(signedHeaders))
+	for _, key := range signedHeaders {
+		values := headers[key]
+		if key == headerHost {
+			values = []string{requestHost(request)}
+		}
+
+		values = append([]string(nil), values...)
+		sort.Strings(values)
+		for _, value := range values {
+			lines = append(lines, key+":"+strings.TrimSpace(value))
+		}
+	}
+
+	return strings.Join(lines, "\n") + "\n"
 }
 
-func getBytes(key interface{}) ([]byte, error) {
-	var buf bytes.Buffer
-	enc := gob.NewEncoder(&buf)
-	err := enc.Encode(key)
-	if err != nil {
-		return nil, err
+func requestHost(request *http.Request) string {
+	if len(request.Host) != 0 {
+		return request.Host
+	}
+
+	return request.URL.Host
+}
+
+// 按照RFC 3986编码，只保留非保留字符A-Z、a-z、0-9、-、_、.、~
+func signEscape(s string) string {
+	var buffer bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			buffer.WriteByte(c)
+		} else {
+			fmt.Fprintf(&buffer, "%%%02X", c)
+		}
 	}
-	return buf.Bytes(), nil
+
+	return buffer.String()
 }
 
-func hashRequestBody(requestBody []byte) string {
-	hash := sha256.New()
-	_, err := hash.Write([]byte(requestBody))
+func hexSha256(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// 读取请求体用于签名，请求体可以通过GetBody重复读取时不会消耗request.Body
+func readRequestBody(request *http.Request) ([]byte, error) {
+	if request.Body == nil || request.Body == http.NoBody {
+		return nil, nil
+	}
+
+	if request.GetBody == nil {
+		body, err := ioutil.ReadAll(request.Body)
+		if err != nil {
+			return nil, err
+		}
+		request.Body = ioutil.NopCloser(bytes.NewReader(body))
+		return body, nil
+	}
+
+	reader, err := request.GetBody()
 	if err != nil {
-		fmt.Println("error")
+		return nil, err
 	}
+	defer reader.Close()
 
-	return strings.ToLower(hex.EncodeToString(hash.Sum(nil)))
+	return ioutil.ReadAll(reader)
 }
 
-func getSortedHeaders(request *resty.Request) []string {
-	headerKeys := make([]string, 0)
-	for key := range request.Header {
-		headerKeys = append(headerKeys, key)
+// SignMessage 对resty请求签名，返回Authorization消息头的值，只能在请求的RawRequest创建之后调用
+//
+// Deprecated: 使用Signer对*http.Request签名
+func SignMessage(request *resty.Request, sk, ak string) string {
+	if request.RawRequest == nil {
+		return ""
+	}
+
+	var body []byte
+	switch value := request.Body.(type) {
+	case []byte:
+		body = value
+	case string:
+		body = []byte(value)
+	}
 
+	signer := NewSigner(ak, sk)
+	if err := signer.Sign(request.RawRequest, body); err != nil {
+		return ""
 	}
-	sort.Strings(headerKeys)
-	return headerKeys
+
+	return request.RawRequest.Header.Get(headerAuthorization)
 }
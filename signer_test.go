@@ -0,0 +1,212 @@
+package iot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const (
+	testAk      = "QTWAOYTTINDUT2QVKYUC"
+	testSk      = "MFyfvK41ba2giqM7Uio6PznpdUKGpownRZlmVmHc"
+	testSdkDate = "20191115T033655Z"
+	// 空请求体的SHA256
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// 期望的规范请求按照API网关签名文档中的规则手工构造，签名根据规范请求独立计算
+func TestSign(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		url           string
+		header        map[string][]string
+		body          string
+		signedHeaders []string
+		wantCanonical string
+		wantHeaders   string
+		wantSignature string
+	}{
+		{
+			name:   "canonical uri ends with slash",
+			method: http.MethodGet,
+			url:    "https://service.region.example.com/app1",
+			header: map[string][]string{"Content-Type": {"application/json"}},
+			wantCanonical: "GET\n/app1/\n\n" +
+				"content-type:application/json\nhost:service.region.example.com\nx-sdk-date:" + testSdkDate + "\n\n" +
+				"content-type;host;x-sdk-date\n" + emptyPayloadHash,
+			wantHeaders:   "content-type;host;x-sdk-date",
+			wantSignature: "d00fa931005c7d38f2aa610bc224f2df8ab081dd6838fa9306c2c6b034025877",
+		},
+		{
+			name:   "root path",
+			method: http.MethodGet,
+			url:    "https://service.region.example.com",
+			wantCanonical: "GET\n/\n\n" +
+				"host:service.region.example.com\nx-sdk-date:" + testSdkDate + "\n\n" +
+				"host;x-sdk-date\n" + emptyPayloadHash,
+			wantHeaders:   "host;x-sdk-date",
+			wantSignature: "7d2aaa5a463367fdab3d7f4a7f5f6120545f2c63a9a16da5edb4b537ef770954",
+		},
+		{
+			name:   "path already ends with slash",
+			method: http.MethodDelete,
+			url:    "https://service.region.example.com/app1/",
+			wantCanonical: "DELETE\n/app1/\n\n" +
+				"host:service.region.example.com\nx-sdk-date:" + testSdkDate + "\n\n" +
+				"host;x-sdk-date\n" + emptyPayloadHash,
+			wantHeaders:   "host;x-sdk-date",
+			wantSignature: "88949b5ee04f98a1c42d50491e1a816e80f3a6625e6dd8f7f5f3ed1db862535e",
+		},
+		{
+			name:   "sorted multi-value query string",
+			method: http.MethodGet,
+			url:    "https://service.region.example.com/app1?b=4&a=1234&a=12&c=&a=2",
+			wantCanonical: "GET\n/app1/\na=12&a=1234&a=2&b=4&c=\n" +
+				"host:service.region.example.com\nx-sdk-date:" + testSdkDate + "\n\n" +
+				"host;x-sdk-date\n" + emptyPayloadHash,
+			wantHeaders:   "host;x-sdk-date",
+			wantSignature: "ad884af75eb2c5b273b2f267e24d53b61ba4a593b025d27c6ee26a29002858ca",
+		},
+		{
+			name:   "rfc 3986 escaping",
+			method: http.MethodGet,
+			url:    "https://service.region.example.com/v5/iot/a%20b/devices/c:d*e~f?name=x%2By%20z&tag%2F1=%E4%B8%AD&q=a+b",
+			wantCanonical: "GET\n/v5/iot/a%20b/devices/c%3Ad%2Ae~f/\n" +
+				"name=x%2By%20z&q=a%20b&tag%2F1=%E4%B8%AD\n" +
+				"host:service.region.example.com\nx-sdk-date:" + testSdkDate + "\n\n" +
+				"host;x-sdk-date\n" + emptyPayloadHash,
+			wantHeaders:   "host;x-sdk-date",
+			wantSignature: "1ae2fb408db03659961cf8eb03b3759463e2b51238a902622711abb486621eb8",
+		},
+		{
+			name:   "body and default signed headers",
+			method: http.MethodPost,
+			url:    "https://iotda.cn-north-4.myhuaweicloud.com/v5/iot/project/devices",
+			header: map[string][]string{
+				"Content-Type":   {"application/json"},
+				"X-Project-Id":   {"  project  "},
+				"X-Multi":        {"b", "a"},
+				"User-Agent":     {"go-test"},
+				"Content-Length": {"26"},
+			},
+			body: `{"device_name":"device-1"}`,
+			wantCanonical: "POST\n/v5/iot/project/devices/\n\n" +
+				"content-type:application/json\nhost:iotda.cn-north-4.myhuaweicloud.com\n" +
+				"x-multi:a\nx-multi:b\nx-project-id:project\nx-sdk-date:" + testSdkDate + "\n\n" +
+				"content-type;host;x-multi;x-project-id;x-sdk-date\n" + hexSha256([]byte(`{"device_name":"device-1"}`)),
+			wantHeaders:   "content-type;host;x-multi;x-project-id;x-sdk-date",
+			wantSignature: "1c0b2f2b64c539cea6ff5bee1f4045f0f1a5a2a66cb31e4ea6490517bcee684e",
+		},
+		{
+			name:   "explicit signed headers",
+			method: http.MethodPut,
+			url:    "https://iotda.cn-north-4.myhuaweicloud.com/v5/iot/project/devices/device-1",
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+				"X-Project-Id": {"project"},
+			},
+			body:          `{}`,
+			signedHeaders: []string{"Content-Type"},
+			wantCanonical: "PUT\n/v5/iot/project/devices/device-1/\n\n" +
+				"content-type:application/json\nhost:iotda.cn-north-4.myhuaweicloud.com\nx-sdk-date:" + testSdkDate + "\n\n" +
+				"content-type;host;x-sdk-date\n" + hexSha256([]byte(`{}`)),
+			wantHeaders:   "content-type;host;x-sdk-date",
+			wantSignature: "b61d5687a0885c1f80ee71127fb672bc02a7909bc6f6c3b78dd3d1a6cb15f6e4",
+		},
+		{
+			name:          "signed header missing from request",
+			method:        http.MethodGet,
+			url:           "https://service.region.example.com/app1",
+			signedHeaders: []string{"X-Missing"},
+			wantCanonical: "GET\n/app1/\n\n" +
+				"host:service.region.example.com\nx-sdk-date:" + testSdkDate + "\n\n" +
+				"host;x-missing;x-sdk-date\n" + emptyPayloadHash,
+			wantHeaders:   "host;x-missing;x-sdk-date",
+			wantSignature: "ae433c8f97f12f046dd45c3ab65b4f88ef7e4c7c7ba0acfa149f4e8dac829bd7",
+		},
+		{
+			name:          "unsigned payload",
+			method:        http.MethodPost,
+			url:           "https://service.region.example.com/app1",
+			header:        map[string][]string{"X-Sdk-Content-Sha256": {UnsignedPayload}},
+			body:          "large body",
+			signedHeaders: []string{"host"},
+			wantCanonical: "POST\n/app1/\n\n" +
+				"host:service.region.example.com\nx-sdk-content-sha256:UNSIGNED-PAYLOAD\nx-sdk-date:" + testSdkDate + "\n\n" +
+				"host;x-sdk-content-sha256;x-sdk-date\nUNSIGNED-PAYLOAD",
+			wantHeaders:   "host;x-sdk-content-sha256;x-sdk-date",
+			wantSignature: "63ab200c69e9578211083e6daae4b70f8329229f00bd5cbe86872889371dba3a",
+		},
+		{
+			name:   "content sha256 other than unsigned payload",
+			method: http.MethodPost,
+			url:    "https://service.region.example.com/app1",
+			header: map[string][]string{"X-Sdk-Content-Sha256": {"abc"}},
+			body:   "body",
+			wantCanonical: "POST\n/app1/\n\n" +
+				"host:service.region.example.com\nx-sdk-content-sha256:abc\nx-sdk-date:" + testSdkDate + "\n\n" +
+				"host;x-sdk-content-sha256;x-sdk-date\n" + hexSha256([]byte("body")),
+			wantHeaders:   "host;x-sdk-content-sha256;x-sdk-date",
+			wantSignature: "23f9e8ca6f437b10c0278d866704e355bd9613c1444b7e0d813b91a67b3a23f3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			for key, values := range tt.header {
+				for _, value := range values {
+					request.Header.Add(key, value)
+				}
+			}
+			request.Header.Set(HeaderSdkDate, testSdkDate)
+
+			signer := NewSigner(testAk, testSk).SetSignedHeaders(tt.signedHeaders...)
+			if err := signer.Sign(request, []byte(tt.body)); err != nil {
+				t.Fatal(err)
+			}
+
+			signedHeaders := strings.Split(tt.wantHeaders, ";")
+			if canonical := buildCanonicalRequest(request, []byte(tt.body), signedHeaders); canonical != tt.wantCanonical {
+				t.Errorf("canonical request:\n%s\nwant:\n%s", canonical, tt.wantCanonical)
+			}
+
+			h := hmac.New(sha256.New, []byte(testSk))
+			h.Write([]byte(SignAlgorithm + "\n" + testSdkDate + "\n" + hexSha256([]byte(tt.wantCanonical))))
+			signature := hex.EncodeToString(h.Sum(nil))
+			if signature != tt.wantSignature {
+				t.Errorf("signature = %s, want %s", signature, tt.wantSignature)
+			}
+
+			want := fmt.Sprintf("SDK-HMAC-SHA256 Access=%s, SignedHeaders=%s, Signature=%s", testAk, tt.wantHeaders, tt.wantSignature)
+			if got := request.Header.Get("Authorization"); got != want {
+				t.Errorf("Authorization = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestSignDefaults(t *testing.T) {
+	if err := NewSigner("", testSk).Sign(httptest.NewRequest(http.MethodGet, "/", nil), nil); err == nil {
+		t.Error("Sign() with empty ak should return error")
+	}
+
+	request := httptest.NewRequest(http.MethodPost, "https://service.region.example.com/app1", strings.NewReader("body"))
+	if err := NewSigner(testAk, testSk).Sign(request, []byte("body")); err != nil {
+		t.Fatal(err)
+	}
+	if len(request.Header.Get(HeaderSdkDate)) != len(SdkDateFormat) {
+		t.Errorf("X-Sdk-Date = %s", request.Header.Get(HeaderSdkDate))
+	}
+
+	body, err := readRequestBody(request)
+	if err != nil || string(body) != "body" {
+		t.Errorf("request body = %s, %v", body, err)
+	}
+}
@@ -1,192 +1,450 @@
 package iot
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"github.com/go-resty/resty/v2"
-	"github.com/golang/glog"
+	"io"
+	"net/http"
 	"strconv"
+	"sync"
 	"time"
 )
 
 type ApplicationClient interface {
+	// 产品管理
+	ListProducts(request ListProductsRequest) (*ListProductsResponse, error)
+	ListProductsCtx(ctx context.Context, request ListProductsRequest) (*ListProductsResponse, error)
+	CreateProduct(request CreateProductRequest) (*ProductDetailResponse, error)
+	CreateProductCtx(ctx context.Context, request CreateProductRequest) (*ProductDetailResponse, error)
+	ShowProduct(productId, appId string) (*ProductDetailResponse, error)
+	ShowProductCtx(ctx context.Context, productId, appId string) (*ProductDetailResponse, error)
+	UpdateProduct(productId string, request UpdateProductRequest) (*ProductDetailResponse, error)
+	UpdateProductCtx(ctx context.Context, productId string, request UpdateProductRequest) (*ProductDetailResponse, error)
+	DeleteProduct(productId, appId string) (bool, error)
+	DeleteProductCtx(ctx context.Context, productId, appId string) (bool, error)
+
 	// 设备管理
 	ListDevices(queryParas map[string]string) (*ListDeviceResponse, error)
+	ListDevicesCtx(ctx context.Context, queryParas map[string]string) (*ListDeviceResponse, error)
 	CreateDevice(request CreateDeviceRequest) (*CreateDeviceResponse, error)
+	CreateDeviceCtx(ctx context.Context, request CreateDeviceRequest) (*CreateDeviceResponse, error)
 	ShowDevice(deviceId string) (*DeviceDetailResponse, error)
+	ShowDeviceCtx(ctx context.Context, deviceId string) (*DeviceDetailResponse, error)
 	UpdateDevice(deviceId string, request UpdateDeviceRequest) (*DeviceDetailResponse, error)
+	UpdateDeviceCtx(ctx context.Context, deviceId string, request UpdateDeviceRequest) (*DeviceDetailResponse, error)
 	DeleteDevice(deviceId string) (bool, error)
+	DeleteDeviceCtx(ctx context.Context, deviceId string) (bool, error)
 	FreezeDevice(deviceId string) (bool, error)
+	FreezeDeviceCtx(ctx context.Context, deviceId string) (bool, error)
 	UnFreezeDevice(deviceId string) (bool, error)
+	UnFreezeDeviceCtx(ctx context.Context, deviceId string) (bool, error)
 	ResetDeviceSecret(deviceId, secret string, forceDisconnect bool) (*ResetDeviceSecretResponse, error)
+	ResetDeviceSecretCtx(ctx context.Context, deviceId, secret string, forceDisconnect bool) (*ResetDeviceSecretResponse, error)
 
 	// 设备消息
 	ListDeviceMessages(deviceId string) (*DeviceMessages, error)
+	ListDeviceMessagesCtx(ctx context.Context, deviceId string) (*DeviceMessages, error)
 	ShowDeviceMessage(deviceId, messageId string) (*DeviceMessage, error)
+	ShowDeviceMessageCtx(ctx context.Context, deviceId, messageId string) (*DeviceMessage, error)
 	SendDeviceMessage(deviceId string, msg SendDeviceMessageRequest) (*SendDeviceMessageResponse, error)
+	SendDeviceMessageCtx(ctx context.Context, deviceId string, msg SendDeviceMessageRequest) (*SendDeviceMessageResponse, error)
 
 	// 设备命令
 	SendDeviceSyncCommand(deviceId string, request DeviceSyncCommandRequest) (*DeviceSyncCommandResponse, error)
+	SendDeviceSyncCommandCtx(ctx context.Context, deviceId string, request DeviceSyncCommandRequest) (*DeviceSyncCommandResponse, error)
+	SendDeviceAsyncCommand(deviceId string, request DeviceAsyncCommandRequest) (*DeviceAsyncCommand, error)
+	SendDeviceAsyncCommandCtx(ctx context.Context, deviceId string, request DeviceAsyncCommandRequest) (*DeviceAsyncCommand, error)
+	ShowDeviceAsyncCommand(deviceId, commandId string) (*DeviceAsyncCommand, error)
+	ShowDeviceAsyncCommandCtx(ctx context.Context, deviceId, commandId string) (*DeviceAsyncCommand, error)
+	ListDeviceAsyncCommands(deviceId string, request ListDeviceAsyncCommandsRequest) (*ListDeviceAsyncCommandsResponse, error)
+	ListDeviceAsyncCommandsCtx(ctx context.Context, deviceId string, request ListDeviceAsyncCommandsRequest) (*ListDeviceAsyncCommandsResponse, error)
 
 	// 设备属性
 	QueryDeviceProperties(deviceId, serviceId string) (interface{}, error)
+	QueryDevicePropertiesCtx(ctx context.Context, deviceId, serviceId string) (interface{}, error)
 	UpdateDeviceProperties(deviceId string, services interface{}) (bool, error)
+	UpdateDevicePropertiesCtx(ctx context.Context, deviceId string, services interface{}) (bool, error)
 
 	// AMQP队列管理
 	ListAmqpQueues(req ListAmqpQueuesRequest) (*ListAmqpQueuesResponse, error)
+	ListAmqpQueuesCtx(ctx context.Context, req ListAmqpQueuesRequest) (*ListAmqpQueuesResponse, error)
 	CreateAmqpQueue(queueName string) (*CreateAmqpQueueResponse, error)
+	CreateAmqpQueueCtx(ctx context.Context, queueName string) (*CreateAmqpQueueResponse, error)
 	ShowAmqpQueue(queueId string) (*ShowAmqpQueueResponse, error)
+	ShowAmqpQueueCtx(ctx context.Context, queueId string) (*ShowAmqpQueueResponse, error)
 	DeleteAmqpQueue(queueId string) (bool, error)
+	DeleteAmqpQueueCtx(ctx context.Context, queueId string) (bool, error)
 
 	// 接入凭证管理
 	CreateAccessCode(accessType string) (*CreateAccessCodeResponse, error)
+	CreateAccessCodeCtx(ctx context.Context, accessType string) (*CreateAccessCodeResponse, error)
 
 	// 数据流转规则管理
+	ListRoutingRules(request ListRoutingRulesRequest) (*ListRoutingRulesResponse, error)
+	ListRoutingRulesCtx(ctx context.Context, request ListRoutingRulesRequest) (*ListRoutingRulesResponse, error)
+	CreateRoutingRule(request CreateRoutingRuleRequest) (*RoutingRuleResponse, error)
+	CreateRoutingRuleCtx(ctx context.Context, request CreateRoutingRuleRequest) (*RoutingRuleResponse, error)
+	ShowRoutingRule(ruleId string) (*RoutingRuleResponse, error)
+	ShowRoutingRuleCtx(ctx context.Context, ruleId string) (*RoutingRuleResponse, error)
+	UpdateRoutingRule(ruleId string, request UpdateRoutingRuleRequest) (*RoutingRuleResponse, error)
+	UpdateRoutingRuleCtx(ctx context.Context, ruleId string, request UpdateRoutingRuleRequest) (*RoutingRuleResponse, error)
+	DeleteRoutingRule(ruleId string) (bool, error)
+	DeleteRoutingRuleCtx(ctx context.Context, ruleId string) (bool, error)
+
+	ListRuleActions(request ListRuleActionsRequest) (*ListRuleActionsResponse, error)
+	ListRuleActionsCtx(ctx context.Context, request ListRuleActionsRequest) (*ListRuleActionsResponse, error)
+	CreateRuleAction(request CreateRuleActionRequest) (*RuleActionResponse, error)
+	CreateRuleActionCtx(ctx context.Context, request CreateRuleActionRequest) (*RuleActionResponse, error)
+	ShowRuleAction(actionId string) (*RuleActionResponse, error)
+	ShowRuleActionCtx(ctx context.Context, actionId string) (*RuleActionResponse, error)
+	UpdateRuleAction(actionId string, request UpdateRuleActionRequest) (*RuleActionResponse, error)
+	UpdateRuleActionCtx(ctx context.Context, actionId string, request UpdateRuleActionRequest) (*RuleActionResponse, error)
+	DeleteRuleAction(actionId string) (bool, error)
+	DeleteRuleActionCtx(ctx context.Context, actionId string) (bool, error)
 
 	// 设备影子
 	ShowDeviceShadow(deviceId string) (*ShowDeviceShadowResponse, error)
+	ShowDeviceShadowCtx(ctx context.Context, deviceId string) (*ShowDeviceShadowResponse, error)
 	UpdateDeviceShadow(deviceId string, request UpdateDeviceShadowRequest) (*ShowDeviceShadowResponse, error)
+	UpdateDeviceShadowCtx(ctx context.Context, deviceId string, request UpdateDeviceShadowRequest) (*ShowDeviceShadowResponse, error)
 
 	// 设备组管理
 	ListDeviceGroups(request ListDeviceGroupRequest) (*ListDeviceGroupResponse, error)
+	ListDeviceGroupsCtx(ctx context.Context, request ListDeviceGroupRequest) (*ListDeviceGroupResponse, error)
 	CreateDeviceGroup(request CreateDeviceGroupRequest) (*CreateDeviceGroupResponse, error)
+	CreateDeviceGroupCtx(ctx context.Context, request CreateDeviceGroupRequest) (*CreateDeviceGroupResponse, error)
 	ShowDeviceGroup(deviceGroupId string) (*ShowDeviceGroupResponse, error)
+	ShowDeviceGroupCtx(ctx context.Context, deviceGroupId string) (*ShowDeviceGroupResponse, error)
 	UpdateDeviceGroup(deviceGroupId string, request UpdateDeviceGroupRequest) (*UpdateDeviceGroupResponse, error)
+	UpdateDeviceGroupCtx(ctx context.Context, deviceGroupId string, request UpdateDeviceGroupRequest) (*UpdateDeviceGroupResponse, error)
 	DeleteDeviceGroup(deviceGroupId string) (bool, error)
+	DeleteDeviceGroupCtx(ctx context.Context, deviceGroupId string) (bool, error)
 
 	AddDeviceToDeviceGroup(deviceGroupId, deviceId string) (bool, error)
+	AddDeviceToDeviceGroupCtx(ctx context.Context, deviceGroupId, deviceId string) (bool, error)
 	RemoveDeviceFromDeviceGroup(deviceGroupId, deviceId string) (bool, error)
-	ListDeviceInDeviceGroup(deviceGroupId string, request ListDeviceInDeviceGroupRequest) (*ListDeviceInDeviceGroupRequest, error)
+	RemoveDeviceFromDeviceGroupCtx(ctx context.Context, deviceGroupId, deviceId string) (bool, error)
+	ListDeviceInDeviceGroup(deviceGroupId string, request ListDeviceInDeviceGroupRequest) (*ListDeviceInDeviceGroupResponse, error)
+	ListDeviceInDeviceGroupCtx(ctx context.Context, deviceGroupId string, request ListDeviceInDeviceGroupRequest) (*ListDeviceInDeviceGroupResponse, error)
 	// 标签管理
 	DeviceBindTags(request DeviceBindTagsRequest) (bool, error)
+	DeviceBindTagsCtx(ctx context.Context, request DeviceBindTagsRequest) (bool, error)
 	DeviceUnBindTags(request DeviceUnBindTagsRequest) (bool, error)
+	DeviceUnBindTagsCtx(ctx context.Context, request DeviceUnBindTagsRequest) (bool, error)
 	ListDeviceByTags(request ListDeviceByTagsRequest) (*ListDeviceByTagsResponse, error)
+	ListDeviceByTagsCtx(ctx context.Context, request ListDeviceByTagsRequest) (*ListDeviceByTagsResponse, error)
 
 	// 资源空间管理
 	ListApplications() (*Applications, error)
+	ListApplicationsCtx(ctx context.Context) (*Applications, error)
 	ShowApplication(appId string) (*Application, error)
+	ShowApplicationCtx(ctx context.Context, appId string) (*Application, error)
 	DeleteApplication(appId string) (bool, error)
+	DeleteApplicationCtx(ctx context.Context, appId string) (bool, error)
 	CreateApplication(request ApplicationCreateRequest) (*Application, error)
+	CreateApplicationCtx(ctx context.Context, request ApplicationCreateRequest) (*Application, error)
 
 	// 批量任务
+	ListBatchTasks(request ListBatchTasksRequest) (*ListBatchTasksResponse, error)
+	ListBatchTasksCtx(ctx context.Context, request ListBatchTasksRequest) (*ListBatchTasksResponse, error)
+	CreateBatchTask(request CreateBatchTaskRequest) (*BatchTask, error)
+	CreateBatchTaskCtx(ctx context.Context, request CreateBatchTaskRequest) (*BatchTask, error)
+	ShowBatchTask(taskId string, request ShowBatchTaskRequest) (*ShowBatchTaskResponse, error)
+	ShowBatchTaskCtx(ctx context.Context, taskId string, request ShowBatchTaskRequest) (*ShowBatchTaskResponse, error)
+	DeleteBatchTask(taskId string) (bool, error)
+	DeleteBatchTaskCtx(ctx context.Context, taskId string) (bool, error)
+
+	// 批量任务文件管理
+	UploadBatchTaskFile(fileName string, content io.Reader) (*UploadBatchTaskFileResponse, error)
+	UploadBatchTaskFileCtx(ctx context.Context, fileName string, content io.Reader) (*UploadBatchTaskFileResponse, error)
+	ListBatchTaskFiles() (*ListBatchTaskFilesResponse, error)
+	ListBatchTaskFilesCtx(ctx context.Context) (*ListBatchTaskFilesResponse, error)
+	DeleteBatchTaskFile(fileId string) (bool, error)
+	DeleteBatchTaskFileCtx(ctx context.Context, fileId string) (bool, error)
 
 	// 设备CA证书管理
 	ListDeviceCertificates(request ListDeviceCertificatesRequest) (*ListDeviceCertificatesResponse, error)
+	ListDeviceCertificatesCtx(ctx context.Context, request ListDeviceCertificatesRequest) (*ListDeviceCertificatesResponse, error)
 	UploadDeviceCertificates(request UploadDeviceCertificatesRequest) (*UploadDeviceCertificatesResponse, error)
+	UploadDeviceCertificatesCtx(ctx context.Context, request UploadDeviceCertificatesRequest) (*UploadDeviceCertificatesResponse, error)
 	DeleteDeviceCertificates(certificateId string) (bool, error)
+	DeleteDeviceCertificatesCtx(ctx context.Context, certificateId string) (bool, error)
 	VerifyDeviceCertificates(certificateId, verifyContent string) (bool, error)
+	VerifyDeviceCertificatesCtx(ctx context.Context, certificateId, verifyContent string) (bool, error)
 }
 
 type syncClient struct {
-	client  *resty.Client
-	options ApplicationOptions
+	client       *resty.Client
+	options      ApplicationOptions
+	credentials  CredentialsProvider
+	retryPolicy  *RetryPolicy
+	rateLimiter  *rateLimiter
+	interceptors []Interceptor
+	logger       Logger
+	metrics      MetricsRecorder
+
+	// 接入地址或者HTTP配置不合法时每次调用都返回该错误
+	configErr error
+
+	projectLock sync.Mutex
+	projectId   string
+	// 正在进行的项目ID解析，不为空时其他goroutine等待它的结果
+	projectResolving *projectIdResolution
 }
 
-func (client *syncClient) VerifyDeviceCertificates(certificateId, verifyContent string) (bool, error) {
-	requestBody := struct {
-		VerifyContent string `json:"verify_content"`
-	}{
-		VerifyContent: verifyContent,
+func (client *syncClient) ListDeviceAsyncCommands(deviceId string, request ListDeviceAsyncCommandsRequest) (*ListDeviceAsyncCommandsResponse, error) {
+	return client.ListDeviceAsyncCommandsCtx(context.Background(), deviceId, request)
+}
+
+func (client *syncClient) ListDeviceAsyncCommandsCtx(ctx context.Context, deviceId string, request ListDeviceAsyncCommandsRequest) (*ListDeviceAsyncCommandsResponse, error) {
+	queryParams := pageQueryParams(request.Limit, request.Marker, request.Offset)
+	if len(request.StartTime) != 0 {
+		queryParams["start_time"] = request.StartTime
 	}
 
-	binaryRequest, err := json.Marshal(requestBody)
-	if err != nil {
-		return false, err
+	if len(request.EndTime) != 0 {
+		queryParams["end_time"] = request.EndTime
 	}
 
-	httpResponse, err := client.client.R().
-		SetPathParam("certificate_id", certificateId).
-		SetQueryParam("action_id", "verify").
-		SetBody(binaryRequest).
-		Post("/v5/iot/{project_id}/certificates/{certificate_id}/action")
+	if len(request.Status) != 0 {
+		queryParams["status"] = request.Status
+	}
+
+	if len(request.CommandName) != 0 {
+		queryParams["command_name"] = request.CommandName
+	}
+
+	response := &ListDeviceAsyncCommandsResponse{}
+	err := client.invoke(ctx, opListDeviceAsyncCommands, &apiRequest{
+		pathParams:  map[string]string{"device_id": deviceId},
+		queryParams: queryParams,
+	}, response)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	if httpResponse.StatusCode() != 200 {
-		return false, convertResponseToApplicationError(httpResponse)
+	return response, nil
+}
+
+func (client *syncClient) ShowDeviceAsyncCommand(deviceId, commandId string) (*DeviceAsyncCommand, error) {
+	return client.ShowDeviceAsyncCommandCtx(context.Background(), deviceId, commandId)
+}
+
+func (client *syncClient) ShowDeviceAsyncCommandCtx(ctx context.Context, deviceId, commandId string) (*DeviceAsyncCommand, error) {
+	response := &DeviceAsyncCommand{}
+	err := client.invoke(ctx, opShowDeviceAsyncCommand, &apiRequest{
+		pathParams: map[string]string{
+			"device_id":  deviceId,
+			"command_id": commandId,
+		},
+	}, response)
+	if err != nil {
+		return nil, err
 	}
 
-	return true, nil
+	return response, nil
 }
 
-func (client *syncClient) DeleteDeviceCertificates(certificateId string) (bool, error) {
-	httpResponse, err := client.client.R().
-		SetPathParam("certificate_id", certificateId).
-		Delete("/v5/iot/{project_id}/certificates/{certificate_id}")
+func (client *syncClient) SendDeviceAsyncCommand(deviceId string, request DeviceAsyncCommandRequest) (*DeviceAsyncCommand, error) {
+	return client.SendDeviceAsyncCommandCtx(context.Background(), deviceId, request)
+}
+
+func (client *syncClient) SendDeviceAsyncCommandCtx(ctx context.Context, deviceId string, request DeviceAsyncCommandRequest) (*DeviceAsyncCommand, error) {
+	if len(request.SendStrategy) == 0 {
+		request.SendStrategy = SendStrategyImmediately
+	}
+
+	response := &DeviceAsyncCommand{}
+	err := client.invoke(ctx, opSendDeviceAsyncCommand, &apiRequest{
+		pathParams: map[string]string{"device_id": deviceId},
+		body:       request,
+	}, response)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	if httpResponse.StatusCode() != 204 {
-		return false, convertResponseToApplicationError(httpResponse)
+	return response, nil
+}
+
+func (client *syncClient) DeleteBatchTaskFile(fileId string) (bool, error) {
+	return client.DeleteBatchTaskFileCtx(context.Background(), fileId)
+}
+
+func (client *syncClient) DeleteBatchTaskFileCtx(ctx context.Context, fileId string) (bool, error) {
+	err := client.invoke(ctx, opDeleteBatchTaskFile, &apiRequest{
+		pathParams: map[string]string{"file_id": fileId},
+	}, nil)
+	if err != nil {
+		return false, err
 	}
 
 	return true, nil
 }
 
-func (client *syncClient) UploadDeviceCertificates(request UploadDeviceCertificatesRequest) (*UploadDeviceCertificatesResponse, error) {
-	binaryRequest, err := json.Marshal(request)
+func (client *syncClient) ListBatchTaskFiles() (*ListBatchTaskFilesResponse, error) {
+	return client.ListBatchTaskFilesCtx(context.Background())
+}
+
+func (client *syncClient) ListBatchTaskFilesCtx(ctx context.Context) (*ListBatchTaskFilesResponse, error) {
+	response := &ListBatchTaskFilesResponse{}
+	err := client.invoke(ctx, opListBatchTaskFiles, nil, response)
 	if err != nil {
 		return nil, err
 	}
 
-	httpResponse, err := client.client.R().
-		SetHeader("Content-Type", "application/json").
-		SetBody(binaryRequest).
-		Post("/v5/iot/{project_id}/certificates")
+	return response, nil
+}
+
+func (client *syncClient) UploadBatchTaskFile(fileName string, content io.Reader) (*UploadBatchTaskFileResponse, error) {
+	return client.UploadBatchTaskFileCtx(context.Background(), fileName, content)
+}
+
+func (client *syncClient) UploadBatchTaskFileCtx(ctx context.Context, fileName string, content io.Reader) (*UploadBatchTaskFileResponse, error) {
+	response := &UploadBatchTaskFileResponse{}
+	err := client.invoke(ctx, opUploadBatchTaskFile, &apiRequest{
+		fileName: fileName,
+		file:     content,
+	}, response)
 	if err != nil {
 		return nil, err
 	}
 
-	if httpResponse.StatusCode() != 200 {
-		return nil, convertResponseToApplicationError(httpResponse)
+	return response, nil
+}
+
+func (client *syncClient) DeleteBatchTask(taskId string) (bool, error) {
+	return client.DeleteBatchTaskCtx(context.Background(), taskId)
+}
+
+func (client *syncClient) DeleteBatchTaskCtx(ctx context.Context, taskId string) (bool, error) {
+	err := client.invoke(ctx, opDeleteBatchTask, &apiRequest{
+		pathParams: map[string]string{"task_id": taskId},
+	}, nil)
+	if err != nil {
+		return false, err
 	}
 
-	response := &UploadDeviceCertificatesResponse{}
-	err = json.Unmarshal(httpResponse.Body(), response)
+	return true, nil
+}
+
+func (client *syncClient) ShowBatchTask(taskId string, request ShowBatchTaskRequest) (*ShowBatchTaskResponse, error) {
+	return client.ShowBatchTaskCtx(context.Background(), taskId, request)
+}
+
+func (client *syncClient) ShowBatchTaskCtx(ctx context.Context, taskId string, request ShowBatchTaskRequest) (*ShowBatchTaskResponse, error) {
+	response := &ShowBatchTaskResponse{}
+	err := client.invoke(ctx, opShowBatchTask, &apiRequest{
+		pathParams:  map[string]string{"task_id": taskId},
+		queryParams: pageQueryParams(request.Limit, request.Marker, request.Offset),
+	}, response)
 	if err != nil {
 		return nil, err
 	}
+
 	return response, nil
 }
 
-func (client *syncClient) ListDeviceCertificates(request ListDeviceCertificatesRequest) (*ListDeviceCertificatesResponse, error) {
-	rawRequest := client.client.R().
-		SetHeader("Content-Type", "application/json")
-	if request.Limit >= 1 && request.Limit <= 50 {
-		rawRequest.SetQueryParam("limit", strconv.Itoa(request.Limit))
-	} else {
-		rawRequest.SetQueryParam("limit", strconv.Itoa(10))
+func (client *syncClient) CreateBatchTask(request CreateBatchTaskRequest) (*BatchTask, error) {
+	return client.CreateBatchTaskCtx(context.Background(), request)
+}
+
+func (client *syncClient) CreateBatchTaskCtx(ctx context.Context, request CreateBatchTaskRequest) (*BatchTask, error) {
+	response := &BatchTask{}
+	err := client.invoke(ctx, opCreateBatchTask, &apiRequest{
+		body: request,
+	}, response)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(request.Marker) != 0 {
-		rawRequest.SetQueryParam("marker", request.Marker)
+	return response, nil
+}
+
+func (client *syncClient) ListBatchTasks(request ListBatchTasksRequest) (*ListBatchTasksResponse, error) {
+	return client.ListBatchTasksCtx(context.Background(), request)
+}
+
+func (client *syncClient) ListBatchTasksCtx(ctx context.Context, request ListBatchTasksRequest) (*ListBatchTasksResponse, error) {
+	queryParams := pageQueryParams(request.Limit, request.Marker, request.Offset)
+	queryParams["task_type"] = request.TaskType
+	if len(request.AppId) != 0 {
+		queryParams["app_id"] = request.AppId
 	}
 
-	if request.Offset >= 0 && request.Offset <= 500 {
-		rawRequest.SetQueryParam("offset", strconv.Itoa(request.Offset))
-	} else {
-		rawRequest.SetQueryParam("offset", strconv.Itoa(0))
+	if len(request.Status) != 0 {
+		queryParams["status"] = request.Status
 	}
 
-	if len(request.AppId) != 0 {
-		rawRequest.SetQueryParam("app_id", request.AppId)
+	response := &ListBatchTasksResponse{}
+	err := client.invoke(ctx, opListBatchTasks, &apiRequest{
+		queryParams: queryParams,
+	}, response)
+	if err != nil {
+		return nil, err
 	}
 
-	httpResponse, err := rawRequest.
-		Get("/v5/iot/{project_id}/certificates")
+	return response, nil
+}
+
+func (client *syncClient) DeleteRuleAction(actionId string) (bool, error) {
+	return client.DeleteRuleActionCtx(context.Background(), actionId)
+}
+
+func (client *syncClient) DeleteRuleActionCtx(ctx context.Context, actionId string) (bool, error) {
+	err := client.invoke(ctx, opDeleteRuleAction, &apiRequest{
+		pathParams: map[string]string{"action_id": actionId},
+	}, nil)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (client *syncClient) UpdateRuleAction(actionId string, request UpdateRuleActionRequest) (*RuleActionResponse, error) {
+	return client.UpdateRuleActionCtx(context.Background(), actionId, request)
+}
+
+func (client *syncClient) UpdateRuleActionCtx(ctx context.Context, actionId string, request UpdateRuleActionRequest) (*RuleActionResponse, error) {
+	response := &RuleActionResponse{}
+	err := client.invoke(ctx, opUpdateRuleAction, &apiRequest{
+		pathParams: map[string]string{"action_id": actionId},
+		body:       request,
+	}, response)
 	if err != nil {
 		return nil, err
 	}
 
-	if httpResponse.StatusCode() != 200 {
-		return nil, convertResponseToApplicationError(httpResponse)
+	return response, nil
+}
+
+func (client *syncClient) ShowRuleAction(actionId string) (*RuleActionResponse, error) {
+	return client.ShowRuleActionCtx(context.Background(), actionId)
+}
+
+func (client *syncClient) ShowRuleActionCtx(ctx context.Context, actionId string) (*RuleActionResponse, error) {
+	response := &RuleActionResponse{}
+	err := client.invoke(ctx, opShowRuleAction, &apiRequest{
+		pathParams: map[string]string{"action_id": actionId},
+	}, response)
+	if err != nil {
+		return nil, err
 	}
 
-	response := &ListDeviceCertificatesResponse{}
+	return response, nil
+}
 
-	err = json.Unmarshal(httpResponse.Body(), response)
+func (client *syncClient) CreateRuleAction(request CreateRuleActionRequest) (*RuleActionResponse, error) {
+	return client.CreateRuleActionCtx(context.Background(), request)
+}
+
+func (client *syncClient) CreateRuleActionCtx(ctx context.Context, request CreateRuleActionRequest) (*RuleActionResponse, error) {
+	response := &RuleActionResponse{}
+	err := client.invoke(ctx, opCreateRuleAction, &apiRequest{
+		body: request,
+	}, response)
 	if err != nil {
 		return nil, err
 	}
@@ -194,52 +452,96 @@ func (client *syncClient) ListDeviceCertificates(request ListDeviceCertificatesR
 	return response, nil
 }
 
-func (client *syncClient) ListDeviceByTags(request ListDeviceByTagsRequest) (*ListDeviceByTagsResponse, error) {
-	rawRequest := client.client.R().
-		SetHeader("Content-Type", "application/json")
-	if request.Limit >= 1 && request.Limit <= 50 {
-		rawRequest.SetQueryParam("limit", strconv.Itoa(request.Limit))
-	} else {
-		rawRequest.SetQueryParam("limit", strconv.Itoa(10))
+func (client *syncClient) ListRuleActions(request ListRuleActionsRequest) (*ListRuleActionsResponse, error) {
+	return client.ListRuleActionsCtx(context.Background(), request)
+}
+
+func (client *syncClient) ListRuleActionsCtx(ctx context.Context, request ListRuleActionsRequest) (*ListRuleActionsResponse, error) {
+	queryParams := pageQueryParams(request.Limit, request.Marker, request.Offset)
+	if len(request.RuleID) != 0 {
+		queryParams["rule_id"] = request.RuleID
 	}
 
-	if len(request.Marker) != 0 {
-		rawRequest.SetQueryParam("marker", request.Marker)
+	if len(request.Channel) != 0 {
+		queryParams["channel"] = request.Channel
 	}
 
-	if request.Offset >= 0 && request.Offset <= 500 {
-		rawRequest.SetQueryParam("offset", strconv.Itoa(request.Offset))
-	} else {
-		rawRequest.SetQueryParam("offset", strconv.Itoa(0))
+	if len(request.AppType) != 0 {
+		queryParams["app_type"] = request.AppType
 	}
 
-	requestBody := struct {
-		ResourceType string     `json:"resource_type,omitempty"`
-		Tags         []TagV5DTO `json:"tags,omitempty"`
-	}{
-		ResourceType: "device",
-		Tags:         request.Tags,
+	if len(request.AppId) != 0 {
+		queryParams["app_id"] = request.AppId
 	}
 
-	binaryRequest, err := json.Marshal(requestBody)
+	response := &ListRuleActionsResponse{}
+	err := client.invoke(ctx, opListRuleActions, &apiRequest{
+		queryParams: queryParams,
+	}, response)
 	if err != nil {
 		return nil, err
 	}
 
-	httpResponse, err := rawRequest.
-		SetBody(binaryRequest).
-		Post("/v5/iot/{project_id}/tags/query-resources")
+	return response, nil
+}
+
+func (client *syncClient) DeleteRoutingRule(ruleId string) (bool, error) {
+	return client.DeleteRoutingRuleCtx(context.Background(), ruleId)
+}
+
+func (client *syncClient) DeleteRoutingRuleCtx(ctx context.Context, ruleId string) (bool, error) {
+	err := client.invoke(ctx, opDeleteRoutingRule, &apiRequest{
+		pathParams: map[string]string{"rule_id": ruleId},
+	}, nil)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (client *syncClient) UpdateRoutingRule(ruleId string, request UpdateRoutingRuleRequest) (*RoutingRuleResponse, error) {
+	return client.UpdateRoutingRuleCtx(context.Background(), ruleId, request)
+}
+
+func (client *syncClient) UpdateRoutingRuleCtx(ctx context.Context, ruleId string, request UpdateRoutingRuleRequest) (*RoutingRuleResponse, error) {
+	response := &RoutingRuleResponse{}
+	err := client.invoke(ctx, opUpdateRoutingRule, &apiRequest{
+		pathParams: map[string]string{"rule_id": ruleId},
+		body:       request,
+	}, response)
 	if err != nil {
 		return nil, err
 	}
 
-	if httpResponse.StatusCode() != 200 {
-		return nil, convertResponseToApplicationError(httpResponse)
+	return response, nil
+}
+
+func (client *syncClient) ShowRoutingRule(ruleId string) (*RoutingRuleResponse, error) {
+	return client.ShowRoutingRuleCtx(context.Background(), ruleId)
+}
+
+func (client *syncClient) ShowRoutingRuleCtx(ctx context.Context, ruleId string) (*RoutingRuleResponse, error) {
+	response := &RoutingRuleResponse{}
+	err := client.invoke(ctx, opShowRoutingRule, &apiRequest{
+		pathParams: map[string]string{"rule_id": ruleId},
+	}, response)
+	if err != nil {
+		return nil, err
 	}
 
-	response := &ListDeviceByTagsResponse{}
+	return response, nil
+}
 
-	err = json.Unmarshal(httpResponse.Body(), response)
+func (client *syncClient) CreateRoutingRule(request CreateRoutingRuleRequest) (*RoutingRuleResponse, error) {
+	return client.CreateRoutingRuleCtx(context.Background(), request)
+}
+
+func (client *syncClient) CreateRoutingRuleCtx(ctx context.Context, request CreateRoutingRuleRequest) (*RoutingRuleResponse, error) {
+	response := &RoutingRuleResponse{}
+	err := client.invoke(ctx, opCreateRoutingRule, &apiRequest{
+		body: request,
+	}, response)
 	if err != nil {
 		return nil, err
 	}
@@ -247,81 +549,212 @@ func (client *syncClient) ListDeviceByTags(request ListDeviceByTagsRequest) (*Li
 	return response, nil
 }
 
-func (client *syncClient) DeviceUnBindTags(request DeviceUnBindTagsRequest) (bool, error) {
-	binaryRequest, err := json.Marshal(request)
+func (client *syncClient) ListRoutingRules(request ListRoutingRulesRequest) (*ListRoutingRulesResponse, error) {
+	return client.ListRoutingRulesCtx(context.Background(), request)
+}
+
+func (client *syncClient) ListRoutingRulesCtx(ctx context.Context, request ListRoutingRulesRequest) (*ListRoutingRulesResponse, error) {
+	queryParams := pageQueryParams(request.Limit, request.Marker, request.Offset)
+	if len(request.Resource) != 0 {
+		queryParams["resource"] = request.Resource
+	}
+
+	if len(request.Event) != 0 {
+		queryParams["event"] = request.Event
+	}
+
+	if len(request.AppType) != 0 {
+		queryParams["app_type"] = request.AppType
+	}
+
+	if len(request.AppId) != 0 {
+		queryParams["app_id"] = request.AppId
+	}
+
+	if len(request.RuleName) != 0 {
+		queryParams["rule_name"] = request.RuleName
+	}
+
+	if request.Active != nil {
+		queryParams["active"] = strconv.FormatBool(*request.Active)
+	}
+
+	response := &ListRoutingRulesResponse{}
+	err := client.invoke(ctx, opListRoutingRules, &apiRequest{
+		queryParams: queryParams,
+	}, response)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	httpResponse, err := client.client.R().
-		SetHeader("Content-Type", "application/json").
-		SetBody(binaryRequest).
-		Post("/v5/iot/{project_id}/tags/unbind-resource")
+	return response, nil
+}
+
+func (client *syncClient) DeleteProduct(productId, appId string) (bool, error) {
+	return client.DeleteProductCtx(context.Background(), productId, appId)
+}
+
+func (client *syncClient) DeleteProductCtx(ctx context.Context, productId, appId string) (bool, error) {
+	queryParams := map[string]string{}
+	if len(appId) != 0 {
+		queryParams["app_id"] = appId
+	}
+
+	err := client.invoke(ctx, opDeleteProduct, &apiRequest{
+		pathParams:  map[string]string{"product_id": productId},
+		queryParams: queryParams,
+	}, nil)
 	if err != nil {
 		return false, err
 	}
 
-	if httpResponse.StatusCode() != 200 {
-		return false, convertResponseToApplicationError(httpResponse)
+	return true, nil
+}
+
+func (client *syncClient) UpdateProduct(productId string, request UpdateProductRequest) (*ProductDetailResponse, error) {
+	return client.UpdateProductCtx(context.Background(), productId, request)
+}
+
+func (client *syncClient) UpdateProductCtx(ctx context.Context, productId string, request UpdateProductRequest) (*ProductDetailResponse, error) {
+	response := &ProductDetailResponse{}
+	err := client.invoke(ctx, opUpdateProduct, &apiRequest{
+		pathParams: map[string]string{"product_id": productId},
+		body:       request,
+	}, response)
+	if err != nil {
+		return nil, err
 	}
 
-	return true, nil
+	return response, nil
 }
 
-func (client *syncClient) DeviceBindTags(request DeviceBindTagsRequest) (bool, error) {
-	binaryRequest, err := json.Marshal(request)
+func (client *syncClient) ShowProduct(productId, appId string) (*ProductDetailResponse, error) {
+	return client.ShowProductCtx(context.Background(), productId, appId)
+}
+
+func (client *syncClient) ShowProductCtx(ctx context.Context, productId, appId string) (*ProductDetailResponse, error) {
+	queryParams := map[string]string{}
+	if len(appId) != 0 {
+		queryParams["app_id"] = appId
+	}
+
+	response := &ProductDetailResponse{}
+	err := client.invoke(ctx, opShowProduct, &apiRequest{
+		pathParams:  map[string]string{"product_id": productId},
+		queryParams: queryParams,
+	}, response)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	httpResponse, err := client.client.R().
-		SetHeader("Content-Type", "application/json").
-		SetBody(binaryRequest).
-		Post("/v5/iot/{project_id}/tags/bind-resource")
+	return response, nil
+}
+
+func (client *syncClient) CreateProduct(request CreateProductRequest) (*ProductDetailResponse, error) {
+	return client.CreateProductCtx(context.Background(), request)
+}
+
+func (client *syncClient) CreateProductCtx(ctx context.Context, request CreateProductRequest) (*ProductDetailResponse, error) {
+	response := &ProductDetailResponse{}
+	err := client.invoke(ctx, opCreateProduct, &apiRequest{
+		body: request,
+	}, response)
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (client *syncClient) ListProducts(request ListProductsRequest) (*ListProductsResponse, error) {
+	return client.ListProductsCtx(context.Background(), request)
+}
+
+func (client *syncClient) ListProductsCtx(ctx context.Context, request ListProductsRequest) (*ListProductsResponse, error) {
+	queryParams := pageQueryParams(request.Limit, request.Marker, request.Offset)
+	if len(request.AppId) != 0 {
+		queryParams["app_id"] = request.AppId
 	}
 
-	if httpResponse.StatusCode() != 200 {
-		return false, convertResponseToApplicationError(httpResponse)
+	response := &ListProductsResponse{}
+	err := client.invoke(ctx, opListProducts, &apiRequest{
+		queryParams: queryParams,
+	}, response)
+	if err != nil {
+		return nil, err
 	}
 
-	return true, nil
+	return response, nil
 }
 
-func (client *syncClient) ListDeviceInDeviceGroup(deviceGroupId string, request ListDeviceInDeviceGroupRequest) (*ListDeviceInDeviceGroupRequest, error) {
-	rawRequest := client.client.R().
-		SetHeader("Content-Type", "application/json")
-	if request.Limit >= 1 && request.Limit <= 50 {
-		rawRequest.SetQueryParam("limit", strconv.Itoa(request.Limit))
-	} else {
-		rawRequest.SetQueryParam("limit", strconv.Itoa(10))
+func (client *syncClient) VerifyDeviceCertificates(certificateId, verifyContent string) (bool, error) {
+	return client.VerifyDeviceCertificatesCtx(context.Background(), certificateId, verifyContent)
+}
+
+func (client *syncClient) VerifyDeviceCertificatesCtx(ctx context.Context, certificateId, verifyContent string) (bool, error) {
+	requestBody := struct {
+		VerifyContent string `json:"verify_content"`
+	}{
+		VerifyContent: verifyContent,
 	}
 
-	if len(request.Marker) != 0 {
-		rawRequest.SetQueryParam("marker", request.Marker)
+	err := client.invoke(ctx, opVerifyDeviceCertificates, &apiRequest{
+		pathParams:  map[string]string{"certificate_id": certificateId},
+		queryParams: map[string]string{"action_id": "verify"},
+		body:        requestBody,
+	}, nil)
+	if err != nil {
+		return false, err
 	}
 
-	if request.Offset >= 0 && request.Offset <= 500 {
-		rawRequest.SetQueryParam("offset", strconv.Itoa(request.Offset))
-	} else {
-		rawRequest.SetQueryParam("offset", strconv.Itoa(0))
+	return true, nil
+}
+
+func (client *syncClient) DeleteDeviceCertificates(certificateId string) (bool, error) {
+	return client.DeleteDeviceCertificatesCtx(context.Background(), certificateId)
+}
+
+func (client *syncClient) DeleteDeviceCertificatesCtx(ctx context.Context, certificateId string) (bool, error) {
+	err := client.invoke(ctx, opDeleteDeviceCertificates, &apiRequest{
+		pathParams: map[string]string{"certificate_id": certificateId},
+	}, nil)
+	if err != nil {
+		return false, err
 	}
 
-	httpResponse, err := rawRequest.
-		SetPathParam("group_id", deviceGroupId).
-		Get("/v5/iot/{project_id}/device-group/{group_id}/devices")
+	return true, nil
+}
+
+func (client *syncClient) UploadDeviceCertificates(request UploadDeviceCertificatesRequest) (*UploadDeviceCertificatesResponse, error) {
+	return client.UploadDeviceCertificatesCtx(context.Background(), request)
+}
+
+func (client *syncClient) UploadDeviceCertificatesCtx(ctx context.Context, request UploadDeviceCertificatesRequest) (*UploadDeviceCertificatesResponse, error) {
+	response := &UploadDeviceCertificatesResponse{}
+	err := client.invoke(ctx, opUploadDeviceCertificates, &apiRequest{
+		body: request,
+	}, response)
 	if err != nil {
 		return nil, err
 	}
 
-	if httpResponse.StatusCode() != 200 {
-		return nil, convertResponseToApplicationError(httpResponse)
-	}
+	return response, nil
+}
 
-	response := &ListDeviceInDeviceGroupRequest{}
+func (client *syncClient) ListDeviceCertificates(request ListDeviceCertificatesRequest) (*ListDeviceCertificatesResponse, error) {
+	return client.ListDeviceCertificatesCtx(context.Background(), request)
+}
 
-	err = json.Unmarshal(httpResponse.Body(), response)
+func (client *syncClient) ListDeviceCertificatesCtx(ctx context.Context, request ListDeviceCertificatesRequest) (*ListDeviceCertificatesResponse, error) {
+	queryParams := pageQueryParams(request.Limit, request.Marker, request.Offset)
+	if len(request.AppId) != 0 {
+		queryParams["app_id"] = request.AppId
+	}
+
+	response := &ListDeviceCertificatesResponse{}
+	err := client.invoke(ctx, opListDeviceCertificates, &apiRequest{
+		queryParams: queryParams,
+	}, response)
 	if err != nil {
 		return nil, err
 	}
@@ -329,71 +762,127 @@ func (client *syncClient) ListDeviceInDeviceGroup(deviceGroupId string, request
 	return response, nil
 }
 
-func (client *syncClient) AddDeviceToDeviceGroup(deviceGroupId, deviceId string) (bool, error) {
-	return client.manageDeviceGroupDevices(deviceGroupId, "addDevice", deviceId)
+func (client *syncClient) ListDeviceByTags(request ListDeviceByTagsRequest) (*ListDeviceByTagsResponse, error) {
+	return client.ListDeviceByTagsCtx(context.Background(), request)
+}
+
+func (client *syncClient) ListDeviceByTagsCtx(ctx context.Context, request ListDeviceByTagsRequest) (*ListDeviceByTagsResponse, error) {
+	requestBody := struct {
+		ResourceType string     `json:"resource_type,omitempty"`
+		Tags         []TagV5DTO `json:"tags,omitempty"`
+	}{
+		ResourceType: "device",
+		Tags:         request.Tags,
+	}
 
+	response := &ListDeviceByTagsResponse{}
+	err := client.invoke(ctx, opListDeviceByTags, &apiRequest{
+		queryParams: pageQueryParams(request.Limit, request.Marker, request.Offset),
+		body:        requestBody,
+	}, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
 }
 
-func (client *syncClient) RemoveDeviceFromDeviceGroup(deviceGroupId, deviceId string) (bool, error) {
-	return client.manageDeviceGroupDevices(deviceGroupId, "removeDevice", deviceId)
+func (client *syncClient) DeviceUnBindTags(request DeviceUnBindTagsRequest) (bool, error) {
+	return client.DeviceUnBindTagsCtx(context.Background(), request)
 }
 
-func (client *syncClient) manageDeviceGroupDevices(deviceGroupId, actionId, deviceId string) (bool, error) {
-	httpResponse, err := client.client.R().
-		SetPathParam("group_id", deviceGroupId).
-		SetQueryParam("action_id", actionId).
-		SetQueryParam("device_id", deviceId).
-		Post("/v5/iot/{project_id}/device-group/{group_id}/action")
+func (client *syncClient) DeviceUnBindTagsCtx(ctx context.Context, request DeviceUnBindTagsRequest) (bool, error) {
+	err := client.invoke(ctx, opDeviceUnBindTags, &apiRequest{
+		body: request,
+	}, nil)
 	if err != nil {
 		return false, err
 	}
 
-	if httpResponse.StatusCode() != 200 {
-		return false, convertResponseToApplicationError(httpResponse)
+	return true, nil
+}
+
+func (client *syncClient) DeviceBindTags(request DeviceBindTagsRequest) (bool, error) {
+	return client.DeviceBindTagsCtx(context.Background(), request)
+}
+
+func (client *syncClient) DeviceBindTagsCtx(ctx context.Context, request DeviceBindTagsRequest) (bool, error) {
+	err := client.invoke(ctx, opDeviceBindTags, &apiRequest{
+		body: request,
+	}, nil)
+	if err != nil {
+		return false, err
 	}
 
 	return true, nil
 }
-func (client *syncClient) ListDeviceGroups(request ListDeviceGroupRequest) (*ListDeviceGroupResponse, error) {
-	rawRequest := client.client.R().
-		SetHeader("Content-Type", "application/json")
-	if request.Limit >= 1 && request.Limit <= 50 {
-		rawRequest.SetQueryParam("limit", strconv.Itoa(request.Limit))
-	} else {
-		rawRequest.SetQueryParam("limit", strconv.Itoa(10))
-	}
 
-	if len(request.Marker) != 0 {
-		rawRequest.SetQueryParam("marker", request.Marker)
+func (client *syncClient) ListDeviceInDeviceGroup(deviceGroupId string, request ListDeviceInDeviceGroupRequest) (*ListDeviceInDeviceGroupResponse, error) {
+	return client.ListDeviceInDeviceGroupCtx(context.Background(), deviceGroupId, request)
+}
+
+func (client *syncClient) ListDeviceInDeviceGroupCtx(ctx context.Context, deviceGroupId string, request ListDeviceInDeviceGroupRequest) (*ListDeviceInDeviceGroupResponse, error) {
+	response := &ListDeviceInDeviceGroupResponse{}
+	err := client.invoke(ctx, opListDeviceInDeviceGroup, &apiRequest{
+		pathParams:  map[string]string{"group_id": deviceGroupId},
+		queryParams: pageQueryParams(request.Limit, request.Marker, request.Offset),
+	}, response)
+	if err != nil {
+		return nil, err
 	}
 
-	if request.Offset >= 0 && request.Offset <= 500 {
-		rawRequest.SetQueryParam("offset", strconv.Itoa(request.Offset))
-	} else {
-		rawRequest.SetQueryParam("offset", strconv.Itoa(0))
+	return response, nil
+}
+
+func (client *syncClient) AddDeviceToDeviceGroup(deviceGroupId, deviceId string) (bool, error) {
+	return client.AddDeviceToDeviceGroupCtx(context.Background(), deviceGroupId, deviceId)
+}
+
+func (client *syncClient) AddDeviceToDeviceGroupCtx(ctx context.Context, deviceGroupId, deviceId string) (bool, error) {
+	return client.manageDeviceGroupDevices(ctx, deviceGroupId, "addDevice", deviceId)
+
+}
+
+func (client *syncClient) RemoveDeviceFromDeviceGroup(deviceGroupId, deviceId string) (bool, error) {
+	return client.RemoveDeviceFromDeviceGroupCtx(context.Background(), deviceGroupId, deviceId)
+}
+
+func (client *syncClient) RemoveDeviceFromDeviceGroupCtx(ctx context.Context, deviceGroupId, deviceId string) (bool, error) {
+	return client.manageDeviceGroupDevices(ctx, deviceGroupId, "removeDevice", deviceId)
+}
+
+func (client *syncClient) manageDeviceGroupDevices(ctx context.Context, deviceGroupId, actionId, deviceId string) (bool, error) {
+	err := client.invoke(ctx, opManageDeviceGroupDevices, &apiRequest{
+		pathParams: map[string]string{"group_id": deviceGroupId},
+		queryParams: map[string]string{
+			"action_id": actionId,
+			"device_id": deviceId,
+		},
+	}, nil)
+	if err != nil {
+		return false, err
 	}
 
+	return true, nil
+}
+func (client *syncClient) ListDeviceGroups(request ListDeviceGroupRequest) (*ListDeviceGroupResponse, error) {
+	return client.ListDeviceGroupsCtx(context.Background(), request)
+}
+
+func (client *syncClient) ListDeviceGroupsCtx(ctx context.Context, request ListDeviceGroupRequest) (*ListDeviceGroupResponse, error) {
+	queryParams := pageQueryParams(request.Limit, request.Marker, request.Offset)
 	if len(request.LastModifiedTime) != 0 {
-		rawRequest.SetQueryParam("last_modified_time", request.LastModifiedTime)
+		queryParams["last_modified_time"] = request.LastModifiedTime
 	}
 
 	if len(request.AppId) != 0 {
-		rawRequest.SetQueryParam("app_id", request.AppId)
-	}
-
-	httpResponse, err := rawRequest.
-		Get("/v5/iot/{project_id}/device-group")
-	if err != nil {
-		return nil, err
-	}
-
-	if httpResponse.StatusCode() != 200 {
-		return nil, convertResponseToApplicationError(httpResponse)
+		queryParams["app_id"] = request.AppId
 	}
 
 	response := &ListDeviceGroupResponse{}
-
-	err = json.Unmarshal(httpResponse.Body(), response)
+	err := client.invoke(ctx, opListDeviceGroups, &apiRequest{
+		queryParams: queryParams,
+	}, response)
 	if err != nil {
 		return nil, err
 	}
@@ -402,41 +891,30 @@ func (client *syncClient) ListDeviceGroups(request ListDeviceGroupRequest) (*Lis
 }
 
 func (client *syncClient) DeleteDeviceGroup(deviceGroupId string) (bool, error) {
-	httpResponse, err := client.client.R().
-		SetPathParam("group_id", deviceGroupId).
-		Delete("/v5/iot/{project_id}/device-group/{group_id}")
+	return client.DeleteDeviceGroupCtx(context.Background(), deviceGroupId)
+}
+
+func (client *syncClient) DeleteDeviceGroupCtx(ctx context.Context, deviceGroupId string) (bool, error) {
+	err := client.invoke(ctx, opDeleteDeviceGroup, &apiRequest{
+		pathParams: map[string]string{"group_id": deviceGroupId},
+	}, nil)
 	if err != nil {
 		return false, err
 	}
 
-	if httpResponse.StatusCode() != 200 {
-		return false, convertResponseToApplicationError(httpResponse)
-	}
-
 	return true, nil
 }
 
 func (client *syncClient) UpdateDeviceGroup(deviceGroupId string, request UpdateDeviceGroupRequest) (*UpdateDeviceGroupResponse, error) {
-	binaryRequest, err := json.Marshal(request)
-	if err != nil {
-		return nil, err
-	}
-
-	httpResponse, err := client.client.R().
-		SetPathParam("group_id", deviceGroupId).
-		SetBody(binaryRequest).
-		Get("/v5/iot/{project_id}/device-group/{group_id}")
-	if err != nil {
-		return nil, err
-	}
-
-	if httpResponse.StatusCode() != 200 {
-		return nil, convertResponseToApplicationError(httpResponse)
-	}
+	return client.UpdateDeviceGroupCtx(context.Background(), deviceGroupId, request)
+}
 
+func (client *syncClient) UpdateDeviceGroupCtx(ctx context.Context, deviceGroupId string, request UpdateDeviceGroupRequest) (*UpdateDeviceGroupResponse, error) {
 	response := &UpdateDeviceGroupResponse{}
-
-	err = json.Unmarshal(httpResponse.Body(), response)
+	err := client.invoke(ctx, opUpdateDeviceGroup, &apiRequest{
+		pathParams: map[string]string{"group_id": deviceGroupId},
+		body:       request,
+	}, response)
 	if err != nil {
 		return nil, err
 	}
@@ -445,20 +923,14 @@ func (client *syncClient) UpdateDeviceGroup(deviceGroupId string, request Update
 }
 
 func (client *syncClient) ShowDeviceGroup(deviceGroupId string) (*ShowDeviceGroupResponse, error) {
-	httpResponse, err := client.client.R().
-		SetPathParam("group_id", deviceGroupId).
-		Get("/v5/iot/{project_id}/device-group/{group_id}")
-	if err != nil {
-		return nil, err
-	}
-
-	if httpResponse.StatusCode() != 200 {
-		return nil, convertResponseToApplicationError(httpResponse)
-	}
+	return client.ShowDeviceGroupCtx(context.Background(), deviceGroupId)
+}
 
+func (client *syncClient) ShowDeviceGroupCtx(ctx context.Context, deviceGroupId string) (*ShowDeviceGroupResponse, error) {
 	response := &ShowDeviceGroupResponse{}
-
-	err = json.Unmarshal(httpResponse.Body(), response)
+	err := client.invoke(ctx, opShowDeviceGroup, &apiRequest{
+		pathParams: map[string]string{"group_id": deviceGroupId},
+	}, response)
 	if err != nil {
 		return nil, err
 	}
@@ -467,26 +939,14 @@ func (client *syncClient) ShowDeviceGroup(deviceGroupId string) (*ShowDeviceGrou
 }
 
 func (client *syncClient) CreateDeviceGroup(request CreateDeviceGroupRequest) (*CreateDeviceGroupResponse, error) {
-	binaryRequest, err := json.Marshal(request)
-	if err != nil {
-		return nil, err
-	}
-
-	httpResponse, err := client.client.R().
-		SetHeader("Content-Type", "application/json").
-		SetBody(binaryRequest).
-		Post("/v5/iot/{project_id}/device-group")
-	if err != nil {
-		return nil, err
-	}
-
-	if httpResponse.StatusCode() != 201 {
-		return nil, convertResponseToApplicationError(httpResponse)
-	}
+	return client.CreateDeviceGroupCtx(context.Background(), request)
+}
 
+func (client *syncClient) CreateDeviceGroupCtx(ctx context.Context, request CreateDeviceGroupRequest) (*CreateDeviceGroupResponse, error) {
 	response := &CreateDeviceGroupResponse{}
-
-	err = json.Unmarshal(httpResponse.Body(), response)
+	err := client.invoke(ctx, opCreateDeviceGroup, &apiRequest{
+		body: request,
+	}, response)
 	if err != nil {
 		return nil, err
 	}
@@ -495,26 +955,15 @@ func (client *syncClient) CreateDeviceGroup(request CreateDeviceGroupRequest) (*
 }
 
 func (client *syncClient) UpdateDeviceShadow(deviceId string, request UpdateDeviceShadowRequest) (*ShowDeviceShadowResponse, error) {
-	binaryRequest, err := json.Marshal(request)
-	if err != nil {
-		return nil, err
-	}
-
-	httpResponse, err := client.client.R().
-		SetPathParam("device_id", deviceId).
-		SetBody(binaryRequest).
-		Put("/v5/iot/{project_id}/devices/{device_id}/shadow")
-	if err != nil {
-		return nil, err
-	}
-
-	if httpResponse.StatusCode() != 200 {
-		return nil, convertResponseToApplicationError(httpResponse)
-	}
+	return client.UpdateDeviceShadowCtx(context.Background(), deviceId, request)
+}
 
+func (client *syncClient) UpdateDeviceShadowCtx(ctx context.Context, deviceId string, request UpdateDeviceShadowRequest) (*ShowDeviceShadowResponse, error) {
 	response := &ShowDeviceShadowResponse{}
-
-	err = json.Unmarshal(httpResponse.Body(), response)
+	err := client.invoke(ctx, opUpdateDeviceShadow, &apiRequest{
+		pathParams: map[string]string{"device_id": deviceId},
+		body:       request,
+	}, response)
 	if err != nil {
 		return nil, err
 	}
@@ -522,20 +971,14 @@ func (client *syncClient) UpdateDeviceShadow(deviceId string, request UpdateDevi
 	return response, nil
 }
 func (client *syncClient) ShowDeviceShadow(deviceId string) (*ShowDeviceShadowResponse, error) {
-	response, err := client.client.R().
-		SetPathParam("device_id", deviceId).
-		Get("/v5/iot/{project_id}/devices/{device_id}/shadow")
-	if err != nil {
-		return nil, err
-	}
-
-	if response.StatusCode() != 200 {
-		return nil, convertResponseToApplicationError(response)
-	}
+	return client.ShowDeviceShadowCtx(context.Background(), deviceId)
+}
 
+func (client *syncClient) ShowDeviceShadowCtx(ctx context.Context, deviceId string) (*ShowDeviceShadowResponse, error) {
 	result := &ShowDeviceShadowResponse{}
-
-	err = json.Unmarshal(response.Body(), result)
+	err := client.invoke(ctx, opShowDeviceShadow, &apiRequest{
+		pathParams: map[string]string{"device_id": deviceId},
+	}, result)
 	if err != nil {
 		return nil, err
 	}
@@ -544,75 +987,52 @@ func (client *syncClient) ShowDeviceShadow(deviceId string) (*ShowDeviceShadowRe
 }
 
 func (client *syncClient) CreateAccessCode(accessType string) (*CreateAccessCodeResponse, error) {
-	glog.Infof("begin to create access code for type %s", accessType)
+	return client.CreateAccessCodeCtx(context.Background(), accessType)
+}
+
+func (client *syncClient) CreateAccessCodeCtx(ctx context.Context, accessType string) (*CreateAccessCodeResponse, error) {
 	req := struct {
 		Type string `json:"type"`
 	}{
 		Type: "AMQP",
 	}
 
-	reqBytes, err := json.Marshal(req)
-	if err != nil {
-		return nil, err
-	}
-
-	response, err := client.client.R().
-		SetHeader("Content-Type", "application/json").
-		SetBody(reqBytes).
-		Post("/v5/iot/{project_id}/auth/accesscode")
-	if err != nil {
-		return nil, err
-	}
-
-	if response.StatusCode() != 201 {
-		return nil, convertResponseToApplicationError(response)
-	}
-
 	resp := &CreateAccessCodeResponse{}
-
-	err = json.Unmarshal(response.Body(), resp)
+	err := client.invoke(ctx, opCreateAccessCode, &apiRequest{
+		body: req,
+	}, resp)
 	if err != nil {
 		return nil, err
 	}
+
 	return resp, nil
 }
 
 func (client *syncClient) DeleteAmqpQueue(queueId string) (bool, error) {
-	glog.Infof("begin to delete amqp queue with id %s", queueId)
-	response, err := client.client.R().
-		SetHeader("Content-Type", "application/json").
-		SetPathParam("queue_id", queueId).
-		Delete("v5/iot/{project_id}/amqp-queues/{queue_id}")
+	return client.DeleteAmqpQueueCtx(context.Background(), queueId)
+}
+
+func (client *syncClient) DeleteAmqpQueueCtx(ctx context.Context, queueId string) (bool, error) {
+	err := client.invoke(ctx, opDeleteAmqpQueue, &apiRequest{
+		pathParams: map[string]string{"queue_id": queueId},
+	}, nil)
 	if err != nil {
 		return false, err
 	}
 
-	if response.StatusCode() != 204 {
-		glog.Warningf("delete amqp queue response code is %d", response.StatusCode())
-		return false, convertResponseToApplicationError(response)
-	}
-
 	return true, nil
 
 }
 
 func (client *syncClient) ShowAmqpQueue(queueId string) (*ShowAmqpQueueResponse, error) {
-	response, err := client.client.R().
-		SetHeader("Content-Type", "application/json").
-		SetPathParam("queue_id", queueId).
-		Get("v5/iot/{project_id}/amqp-queues/{queue_id}")
-	if err != nil {
-		return nil, err
-	}
-
-	if response.StatusCode() != 200 {
-		err = convertResponseToApplicationError(response)
-		return nil, err
-	}
+	return client.ShowAmqpQueueCtx(context.Background(), queueId)
+}
 
+func (client *syncClient) ShowAmqpQueueCtx(ctx context.Context, queueId string) (*ShowAmqpQueueResponse, error) {
 	resp := &ShowAmqpQueueResponse{}
-
-	err = json.Unmarshal(response.Body(), resp)
+	err := client.invoke(ctx, opShowAmqpQueue, &apiRequest{
+		pathParams: map[string]string{"queue_id": queueId},
+	}, resp)
 	if err != nil {
 		return nil, err
 	}
@@ -621,29 +1041,18 @@ func (client *syncClient) ShowAmqpQueue(queueId string) (*ShowAmqpQueueResponse,
 }
 
 func (client *syncClient) CreateAmqpQueue(queueName string) (*CreateAmqpQueueResponse, error) {
+	return client.CreateAmqpQueueCtx(context.Background(), queueName)
+}
+
+func (client *syncClient) CreateAmqpQueueCtx(ctx context.Context, queueName string) (*CreateAmqpQueueResponse, error) {
 	createAmqpRequest := struct {
 		QueueName string `json:"queue_name,omitempty"`
 	}{QueueName: queueName}
 
-	requestBytes, err := json.Marshal(createAmqpRequest)
-	if err != nil {
-		return nil, nil
-	}
-	response, err := client.client.R().
-		SetHeader("Content-Type", "application/json").
-		SetBody(requestBytes).
-		Post("/v5/iot/{project_id}/amqp-queues")
-	if err != nil {
-		return nil, err
-	}
-
-	if response.StatusCode() != 201 {
-		return nil, convertResponseToApplicationError(response)
-	}
-
 	resp := &CreateAmqpQueueResponse{}
-
-	err = json.Unmarshal(response.Body(), resp)
+	err := client.invoke(ctx, opCreateAmqpQueue, &apiRequest{
+		body: createAmqpRequest,
+	}, resp)
 	if err != nil {
 		return nil, err
 	}
@@ -652,38 +1061,27 @@ func (client *syncClient) CreateAmqpQueue(queueName string) (*CreateAmqpQueueRes
 }
 
 func (client *syncClient) ListAmqpQueues(req ListAmqpQueuesRequest) (*ListAmqpQueuesResponse, error) {
-	queryParas := map[string]string{}
-	if len(req.QueueName) != 0 {
-		queryParas["queue_name"] = req.QueueName
-	}
-	if req.Limit == 0 {
-		queryParas["limit"] = "10"
-	} else {
-		queryParas["limit"] = strconv.Itoa(req.Limit)
-	}
-	if len(req.Marker) != 0 {
-		queryParas["marker"] = req.Marker
-	}
-	if len(req.Offset) != 0 {
-		queryParas["offset"] = req.Offset
-	}
-
-	response, err := client.client.R().
-		SetHeader("Content-Type", "application/json").
-		SetQueryParams(queryParas).
-		Get("/v5/iot/{project_id}/amqp-queues")
+	return client.ListAmqpQueuesCtx(context.Background(), req)
+}
 
-	if err != nil {
-		return nil, err
+func (client *syncClient) ListAmqpQueuesCtx(ctx context.Context, req ListAmqpQueuesRequest) (*ListAmqpQueuesResponse, error) {
+	offset := 0
+	if len(req.Offset) != 0 {
+		var err error
+		if offset, err = strconv.Atoi(req.Offset); err != nil {
+			return nil, fmt.Errorf("invalid offset %s: %w", req.Offset, err)
+		}
 	}
 
-	if response.StatusCode() != 200 {
-		return nil, convertResponseToApplicationError(response)
+	queryParas := pageQueryParams(req.Limit, req.Marker, offset)
+	if len(req.QueueName) != 0 {
+		queryParas["queue_name"] = req.QueueName
 	}
 
 	resp := &ListAmqpQueuesResponse{}
-
-	err = json.Unmarshal(response.Body(), resp)
+	err := client.invoke(ctx, opListAmqpQueues, &apiRequest{
+		queryParams: queryParas,
+	}, resp)
 	if err != nil {
 		return nil, err
 	}
@@ -692,99 +1090,83 @@ func (client *syncClient) ListAmqpQueues(req ListAmqpQueuesRequest) (*ListAmqpQu
 }
 
 func (client *syncClient) ResetDeviceSecret(deviceId, secret string, forceDisconnect bool) (*ResetDeviceSecretResponse, error) {
+	return client.ResetDeviceSecretCtx(context.Background(), deviceId, secret, forceDisconnect)
+}
+
+func (client *syncClient) ResetDeviceSecretCtx(ctx context.Context, deviceId, secret string, forceDisconnect bool) (*ResetDeviceSecretResponse, error) {
 	resetSecret := struct {
 		Secret          string `json:"secret,omitempty"`
 		ForceDisconnect bool   `json:"force_disconnect,omitempty"`
 	}{Secret: secret, ForceDisconnect: forceDisconnect}
 
-	body, err := json.Marshal(resetSecret)
-	if err != nil {
-		return nil, err
-	}
-	response, err := client.client.R().
-		SetHeader("Content-Type", "application/json").
-		SetBody(body).
-		SetPathParams(map[string]string{
-			"device_id": deviceId,
-		}).
-		SetQueryParams(map[string]string{
-			"action_id": "resetSecret",
-		}).
-		Post("/v5/iot/{project_id}/devices/{device_id}/action")
-	if err != nil {
-		return nil, err
-	}
-
 	resp := &ResetDeviceSecretResponse{}
-
-	err = json.Unmarshal(response.Body(), resp)
+	err := client.invoke(ctx, opResetDeviceSecret, &apiRequest{
+		pathParams:  map[string]string{"device_id": deviceId},
+		queryParams: map[string]string{"action_id": "resetSecret"},
+		body:        resetSecret,
+	}, resp)
 	if err != nil {
 		return nil, err
 	}
+
 	return resp, nil
 }
 
 func (client *syncClient) FreezeDevice(deviceId string) (bool, error) {
-	response, err := client.client.R().
-		SetHeader("Content-Type", "application/json").
-		SetPathParams(map[string]string{
-			"device_id": deviceId,
-		}).
-		Post("/v5/iot/{project_id}/devices/{device_id}/freeze")
+	return client.FreezeDeviceCtx(context.Background(), deviceId)
+}
+
+func (client *syncClient) FreezeDeviceCtx(ctx context.Context, deviceId string) (bool, error) {
+	err := client.invoke(ctx, opFreezeDevice, &apiRequest{
+		pathParams: map[string]string{"device_id": deviceId},
+	}, nil)
 	if err != nil {
 		return false, err
 	}
 
-	return response.StatusCode() == 204, nil
+	return true, nil
 }
 
 func (client *syncClient) UnFreezeDevice(deviceId string) (bool, error) {
-	response, err := client.client.R().
-		SetHeader("Content-Type", "application/json").
-		SetPathParams(map[string]string{
-			"device_id": deviceId,
-		}).
-		Post("/v5/iot/{project_id}/devices/{device_id}/unfreeze")
+	return client.UnFreezeDeviceCtx(context.Background(), deviceId)
+}
+
+func (client *syncClient) UnFreezeDeviceCtx(ctx context.Context, deviceId string) (bool, error) {
+	err := client.invoke(ctx, opUnFreezeDevice, &apiRequest{
+		pathParams: map[string]string{"device_id": deviceId},
+	}, nil)
 	if err != nil {
 		return false, err
 	}
 
-	return response.StatusCode() == 204, nil
+	return true, nil
 }
 
 func (client *syncClient) DeleteDevice(deviceId string) (bool, error) {
-	response, err := client.client.R().
-		SetHeader("Content-Type", "application/json").
-		SetPathParams(map[string]string{
-			"device_id": deviceId,
-		}).
-		Delete("/v5/iot/{project_id}/devices/{device_id}")
+	return client.DeleteDeviceCtx(context.Background(), deviceId)
+}
+
+func (client *syncClient) DeleteDeviceCtx(ctx context.Context, deviceId string) (bool, error) {
+	err := client.invoke(ctx, opDeleteDevice, &apiRequest{
+		pathParams: map[string]string{"device_id": deviceId},
+	}, nil)
 	if err != nil {
-		return false, nil
+		return false, err
 	}
 
-	return response.StatusCode() == 204, nil
+	return true, nil
 }
 
 func (client *syncClient) UpdateDevice(deviceId string, request UpdateDeviceRequest) (*DeviceDetailResponse, error) {
-	body, err := json.Marshal(request)
-	if err != nil {
-		return nil, err
-	}
-
-	response, err := client.client.R().
-		SetHeader("Content-Type", "application/json").
-		SetBody(body).
-		SetPathParams(map[string]string{
-			"device_id": deviceId,
-		}).
-		Put("/v5/iot/{project_id}/devices/{device_id}")
-	if err != nil {
-		return nil, err
-	}
+	return client.UpdateDeviceCtx(context.Background(), deviceId, request)
+}
 
+func (client *syncClient) UpdateDeviceCtx(ctx context.Context, deviceId string, request UpdateDeviceRequest) (*DeviceDetailResponse, error) {
 	device := &DeviceDetailResponse{}
-	err = json.Unmarshal(response.Body(), device)
+	err := client.invoke(ctx, opUpdateDevice, &apiRequest{
+		pathParams: map[string]string{"device_id": deviceId},
+		body:       request,
+	}, device)
 	if err != nil {
 		return nil, err
 	}
@@ -794,18 +1176,14 @@ func (client *syncClient) UpdateDevice(deviceId string, request UpdateDeviceRequ
 }
 
 func (client *syncClient) ShowDevice(deviceId string) (*DeviceDetailResponse, error) {
-	response, err := client.client.R().
-		SetHeader("Content-Type", "application/json").
-		SetPathParams(map[string]string{
-			"device_id": deviceId,
-		}).
-		Get("/v5/iot/{project_id}/devices/{device_id}")
-	if err != nil {
-		return nil, err
-	}
+	return client.ShowDeviceCtx(context.Background(), deviceId)
+}
 
+func (client *syncClient) ShowDeviceCtx(ctx context.Context, deviceId string) (*DeviceDetailResponse, error) {
 	deviceDetail := &DeviceDetailResponse{}
-	err = json.Unmarshal(response.Body(), deviceDetail)
+	err := client.invoke(ctx, opShowDevice, &apiRequest{
+		pathParams: map[string]string{"device_id": deviceId},
+	}, deviceDetail)
 	if err != nil {
 		return nil, err
 	}
@@ -814,47 +1192,31 @@ func (client *syncClient) ShowDevice(deviceId string) (*DeviceDetailResponse, er
 }
 
 func (client *syncClient) CreateDevice(request CreateDeviceRequest) (*CreateDeviceResponse, error) {
-	bytesBody, err := json.Marshal(request)
-	if err != nil {
-		return nil, err
-	}
-	response, err := client.client.R().
-		SetHeader("Content-Type", "application/json").
-		SetBody(bytesBody).
-		Post("/v5/iot/{project_id}/devices")
-
-	if err != nil {
-		return nil, err
-	}
+	return client.CreateDeviceCtx(context.Background(), request)
+}
 
+func (client *syncClient) CreateDeviceCtx(ctx context.Context, request CreateDeviceRequest) (*CreateDeviceResponse, error) {
 	resp := &CreateDeviceResponse{}
-	err = json.Unmarshal(response.Body(), resp)
+	err := client.invoke(ctx, opCreateDevice, &apiRequest{
+		body: request,
+	}, resp)
 	if err != nil {
 		return nil, err
 	}
+
 	return resp, nil
 }
 
 func (client *syncClient) ListDevices(queryParas map[string]string) (*ListDeviceResponse, error) {
-	response, err := client.client.R().
-		SetHeader("Content-Type", "application/json").
-		SetQueryParams(queryParas).
-		Get("/v5/iot/{project_id}/devices")
-	if err != nil {
-		fmt.Println("list devices failed")
-		return nil, err
-	}
-
-	if !successResponse(response) {
-		fmt.Println("response failed")
-		return nil, err
-	}
+	return client.ListDevicesCtx(context.Background(), queryParas)
+}
 
+func (client *syncClient) ListDevicesCtx(ctx context.Context, queryParas map[string]string) (*ListDeviceResponse, error) {
 	devices := &ListDeviceResponse{}
-
-	err = json.Unmarshal(response.Body(), devices)
+	err := client.invoke(ctx, opListDevices, &apiRequest{
+		queryParams: queryParas,
+	}, devices)
 	if err != nil {
-		fmt.Println("un marshal failed")
 		return nil, err
 	}
 
@@ -862,41 +1224,31 @@ func (client *syncClient) ListDevices(queryParas map[string]string) (*ListDevice
 }
 
 func (client *syncClient) UpdateDeviceProperties(deviceId string, services interface{}) (bool, error) {
-	response, err := client.client.R().
-		SetHeader("Content-Type", "application/json").
-		SetPathParams(map[string]string{
-			"device_id": deviceId,
-		}).
-		SetBody(services).
-		Put("/v5/iot/{project_id}/devices/{device_id}/properties")
+	return client.UpdateDevicePropertiesCtx(context.Background(), deviceId, services)
+}
 
+func (client *syncClient) UpdateDevicePropertiesCtx(ctx context.Context, deviceId string, services interface{}) (bool, error) {
+	err := client.invoke(ctx, opUpdateDeviceProperties, &apiRequest{
+		pathParams: map[string]string{"device_id": deviceId},
+		body:       services,
+	}, nil)
 	if err != nil {
-		return false, nil
+		return false, err
 	}
 
-	return response.StatusCode() == 200, nil
+	return true, nil
 }
 
 func (client *syncClient) QueryDeviceProperties(deviceId, serviceId string) (interface{}, error) {
-	httpResponse, err := client.client.R().
-		SetHeader("Content-Type", "application/json").
-		SetQueryParam("service_id", serviceId).
-		SetPathParams(map[string]string{
-			"device_id": deviceId,
-		}).
-		Get("/v5/iot/{project_id}/devices/{device_id}/properties")
-
-	if err != nil {
-		return nil, err
-	}
-
-	if httpResponse.StatusCode() != 200 {
-		return nil, convertResponseToApplicationError(httpResponse)
-	}
+	return client.QueryDevicePropertiesCtx(context.Background(), deviceId, serviceId)
+}
 
+func (client *syncClient) QueryDevicePropertiesCtx(ctx context.Context, deviceId, serviceId string) (interface{}, error) {
 	var response interface{}
-
-	err = json.Unmarshal(httpResponse.Body(), response)
+	err := client.invoke(ctx, opQueryDeviceProperties, &apiRequest{
+		pathParams:  map[string]string{"device_id": deviceId},
+		queryParams: map[string]string{"service_id": serviceId},
+	}, &response)
 	if err != nil {
 		return nil, err
 	}
@@ -905,23 +1257,15 @@ func (client *syncClient) QueryDeviceProperties(deviceId, serviceId string) (int
 }
 
 func (client *syncClient) SendDeviceSyncCommand(deviceId string, request DeviceSyncCommandRequest) (*DeviceSyncCommandResponse, error) {
-	reqBody, err := json.Marshal(request)
-	if err != nil {
-		return nil, err
-	}
-	response, err := client.client.R().
-		SetHeader("Content-Type", "application/json").
-		SetBody(reqBody).
-		SetPathParams(map[string]string{
-			"device_id": deviceId,
-		}).
-		Post("/v5/iot/{project_id}/devices/{device_id}/commands")
-	if err != nil {
-		return nil, err
-	}
+	return client.SendDeviceSyncCommandCtx(context.Background(), deviceId, request)
+}
 
+func (client *syncClient) SendDeviceSyncCommandCtx(ctx context.Context, deviceId string, request DeviceSyncCommandRequest) (*DeviceSyncCommandResponse, error) {
 	resp := &DeviceSyncCommandResponse{}
-	err = json.Unmarshal(response.Body(), resp)
+	err := client.invoke(ctx, opSendDeviceSyncCommand, &apiRequest{
+		pathParams: map[string]string{"device_id": deviceId},
+		body:       request,
+	}, resp)
 	if err != nil {
 		return nil, err
 	}
@@ -930,24 +1274,15 @@ func (client *syncClient) SendDeviceSyncCommand(deviceId string, request DeviceS
 }
 
 func (client *syncClient) SendDeviceMessage(deviceId string, msg SendDeviceMessageRequest) (*SendDeviceMessageResponse, error) {
-	reqBody, err := json.Marshal(msg)
-	if err != nil {
-		return nil, err
-	}
-	response, err := client.client.R().
-		SetHeader("Content-Type", "application/json").
-		SetBody(reqBody).
-		SetPathParams(map[string]string{
-			"device_id": deviceId,
-		}).
-		Post("/v5/iot/{project_id}/devices/{device_id}/messages")
-	if err != nil {
-		return nil, err
-	}
+	return client.SendDeviceMessageCtx(context.Background(), deviceId, msg)
+}
 
+func (client *syncClient) SendDeviceMessageCtx(ctx context.Context, deviceId string, msg SendDeviceMessageRequest) (*SendDeviceMessageResponse, error) {
 	resp := &SendDeviceMessageResponse{}
-
-	err = json.Unmarshal(response.Body(), resp)
+	err := client.invoke(ctx, opSendDeviceMessage, &apiRequest{
+		pathParams: map[string]string{"device_id": deviceId},
+		body:       msg,
+	}, resp)
 	if err != nil {
 		return nil, err
 	}
@@ -956,17 +1291,14 @@ func (client *syncClient) SendDeviceMessage(deviceId string, msg SendDeviceMessa
 }
 
 func (client *syncClient) ListDeviceMessages(deviceId string) (*DeviceMessages, error) {
-	response, err := client.client.R().
-		SetPathParams(map[string]string{
-			"device_id": deviceId,
-		}).
-		Get("/v5/iot/{project_id}/devices/{device_id}/messages")
-	if err != nil {
-		return &DeviceMessages{}, err
-	}
+	return client.ListDeviceMessagesCtx(context.Background(), deviceId)
+}
 
+func (client *syncClient) ListDeviceMessagesCtx(ctx context.Context, deviceId string) (*DeviceMessages, error) {
 	messages := &DeviceMessages{}
-	err = json.Unmarshal(response.Body(), messages)
+	err := client.invoke(ctx, opListDeviceMessages, &apiRequest{
+		pathParams: map[string]string{"device_id": deviceId},
+	}, messages)
 	if err != nil {
 		return nil, err
 	}
@@ -975,18 +1307,17 @@ func (client *syncClient) ListDeviceMessages(deviceId string) (*DeviceMessages,
 }
 
 func (client *syncClient) ShowDeviceMessage(deviceId, messageId string) (*DeviceMessage, error) {
-	response, err := client.client.R().
-		SetPathParams(map[string]string{
-			"device_id":  deviceId,
-			"message_id": messageId,
-		}).
-		Get("/v5/iot/{project_id}/devices/{device_id}/messages/{message_id}")
-	if err != nil {
-		return nil, err
-	}
+	return client.ShowDeviceMessageCtx(context.Background(), deviceId, messageId)
+}
 
+func (client *syncClient) ShowDeviceMessageCtx(ctx context.Context, deviceId, messageId string) (*DeviceMessage, error) {
 	messages := &DeviceMessage{}
-	err = json.Unmarshal(response.Body(), messages)
+	err := client.invoke(ctx, opShowDeviceMessage, &apiRequest{
+		pathParams: map[string]string{
+			"device_id":  deviceId,
+			"message_id": messageId,
+		},
+	}, messages)
 	if err != nil {
 		return nil, err
 	}
@@ -995,13 +1326,12 @@ func (client *syncClient) ShowDeviceMessage(deviceId, messageId string) (*Device
 }
 
 func (client *syncClient) ListApplications() (*Applications, error) {
-	response, err := client.client.R().Get("/v5/iot/{project_id}/apps")
-	if err != nil {
-		return nil, err
-	}
+	return client.ListApplicationsCtx(context.Background())
+}
 
+func (client *syncClient) ListApplicationsCtx(ctx context.Context) (*Applications, error) {
 	app := &Applications{}
-	err = json.Unmarshal(response.Body(), app)
+	err := client.invoke(ctx, opListApplications, nil, app)
 	if err != nil {
 		return nil, err
 	}
@@ -1010,17 +1340,14 @@ func (client *syncClient) ListApplications() (*Applications, error) {
 }
 
 func (client *syncClient) ShowApplication(appId string) (*Application, error) {
-	response, err := client.client.R().
-		SetPathParams(map[string]string{
-			"app_id": appId,
-		}).
-		Get("/v5/iot/{project_id}/apps/{app_id}")
-	if err != nil {
-		return nil, err
-	}
+	return client.ShowApplicationCtx(context.Background(), appId)
+}
 
+func (client *syncClient) ShowApplicationCtx(ctx context.Context, appId string) (*Application, error) {
 	app := &Application{}
-	err = json.Unmarshal(response.Body(), app)
+	err := client.invoke(ctx, opShowApplication, &apiRequest{
+		pathParams: map[string]string{"app_id": appId},
+	}, app)
 	if err != nil {
 		return nil, err
 	}
@@ -1029,39 +1356,29 @@ func (client *syncClient) ShowApplication(appId string) (*Application, error) {
 }
 
 func (client *syncClient) DeleteApplication(appId string) (bool, error) {
-	response, err := client.client.R().
-		SetPathParams(map[string]string{
-			"app_id": appId,
-		}).
-		Delete("/v5/iot/{project_id}/apps/{app_id}")
+	return client.DeleteApplicationCtx(context.Background(), appId)
+}
+
+func (client *syncClient) DeleteApplicationCtx(ctx context.Context, appId string) (bool, error) {
+	err := client.invoke(ctx, opDeleteApplication, &apiRequest{
+		pathParams: map[string]string{"app_id": appId},
+	}, nil)
 	if err != nil {
 		return false, err
 	}
 
-	if response.StatusCode() != 204 {
-		return false, convertResponseToApplicationError(response)
-	}
-
 	return true, nil
 }
 
 func (client *syncClient) CreateApplication(request ApplicationCreateRequest) (*Application, error) {
-	body, err := json.Marshal(request)
-	if err != nil {
-		fmt.Println("marshal application create request failed")
-		return nil, err
-	}
-
-	response, err := client.client.R().
-		SetHeader("Content-Type", "application/json").
-		SetBody(body).
-		Post("/v5/iot/{project_id}/apps")
-	if err != nil {
-		return nil, err
-	}
+	return client.CreateApplicationCtx(context.Background(), request)
+}
 
+func (client *syncClient) CreateApplicationCtx(ctx context.Context, request ApplicationCreateRequest) (*Application, error) {
 	app := &Application{}
-	err = json.Unmarshal(response.Body(), app)
+	err := client.invoke(ctx, opCreateApplication, &apiRequest{
+		body: request,
+	}, app)
 	if err != nil {
 		return nil, err
 	}
@@ -1069,39 +1386,43 @@ func (client *syncClient) CreateApplication(request ApplicationCreateRequest) (*
 	return app, nil
 }
 
+// CreateSyncIotApplicationClient 不检查配置，配置不合法时调用API返回错误，推荐使用NewClient
 func CreateSyncIotApplicationClient(options ApplicationOptions) *syncClient {
-	c := &syncClient{
+	c := &syncClient{}
+	c.options = options
+	c.credentials = options.credentialsProvider()
 
+	client, err := newRestyClient(options)
+	if err != nil {
+		c.configErr = err
+		client = resty.New()
 	}
-	c.options = options
-	c.client = resty.New()
-	if len(options.ServerAddress) > 0 {
-		c.client.SetHostURL("https://" + options.ServerAddress + ":" + strconv.Itoa(options.ServerPort))
+	c.client = client
+
+	endpoint, err := resolveEndpoint(options)
+	if err != nil {
+		c.configErr = err
 	} else {
-		c.client.SetHostURL("https://iotda.cn-north-4.myhuaweicloud.com")
+		c.client.SetHostURL(endpoint)
 	}
 
-	c.client.SetPathParams(map[string]string{
-		"project_id": options.ProjectId,
-	})
-
-	c.client.SetRetryCount(3)
+	// 由executeWithRetry根据重试策略重试，resty不再重试
+	c.retryPolicy = DefaultRetryPolicy()
+	if options.RetryPolicy != nil {
+		c.retryPolicy = options.RetryPolicy.withDefaults()
+	}
+	c.rateLimiter = newRateLimiter(options.RateLimits, options.RateLimitMode)
+	c.logger = newRedactingLogger(options.Logger)
+	c.metrics = options.MetricsRecorder
+	c.interceptors = append([]Interceptor{loggingInterceptor(c.logger)}, options.Interceptors...)
 	c.client.OnBeforeRequest(func(client *resty.Client, request *resty.Request) error {
 		if len(request.Header.Get("Content-Type")) == 0 {
-			fmt.Println("content type not exist,begin to set")
 			request.SetHeader("Content-Type", "application/json")
 		}
 
 		xSdkDate := time.Now().UTC().Format("20060102T150405Z")
 		request.SetHeader("X-Sdk-Date", xSdkDate)
 
-		if options.Credential.UseAkSk {
-			signedMsg := SignMessage(request, options.Credential.Sk, options.Credential.Ak)
-			request.SetHeader("Authorization", " "+signedMsg)
-		} else {
-			request.SetHeader("X-Auth-Token", options.Credential.Token)
-		}
-
 		if len(options.InstanceId) != 0 {
 			request.SetHeader("Instance-Id", options.InstanceId)
 		}
@@ -1109,25 +1430,43 @@ func CreateSyncIotApplicationClient(options ApplicationOptions) *syncClient {
 		return nil
 	})
 
-	go logFlush()
+	// 签名需要请求的原始字节，因此在*http.Request创建之后鉴权
+	c.client.SetPreRequestHook(func(client *resty.Client, request *http.Request) error {
+		err := c.authenticate(request)
+		if err != nil {
+			return err
+		}
+
+		c.logger.Debug("send request", "method", request.Method, "url", request.URL.String(), "header", request.Header)
+		return nil
+	})
 
 	return c
 }
 
-func logFlush() {
-	ticker := time.Tick(5 * time.Second)
-	for {
-		select {
-		case <-ticker:
-			glog.Flush()
-		}
+func (client *syncClient) authenticate(request *http.Request) error {
+	if client.credentials == nil {
+		return errors.New("credentials is not configured")
+	}
+
+	credentials, err := client.credentials.Retrieve(request.Context())
+	if err != nil {
+		return err
+	}
+
+	if !credentials.UseAkSk {
+		request.Header.Set("X-Auth-Token", credentials.Token)
+		return nil
+	}
+
+	if len(credentials.SecurityToken) != 0 {
+		request.Header.Set("X-Security-Token", credentials.SecurityToken)
 	}
-}
 
-func successResponse(response *resty.Response) bool {
-	if response.StatusCode() >= 200 && response.StatusCode() < 300 {
-		return true
+	body, err := readRequestBody(request)
+	if err != nil {
+		return err
 	}
 
-	return false
+	return NewSigner(credentials.Ak, credentials.Sk).Sign(request, body)
 }
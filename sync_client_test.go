@@ -0,0 +1,551 @@
+package iot
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// 平台收到的请求
+type stubRequest struct {
+	method string
+	path   string
+	query  url.Values
+	body   map[string]interface{}
+}
+
+// 模拟平台接口，记录收到的请求并返回设置的状态码和响应体
+type apiStub struct {
+	*httptest.Server
+	status   int
+	response string
+	requests chan stubRequest
+}
+
+func newApiStub(status int, response string) *apiStub {
+	stub := &apiStub{status: status, response: response, requests: make(chan stubRequest, 10)}
+	stub.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := stubRequest{method: r.Method, path: r.URL.Path, query: r.URL.Query()}
+		data, _ := ioutil.ReadAll(r.Body)
+		if len(data) != 0 {
+			_ = json.Unmarshal(data, &request.body)
+		}
+		stub.requests <- request
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(stub.status)
+		_, _ = w.Write([]byte(stub.response))
+	}))
+
+	return stub
+}
+
+func (s *apiStub) client() *syncClient {
+	return CreateSyncIotApplicationClient(*NewApplicationOptions().WithEndpoint(s.URL).
+		SetProjectId("project").SetToken("token").SetRetryPolicy(NoRetryPolicy()))
+}
+
+func (s *apiStub) lastRequest(t *testing.T) stubRequest {
+	t.Helper()
+
+	select {
+	case request := <-s.requests:
+		return request
+	default:
+		t.Fatal("no request is received")
+		return stubRequest{}
+	}
+}
+
+// 比较请求体，数字按照JSON解码为float64
+func assertRequestBody(t *testing.T, body map[string]interface{}, want string) {
+	t.Helper()
+
+	var expected map[string]interface{}
+	if err := json.Unmarshal([]byte(want), &expected); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(body, expected) {
+		t.Errorf("body = %v, want %s", body, want)
+	}
+}
+
+func assertRequest(t *testing.T, request stubRequest, method, path string, query url.Values) {
+	t.Helper()
+
+	if request.method != method || request.path != path {
+		t.Errorf("request = %s %s, want %s %s", request.method, request.path, method, path)
+	}
+	if len(query) == 0 && len(request.query) == 0 {
+		return
+	}
+	if !reflect.DeepEqual(request.query, query) {
+		t.Errorf("query = %v, want %v", request.query, query)
+	}
+}
+
+func TestListProducts(t *testing.T) {
+	stub := newApiStub(http.StatusOK, `{"products":[{"product_id":"product-1","name":"meter"}],"page":{"count":1,"marker":"m1"}}`)
+	defer stub.Close()
+	client := stub.client()
+
+	response, err := client.ListProducts(ListProductsRequest{AppId: "app", Limit: 20, Marker: "m0", Offset: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Products) != 1 || response.Products[0].ProductID != "product-1" || response.Page.Marker != "m1" {
+		t.Errorf("response = %+v", response)
+	}
+	assertRequest(t, stub.lastRequest(t), http.MethodGet, "/v5/iot/project/products",
+		url.Values{"app_id": {"app"}, "limit": {"20"}, "marker": {"m0"}, "offset": {"5"}})
+
+	// limit和offset超出范围时取边界值，app_id为空时不发送
+	if _, err = client.ListProducts(ListProductsRequest{Limit: 100, Offset: 1000}); err != nil {
+		t.Fatal(err)
+	}
+	assertRequest(t, stub.lastRequest(t), http.MethodGet, "/v5/iot/project/products",
+		url.Values{"limit": {"50"}, "offset": {"500"}})
+
+	if _, err = client.ListProducts(ListProductsRequest{Limit: -1, Offset: -1}); err != nil {
+		t.Fatal(err)
+	}
+	assertRequest(t, stub.lastRequest(t), http.MethodGet, "/v5/iot/project/products",
+		url.Values{"limit": {"10"}, "offset": {"0"}})
+}
+
+func TestCreateProduct(t *testing.T) {
+	stub := newApiStub(http.StatusCreated, `{"product_id":"product-1","name":"meter","device_type":"Meter"}`)
+	defer stub.Close()
+
+	response, err := stub.client().CreateProduct(CreateProductRequest{
+		Name:         "meter",
+		DeviceType:   "Meter",
+		ProtocolType: "MQTT",
+		DataFormat:   "json",
+		ServiceCapabilities: []ServiceCapability{{
+			ServiceID:   "temperature",
+			ServiceType: "temperature",
+			Properties:  []ServiceProperty{{PropertyName: "value", DataType: "decimal", Method: "R"}},
+		}},
+		AppID: "app",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.ProductID != "product-1" || response.DeviceType != "Meter" {
+		t.Errorf("response = %+v", response)
+	}
+
+	request := stub.lastRequest(t)
+	assertRequest(t, request, http.MethodPost, "/v5/iot/project/products", nil)
+	assertRequestBody(t, request.body, `{"name":"meter","device_type":"Meter","protocol_type":"MQTT","data_format":"json","app_id":"app",
+		"service_capabilities":[{"service_id":"temperature","service_type":"temperature",
+		"properties":[{"property_name":"value","data_type":"decimal","method":"R"}]}]}`)
+}
+
+func TestShowProduct(t *testing.T) {
+	stub := newApiStub(http.StatusOK, `{"product_id":"product-1","app_id":"app","name":"meter"}`)
+	defer stub.Close()
+	client := stub.client()
+
+	response, err := client.ShowProduct("product-1", "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.ProductID != "product-1" || response.AppID != "app" {
+		t.Errorf("response = %+v", response)
+	}
+	assertRequest(t, stub.lastRequest(t), http.MethodGet, "/v5/iot/project/products/product-1", url.Values{"app_id": {"app"}})
+
+	if _, err = client.ShowProduct("product-1", ""); err != nil {
+		t.Fatal(err)
+	}
+	assertRequest(t, stub.lastRequest(t), http.MethodGet, "/v5/iot/project/products/product-1", nil)
+}
+
+func TestUpdateProduct(t *testing.T) {
+	stub := newApiStub(http.StatusOK, `{"product_id":"product-1","name":"renamed"}`)
+	defer stub.Close()
+
+	response, err := stub.client().UpdateProduct("product-1", UpdateProductRequest{Name: "renamed", Description: "updated"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Name != "renamed" {
+		t.Errorf("response = %+v", response)
+	}
+
+	request := stub.lastRequest(t)
+	assertRequest(t, request, http.MethodPut, "/v5/iot/project/products/product-1", nil)
+	assertRequestBody(t, request.body, `{"name":"renamed","description":"updated"}`)
+}
+
+func TestDeleteProduct(t *testing.T) {
+	stub := newApiStub(http.StatusNoContent, "")
+	defer stub.Close()
+
+	deleted, err := stub.client().DeleteProduct("product-1", "app")
+	if err != nil || !deleted {
+		t.Fatalf("DeleteProduct() = %v, %v", deleted, err)
+	}
+	assertRequest(t, stub.lastRequest(t), http.MethodDelete, "/v5/iot/project/products/product-1", url.Values{"app_id": {"app"}})
+}
+
+// 接口调用和操作表中表示成功的状态码
+type statusCodeCase struct {
+	name   string
+	status int
+	call   func(client *syncClient) error
+}
+
+// 只有操作表中的状态码表示成功，其他状态码返回ApplicationError
+func assertSuccessStatusCodes(t *testing.T, tests []statusCodeCase) {
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := newApiStub(tt.status, `{}`)
+			defer stub.Close()
+			if tt.status == http.StatusNoContent {
+				stub.response = ""
+			}
+
+			if err := tt.call(stub.client()); err != nil {
+				t.Errorf("status %d: error = %v", tt.status, err)
+			}
+
+			stub.status = http.StatusBadRequest
+			stub.response = `{"error_code":"IOTDA.000006","error_msg":"invalid input"}`
+			err := tt.call(stub.client())
+			if ae, ok := asApplicationError(err); !ok || ae.StatusCode != http.StatusBadRequest || ae.ErrorCode != "IOTDA.000006" {
+				t.Errorf("status 400: error = %v", err)
+			}
+		})
+	}
+}
+
+func TestProductSuccessStatusCodes(t *testing.T) {
+	assertSuccessStatusCodes(t, []statusCodeCase{
+		{"ListProducts", http.StatusOK, func(client *syncClient) error {
+			_, err := client.ListProducts(ListProductsRequest{})
+			return err
+		}},
+		{"CreateProduct", http.StatusCreated, func(client *syncClient) error {
+			_, err := client.CreateProduct(CreateProductRequest{Name: "meter"})
+			return err
+		}},
+		{"ShowProduct", http.StatusOK, func(client *syncClient) error {
+			_, err := client.ShowProduct("product-1", "")
+			return err
+		}},
+		{"UpdateProduct", http.StatusOK, func(client *syncClient) error {
+			_, err := client.UpdateProduct("product-1", UpdateProductRequest{})
+			return err
+		}},
+		{"DeleteProduct", http.StatusNoContent, func(client *syncClient) error {
+			_, err := client.DeleteProduct("product-1", "")
+			return err
+		}},
+	})
+}
+
+func TestListRoutingRules(t *testing.T) {
+	stub := newApiStub(http.StatusOK, `{"rules":[{"rule_id":"rule-1","subject":{"resource":"device","event":"create"}}],"count":1,"marker":"m1"}`)
+	defer stub.Close()
+	client := stub.client()
+
+	active := false
+	response, err := client.ListRoutingRules(ListRoutingRulesRequest{
+		Resource: RuleResourceDevice,
+		Event:    RuleEventCreate,
+		AppType:  RuleAppTypeApp,
+		AppId:    "app",
+		RuleName: "rule",
+		Active:   &active,
+		Limit:    20,
+		Marker:   "m0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Rules) != 1 || response.Rules[0].Subject.Resource != RuleResourceDevice || response.Marker != "m1" {
+		t.Errorf("response = %+v", response)
+	}
+	assertRequest(t, stub.lastRequest(t), http.MethodGet, "/v5/iot/project/routing-rule/rules", url.Values{
+		"resource": {"device"}, "event": {"create"}, "app_type": {"APP"}, "app_id": {"app"}, "rule_name": {"rule"},
+		"active": {"false"}, "limit": {"20"}, "marker": {"m0"}, "offset": {"0"},
+	})
+
+	// 未设置的过滤条件不发送
+	if _, err = client.ListRoutingRules(ListRoutingRulesRequest{Limit: 100}); err != nil {
+		t.Fatal(err)
+	}
+	assertRequest(t, stub.lastRequest(t), http.MethodGet, "/v5/iot/project/routing-rule/rules",
+		url.Values{"limit": {"50"}, "offset": {"0"}})
+}
+
+func TestRoutingRule(t *testing.T) {
+	stub := newApiStub(http.StatusCreated, `{"rule_id":"rule-1","rule_name":"online","subject":{"resource":"device.status","event":"update"},"active":true}`)
+	defer stub.Close()
+	client := stub.client()
+
+	response, err := client.CreateRoutingRule(CreateRoutingRuleRequest{
+		RuleName: "online",
+		Subject:  RoutingRuleSubject{Resource: RuleResourceDeviceStatus, Event: RuleEventUpdate},
+		AppType:  RuleAppTypeGlobal,
+		Select:   "*",
+		Where:    "notify_data.body.status='ONLINE'",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.RuleID != "rule-1" || response.Subject.Event != RuleEventUpdate || !response.Active {
+		t.Errorf("response = %+v", response)
+	}
+	request := stub.lastRequest(t)
+	assertRequest(t, request, http.MethodPost, "/v5/iot/project/routing-rule/rules", nil)
+	assertRequestBody(t, request.body, `{"rule_name":"online","subject":{"resource":"device.status","event":"update"},
+		"app_type":"GLOBAL","select":"*","where":"notify_data.body.status='ONLINE'"}`)
+
+	stub.status = http.StatusOK
+	if response, err = client.ShowRoutingRule("rule-1"); err != nil || response.RuleID != "rule-1" {
+		t.Fatalf("ShowRoutingRule() = %+v, %v", response, err)
+	}
+	assertRequest(t, stub.lastRequest(t), http.MethodGet, "/v5/iot/project/routing-rule/rules/rule-1", nil)
+
+	active := false
+	if _, err = client.UpdateRoutingRule("rule-1", UpdateRoutingRuleRequest{Description: "disabled", Active: &active}); err != nil {
+		t.Fatal(err)
+	}
+	request = stub.lastRequest(t)
+	assertRequest(t, request, http.MethodPut, "/v5/iot/project/routing-rule/rules/rule-1", nil)
+	assertRequestBody(t, request.body, `{"description":"disabled","active":false}`)
+
+	stub.status = http.StatusNoContent
+	stub.response = ""
+	if deleted, err := client.DeleteRoutingRule("rule-1"); err != nil || !deleted {
+		t.Fatalf("DeleteRoutingRule() = %v, %v", deleted, err)
+	}
+	assertRequest(t, stub.lastRequest(t), http.MethodDelete, "/v5/iot/project/routing-rule/rules/rule-1", nil)
+}
+
+func TestListRuleActions(t *testing.T) {
+	stub := newApiStub(http.StatusOK, `{"actions":[{"action_id":"action-1","rule_id":"rule-1","channel":"AMQP_FORWARDING"}],"count":1,"marker":"m1"}`)
+	defer stub.Close()
+	client := stub.client()
+
+	response, err := client.ListRuleActions(ListRuleActionsRequest{
+		RuleID:  "rule-1",
+		Channel: ChannelAmqpForwarding,
+		AppType: RuleAppTypeApp,
+		AppId:   "app",
+		Offset:  10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Actions) != 1 || response.Actions[0].ActionID != "action-1" || response.Count != 1 {
+		t.Errorf("response = %+v", response)
+	}
+	assertRequest(t, stub.lastRequest(t), http.MethodGet, "/v5/iot/project/routing-rule/actions", url.Values{
+		"rule_id": {"rule-1"}, "channel": {"AMQP_FORWARDING"}, "app_type": {"APP"}, "app_id": {"app"},
+		"limit": {"10"}, "offset": {"10"},
+	})
+
+	if _, err = client.ListRuleActions(ListRuleActionsRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	assertRequest(t, stub.lastRequest(t), http.MethodGet, "/v5/iot/project/routing-rule/actions",
+		url.Values{"limit": {"10"}, "offset": {"0"}})
+}
+
+func TestRuleAction(t *testing.T) {
+	stub := newApiStub(http.StatusCreated, `{"action_id":"action-1","rule_id":"rule-1","channel":"HTTP_FORWARDING",
+		"channel_detail":{"http_forwarding":{"url":"https://example.com/push"}}}`)
+	defer stub.Close()
+	client := stub.client()
+
+	response, err := client.CreateRuleAction(CreateRuleActionRequest{
+		RuleID:        "rule-1",
+		Channel:       ChannelHttpForwarding,
+		ChannelDetail: ChannelDetail{HttpForwarding: &HttpForwarding{Url: "https://example.com/push"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.ActionID != "action-1" || response.ChannelDetail.HttpForwarding == nil ||
+		response.ChannelDetail.HttpForwarding.Url != "https://example.com/push" {
+		t.Errorf("response = %+v", response)
+	}
+	request := stub.lastRequest(t)
+	assertRequest(t, request, http.MethodPost, "/v5/iot/project/routing-rule/actions", nil)
+	assertRequestBody(t, request.body, `{"rule_id":"rule-1","channel":"HTTP_FORWARDING",
+		"channel_detail":{"http_forwarding":{"url":"https://example.com/push"}}}`)
+
+	stub.status = http.StatusOK
+	if response, err = client.ShowRuleAction("action-1"); err != nil || response.RuleID != "rule-1" {
+		t.Fatalf("ShowRuleAction() = %+v, %v", response, err)
+	}
+	assertRequest(t, stub.lastRequest(t), http.MethodGet, "/v5/iot/project/routing-rule/actions/action-1", nil)
+
+	if _, err = client.UpdateRuleAction("action-1", UpdateRuleActionRequest{
+		Channel:       ChannelAmqpForwarding,
+		ChannelDetail: ChannelDetail{AmqpForwarding: &AmqpForwarding{QueueName: "queue"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	request = stub.lastRequest(t)
+	assertRequest(t, request, http.MethodPut, "/v5/iot/project/routing-rule/actions/action-1", nil)
+	assertRequestBody(t, request.body, `{"channel":"AMQP_FORWARDING","channel_detail":{"amqp_forwarding":{"queue_name":"queue"}}}`)
+
+	stub.status = http.StatusNoContent
+	stub.response = ""
+	if deleted, err := client.DeleteRuleAction("action-1"); err != nil || !deleted {
+		t.Fatalf("DeleteRuleAction() = %v, %v", deleted, err)
+	}
+	assertRequest(t, stub.lastRequest(t), http.MethodDelete, "/v5/iot/project/routing-rule/actions/action-1", nil)
+}
+
+func TestRoutingRuleSuccessStatusCodes(t *testing.T) {
+	assertSuccessStatusCodes(t, []statusCodeCase{
+		{"ListRoutingRules", http.StatusOK, func(client *syncClient) error {
+			_, err := client.ListRoutingRules(ListRoutingRulesRequest{})
+			return err
+		}},
+		{"CreateRoutingRule", http.StatusCreated, func(client *syncClient) error {
+			_, err := client.CreateRoutingRule(CreateRoutingRuleRequest{})
+			return err
+		}},
+		{"ShowRoutingRule", http.StatusOK, func(client *syncClient) error {
+			_, err := client.ShowRoutingRule("rule-1")
+			return err
+		}},
+		{"UpdateRoutingRule", http.StatusOK, func(client *syncClient) error {
+			_, err := client.UpdateRoutingRule("rule-1", UpdateRoutingRuleRequest{})
+			return err
+		}},
+		{"DeleteRoutingRule", http.StatusNoContent, func(client *syncClient) error {
+			_, err := client.DeleteRoutingRule("rule-1")
+			return err
+		}},
+		{"ListRuleActions", http.StatusOK, func(client *syncClient) error {
+			_, err := client.ListRuleActions(ListRuleActionsRequest{})
+			return err
+		}},
+		{"CreateRuleAction", http.StatusCreated, func(client *syncClient) error {
+			_, err := client.CreateRuleAction(CreateRuleActionRequest{})
+			return err
+		}},
+		{"ShowRuleAction", http.StatusOK, func(client *syncClient) error {
+			_, err := client.ShowRuleAction("action-1")
+			return err
+		}},
+		{"UpdateRuleAction", http.StatusOK, func(client *syncClient) error {
+			_, err := client.UpdateRuleAction("action-1", UpdateRuleActionRequest{})
+			return err
+		}},
+		{"DeleteRuleAction", http.StatusNoContent, func(client *syncClient) error {
+			_, err := client.DeleteRuleAction("action-1")
+			return err
+		}},
+	})
+}
+
+func TestSendDeviceAsyncCommand(t *testing.T) {
+	stub := newApiStub(http.StatusOK, `{"device_id":"device-1","command_id":"command-1","status":"PENDING","send_strategy":"immediately"}`)
+	defer stub.Close()
+	client := stub.client()
+
+	// 未设置下发策略时立即下发
+	response, err := client.SendDeviceAsyncCommand("device-1", DeviceAsyncCommandRequest{
+		ServiceId:   "switch",
+		CommandName: "on",
+		Paras:       map[string]interface{}{"value": true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.CommandId != "command-1" || response.Status != AsyncCommandPending {
+		t.Errorf("response = %+v", response)
+	}
+	request := stub.lastRequest(t)
+	assertRequest(t, request, http.MethodPost, "/v5/iot/project/devices/device-1/async-commands", nil)
+	assertRequestBody(t, request.body, `{"service_id":"switch","command_name":"on","paras":{"value":true},"send_strategy":"immediately"}`)
+
+	// 设置的下发策略不被覆盖
+	if _, err = client.SendDeviceAsyncCommand("device-1", DeviceAsyncCommandRequest{
+		Paras:        map[string]interface{}{},
+		ExpireTime:   3600,
+		SendStrategy: SendStrategyDelay,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	assertRequestBody(t, stub.lastRequest(t).body, `{"paras":{},"expire_time":3600,"send_strategy":"delay"}`)
+}
+
+func TestShowDeviceAsyncCommand(t *testing.T) {
+	stub := newApiStub(http.StatusOK, `{"device_id":"device-1","command_id":"command-1","status":"SUCCESSFUL","result":{"code":0}}`)
+	defer stub.Close()
+
+	response, err := stub.client().ShowDeviceAsyncCommand("device-1", "command-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.CommandId != "command-1" || response.Status != AsyncCommandSuccessful || response.Result == nil {
+		t.Errorf("response = %+v", response)
+	}
+	assertRequest(t, stub.lastRequest(t), http.MethodGet, "/v5/iot/project/devices/device-1/async-commands/command-1", nil)
+}
+
+func TestListDeviceAsyncCommands(t *testing.T) {
+	stub := newApiStub(http.StatusOK, `{"commands":[{"command_id":"command-1","status":"FAILED"}],"page":{"count":1,"marker":"m1"}}`)
+	defer stub.Close()
+	client := stub.client()
+
+	response, err := client.ListDeviceAsyncCommands("device-1", ListDeviceAsyncCommandsRequest{
+		StartTime:   "20230101T000000Z",
+		EndTime:     "20230102T000000Z",
+		Status:      AsyncCommandFailed,
+		CommandName: "on",
+		Limit:       30,
+		Marker:      "m0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Commands) != 1 || response.Commands[0].CommandId != "command-1" || response.Page.Marker != "m1" {
+		t.Errorf("response = %+v", response)
+	}
+	assertRequest(t, stub.lastRequest(t), http.MethodGet, "/v5/iot/project/devices/device-1/async-commands-history", url.Values{
+		"start_time": {"20230101T000000Z"}, "end_time": {"20230102T000000Z"}, "status": {"FAILED"}, "command_name": {"on"},
+		"limit": {"30"}, "marker": {"m0"}, "offset": {"0"},
+	})
+
+	if _, err = client.ListDeviceAsyncCommands("device-1", ListDeviceAsyncCommandsRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	assertRequest(t, stub.lastRequest(t), http.MethodGet, "/v5/iot/project/devices/device-1/async-commands-history",
+		url.Values{"limit": {"10"}, "offset": {"0"}})
+}
+
+func TestDeviceAsyncCommandSuccessStatusCodes(t *testing.T) {
+	send := func(client *syncClient) error {
+		_, err := client.SendDeviceAsyncCommand("device-1", DeviceAsyncCommandRequest{})
+		return err
+	}
+
+	assertSuccessStatusCodes(t, []statusCodeCase{
+		{"SendDeviceAsyncCommand 200", http.StatusOK, send},
+		{"SendDeviceAsyncCommand 201", http.StatusCreated, send},
+		{"ShowDeviceAsyncCommand", http.StatusOK, func(client *syncClient) error {
+			_, err := client.ShowDeviceAsyncCommand("device-1", "command-1")
+			return err
+		}},
+		{"ListDeviceAsyncCommands", http.StatusOK, func(client *syncClient) error {
+			_, err := client.ListDeviceAsyncCommands("device-1", ListDeviceAsyncCommandsRequest{})
+			return err
+		}},
+	})
+}
@@ -3,21 +3,46 @@ package iot
 import (
 	"encoding/json"
 	"github.com/go-resty/resty/v2"
+	"net/http"
+	"strconv"
+	"strings"
 )
 
 func convertResponseToApplicationError(response *resty.Response) error {
-	are := &ApplicationResponseError{}
-
-	err := json.Unmarshal(response.Body(), are)
-	if err != nil {
-		return err
+	method, endpoint := "", ""
+	if response.Request != nil {
+		method = response.Request.Method
+		endpoint = response.Request.URL
+		if response.Request.RawRequest != nil {
+			endpoint = response.Request.RawRequest.URL.String()
+		}
 	}
 
+	return newApplicationError(response.StatusCode(), response.Header(), response.Body(), method, endpoint)
+}
+
+func newApplicationError(statusCode int, header http.Header, body []byte, method, endpoint string) error {
 	ae := &ApplicationError{
-		ErrorMsg:  are.ErrorMsg,
-		ErrorCode: are.ErrorCode,
+		StatusCode: statusCode,
+		RequestID:  header.Get("X-Request-Id"),
+		Method:     method,
+		Endpoint:   endpoint,
 	}
 
+	are := &ApplicationResponseError{}
+	err := json.Unmarshal(body, are)
+	if err != nil || len(are.ErrorCode) == 0 && len(are.ErrorMsg) == 0 {
+		// 网关或负载均衡返回的错误可能不是JSON格式，直接使用响应体作为错误信息
+		ae.ErrorMsg = strings.TrimSpace(string(body))
+		if len(ae.ErrorMsg) == 0 {
+			ae.ErrorMsg = strconv.Itoa(statusCode) + " " + http.StatusText(statusCode)
+		}
+		return ae
+	}
+
+	ae.ErrorCode = are.ErrorCode
+	ae.ErrorMsg = are.ErrorMsg
+
 	return ae
 }
 
@@ -0,0 +1,127 @@
+package iot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewApplicationError(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-Request-Id", "request-1")
+
+	tests := []struct {
+		name     string
+		status   int
+		header   http.Header
+		body     string
+		expected *ApplicationError
+	}{
+		{
+			name:   "platform error",
+			status: http.StatusNotFound,
+			header: header,
+			body:   `{"error_code":"IOTDA.014000","error_msg":"device not found"}`,
+			expected: &ApplicationError{ErrorCode: "IOTDA.014000", ErrorMsg: "device not found", StatusCode: http.StatusNotFound,
+				RequestID: "request-1", Method: http.MethodGet, Endpoint: "https://iot/v5/iot/project/devices/device-1"},
+		},
+		{
+			name:   "not json",
+			status: http.StatusBadGateway,
+			header: http.Header{},
+			body:   "  <html>bad gateway</html>\n",
+			expected: &ApplicationError{ErrorMsg: "<html>bad gateway</html>", StatusCode: http.StatusBadGateway,
+				Method: http.MethodGet, Endpoint: "https://iot/v5/iot/project/devices/device-1"},
+		},
+		{
+			name:   "empty body",
+			status: http.StatusServiceUnavailable,
+			header: header,
+			expected: &ApplicationError{ErrorMsg: "503 Service Unavailable", StatusCode: http.StatusServiceUnavailable,
+				RequestID: "request-1", Method: http.MethodGet, Endpoint: "https://iot/v5/iot/project/devices/device-1"},
+		},
+		{
+			name:   "json without error code",
+			status: http.StatusForbidden,
+			header: http.Header{},
+			body:   `{"message":"forbidden"}`,
+			expected: &ApplicationError{ErrorMsg: `{"message":"forbidden"}`, StatusCode: http.StatusForbidden,
+				Method: http.MethodGet, Endpoint: "https://iot/v5/iot/project/devices/device-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newApplicationError(tt.status, tt.header, []byte(tt.body), http.MethodGet, "https://iot/v5/iot/project/devices/device-1")
+			if !reflect.DeepEqual(err, tt.expected) {
+				t.Errorf("newApplicationError() = %#v, want %#v", err, tt.expected)
+			}
+		})
+	}
+}
+
+// 非成功状态码不会被解析成成功的响应
+func TestNonSuccessStatusCode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "request-1")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error_code":"IOTDA.000006","error_msg":"invalid input"}`))
+	}))
+	defer server.Close()
+
+	client := CreateSyncIotApplicationClient(*NewApplicationOptions().WithEndpoint(server.URL).
+		SetProjectId("project").SetToken("token").SetRetryPolicy(NoRetryPolicy()))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		call   func() (interface{}, error)
+	}{
+		{"ListDevices", http.MethodGet, "/v5/iot/project/devices", func() (interface{}, error) {
+			return client.ListDevices(nil)
+		}},
+		{"ResetDeviceSecret", http.MethodPost, "/v5/iot/project/devices/device-1/action", func() (interface{}, error) {
+			return client.ResetDeviceSecret("device-1", "secret", true)
+		}},
+		{"ListDeviceMessages", http.MethodGet, "/v5/iot/project/devices/device-1/messages", func() (interface{}, error) {
+			return client.ListDeviceMessages("device-1")
+		}},
+		{"ShowDeviceMessage", http.MethodGet, "/v5/iot/project/devices/device-1/messages/message-1", func() (interface{}, error) {
+			return client.ShowDeviceMessage("device-1", "message-1")
+		}},
+		{"SendDeviceMessage", http.MethodPost, "/v5/iot/project/devices/device-1/messages", func() (interface{}, error) {
+			return client.SendDeviceMessage("device-1", SendDeviceMessageRequest{})
+		}},
+		{"SendDeviceSyncCommand", http.MethodPost, "/v5/iot/project/devices/device-1/commands", func() (interface{}, error) {
+			return client.SendDeviceSyncCommand("device-1", DeviceSyncCommandRequest{})
+		}},
+		{"UpdateDeviceProperties", http.MethodPut, "/v5/iot/project/devices/device-1/properties", func() (interface{}, error) {
+			return client.UpdateDeviceProperties("device-1", map[string]interface{}{})
+		}},
+		{"ListApplications", http.MethodGet, "/v5/iot/project/apps", func() (interface{}, error) {
+			return client.ListApplications()
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := tt.call()
+			if value := reflect.ValueOf(response); value.Kind() == reflect.Ptr && !value.IsNil() || response == true {
+				t.Errorf("response = %#v, want empty", response)
+			}
+
+			ae, ok := asApplicationError(err)
+			if !ok {
+				t.Fatalf("error = %v, want ApplicationError", err)
+			}
+			if ae.StatusCode != http.StatusBadRequest || ae.ErrorCode != "IOTDA.000006" || ae.ErrorMsg != "invalid input" ||
+				ae.RequestID != "request-1" || ae.Method != tt.method || !strings.HasPrefix(ae.Endpoint, server.URL+tt.path) {
+				t.Errorf("error = %+v", ae)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package iot
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// 签名校验失败时返回的错误
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// SecretKeyLookup 根据AK查询SK，AK不存在时返回错误
+type SecretKeyLookup func(ctx context.Context, ak string) (string, error)
+
+const (
+	defaultVerifierMaxSkew     = 15 * time.Minute
+	defaultVerifierMaxBodySize = 10 << 20
+)
+
+// Verifier 校验使用SDK-HMAC-SHA256算法签名的请求，可以用于模拟平台或者内部服务。
+// 签名的消息头必须包含host和x-sdk-date，请求携带X-Sdk-Content-Sha256时该消息头也必须参与签名
+type Verifier struct {
+	lookup SecretKeyLookup
+
+	// X-Sdk-Date与服务端时间允许的最大偏差，为0时使用15分钟
+	MaxSkew time.Duration
+	// Middleware读取请求体的最大字节数，为0时使用10MB
+	MaxBodySize int64
+
+	// 为空时使用time.Now
+	now func() time.Time
+}
+
+func NewVerifier(lookup SecretKeyLookup) (*Verifier, error) {
+	if lookup == nil {
+		return nil, errors.New("secret key lookup is nil")
+	}
+
+	return &Verifier{
+		lookup:      lookup,
+		MaxSkew:     defaultVerifierMaxSkew,
+		MaxBodySize: defaultVerifierMaxBodySize,
+		now:         time.Now,
+	}, nil
+}
+
+func (v *Verifier) SetMaxSkew(skew time.Duration) *Verifier {
+	v.MaxSkew = skew
+	return v
+}
+
+// 解析后的Authorization消息头
+type signatureAuthorization struct {
+	access        string
+	signedHeaders []string
+	signature     string
+}
+
+// Verify 校验请求的签名，body为请求体的原始字节，校验成功时返回请求使用的AK
+func (v *Verifier) Verify(request *http.Request, body []byte) (string, error) {
+	authorization, err := parseSignatureAuthorization(request.Header.Get(headerAuthorization))
+	if err != nil {
+		return "", err
+	}
+
+	for _, header := range []string{headerHost, headerSdkDateLowerCase} {
+		if !containsString(authorization.signedHeaders, header) {
+			return "", fmt.Errorf("%w: %s is not signed", ErrInvalidSignature, header)
+		}
+	}
+	// X-Sdk-Content-Sha256决定请求体是否参与签名，未签名时可以被篡改为UNSIGNED-PAYLOAD
+	if len(request.Header.Values(HeaderSdkContentSha256)) != 0 &&
+		!containsString(authorization.signedHeaders, headerSdkContentSha256LowerCase) {
+		return "", fmt.Errorf("%w: %s is not signed", ErrInvalidSignature, headerSdkContentSha256LowerCase)
+	}
+
+	date, err := time.Parse(SdkDateFormat, request.Header.Get(HeaderSdkDate))
+	if err != nil {
+		return "", fmt.Errorf("%w: malformed %s", ErrInvalidSignature, HeaderSdkDate)
+	}
+
+	now := time.Now
+	if v.now != nil {
+		now = v.now
+	}
+	maxSkew := v.MaxSkew
+	if maxSkew <= 0 {
+		maxSkew = defaultVerifierMaxSkew
+	}
+
+	skew := now().Sub(date)
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > maxSkew {
+		return "", fmt.Errorf("%w: %s is out of the allowed skew %v", ErrInvalidSignature, HeaderSdkDate, maxSkew)
+	}
+
+	if v.lookup == nil {
+		return "", fmt.Errorf("%w: secret key lookup is not configured", ErrInvalidSignature)
+	}
+	sk, err := v.lookup(request.Context(), authorization.access)
+	if err != nil {
+		return "", fmt.Errorf("%w: unknown access key %s: %v", ErrInvalidSignature, authorization.access, err)
+	}
+
+	expected := computeSignature(sk, request, body, authorization.signedHeaders)
+	if !hmac.Equal([]byte(expected), []byte(authorization.signature)) {
+		return "", fmt.Errorf("%w: signature mismatch", ErrInvalidSignature)
+	}
+
+	return authorization.access, nil
+}
+
+type verifierContextKey struct{}
+
+// AccessKeyFromContext 返回Middleware校验通过的请求使用的AK
+func AccessKeyFromContext(ctx context.Context) (string, bool) {
+	ak, ok := ctx.Value(verifierContextKey{}).(string)
+	return ak, ok
+}
+
+// Middleware 校验请求签名的http.Handler中间件，校验失败时返回401，校验通过时可以通过AccessKeyFromContext获取AK
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		maxBodySize := v.MaxBodySize
+		if maxBodySize <= 0 {
+			maxBodySize = defaultVerifierMaxBodySize
+		}
+
+		var body []byte
+		if request.Body != nil {
+			var err error
+			body, err = ioutil.ReadAll(io.LimitReader(request.Body, maxBodySize+1))
+			if err != nil {
+				writeVerifierError(w, http.StatusBadRequest, "APIGW.0201", "read request body failed")
+				return
+			}
+			if int64(len(body)) > maxBodySize {
+				writeVerifierError(w, http.StatusRequestEntityTooLarge, "APIGW.0201", "request body too large")
+				return
+			}
+			request.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+
+		ak, err := v.Verify(request, body)
+		if err != nil {
+			writeVerifierError(w, http.StatusUnauthorized, ErrIamAuthFailed.ErrorCode, err.Error())
+			return
+		}
+
+		next.ServeHTTP(w, request.WithContext(context.WithValue(request.Context(), verifierContextKey{}, ak)))
+	})
+}
+
+// 错误响应与平台的格式一致，便于客户端使用ApplicationError处理
+func writeVerifierError(w http.ResponseWriter, statusCode int, errorCode, errorMsg string) {
+	body, _ := json.Marshal(&ApplicationResponseError{
+		ErrorCode: errorCode,
+		ErrorMsg:  errorMsg,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(body)
+}
+
+// 解析Authorization消息头：SDK-HMAC-SHA256 Access=${ak}, SignedHeaders=${headers}, Signature=${signature}
+func parseSignatureAuthorization(value string) (*signatureAuthorization, error) {
+	value = strings.TrimSpace(value)
+	if len(value) == 0 {
+		return nil, fmt.Errorf("%w: missing %s", ErrInvalidSignature, headerAuthorization)
+	}
+	if !strings.HasPrefix(value, SignAlgorithm+" ") {
+		return nil, fmt.Errorf("%w: unsupported algorithm", ErrInvalidSignature)
+	}
+
+	authorization := &signatureAuthorization{}
+	for _, param := range strings.Split(strings.TrimPrefix(value, SignAlgorithm+" "), ",") {
+		pair := strings.SplitN(strings.TrimSpace(param), "=", 2)
+		if len(pair) != 2 {
+			return nil, fmt.Errorf("%w: malformed %s", ErrInvalidSignature, headerAuthorization)
+		}
+
+		switch pair[0] {
+		case "Access":
+			authorization.access = pair[1]
+		case "SignedHeaders":
+			authorization.signedHeaders = strings.Split(strings.ToLower(pair[1]), ";")
+		case "Signature":
+			authorization.signature = strings.ToLower(pair[1])
+		}
+	}
+
+	if len(authorization.access) == 0 || len(authorization.signedHeaders) == 0 || len(authorization.signature) == 0 {
+		return nil, fmt.Errorf("%w: incomplete %s", ErrInvalidSignature, headerAuthorization)
+	}
+
+	return authorization, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
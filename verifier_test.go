@@ -0,0 +1,250 @@
+package iot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSignTime = time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func newTestVerifier(t *testing.T) *Verifier {
+	t.Helper()
+
+	verifier, err := NewVerifier(func(ctx context.Context, ak string) (string, error) {
+		if ak != "ak" {
+			return "", errors.New("not found")
+		}
+		return "sk", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier.now = func() time.Time {
+		return testSignTime
+	}
+
+	return verifier
+}
+
+func newSignedRequest(t *testing.T, body string, modify func(request *http.Request)) *http.Request {
+	t.Helper()
+
+	request := httptest.NewRequest(http.MethodPost, "https://iotda.example.com/v5/iot/project/devices?limit=10&marker=m", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(HeaderSdkDate, testSignTime.Format(SdkDateFormat))
+	if modify != nil {
+		modify(request)
+	}
+
+	if err := NewSigner("ak", "sk").Sign(request, []byte(body)); err != nil {
+		t.Fatal(err)
+	}
+
+	return request
+}
+
+// 使用指定的SignedHeaders签名，用于构造Signer不会生成的请求
+func signWithHeaders(request *http.Request, body []byte, signedHeaders []string) {
+	request.Header.Set(headerAuthorization, fmt.Sprintf("%s Access=ak, SignedHeaders=%s, Signature=%s",
+		SignAlgorithm, strings.Join(signedHeaders, ";"), computeSignature("sk", request, body, signedHeaders)))
+}
+
+func TestNewVerifierNilLookup(t *testing.T) {
+	if _, err := NewVerifier(nil); err == nil {
+		t.Error("NewVerifier(nil) should return error")
+	}
+}
+
+func TestZeroValueVerifier(t *testing.T) {
+	request := newSignedRequest(t, "", func(request *http.Request) {
+		request.Header.Set(HeaderSdkDate, time.Now().UTC().Format(SdkDateFormat))
+	})
+
+	if _, err := (&Verifier{}).Verify(request, nil); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify() error = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	const body = `{"device_name":"test"}`
+
+	tests := []struct {
+		name    string
+		request func(t *testing.T) (*http.Request, []byte)
+		wantErr bool
+	}{
+		{"valid", func(t *testing.T) (*http.Request, []byte) {
+			return newSignedRequest(t, body, nil), []byte(body)
+		}, false},
+		{"skew within limit", func(t *testing.T) (*http.Request, []byte) {
+			return newSignedRequest(t, body, func(request *http.Request) {
+				request.Header.Set(HeaderSdkDate, testSignTime.Add(14*time.Minute).Format(SdkDateFormat))
+			}), []byte(body)
+		}, false},
+		{"date too old", func(t *testing.T) (*http.Request, []byte) {
+			return newSignedRequest(t, body, func(request *http.Request) {
+				request.Header.Set(HeaderSdkDate, testSignTime.Add(-16*time.Minute).Format(SdkDateFormat))
+			}), []byte(body)
+		}, true},
+		{"date in future", func(t *testing.T) (*http.Request, []byte) {
+			return newSignedRequest(t, body, func(request *http.Request) {
+				request.Header.Set(HeaderSdkDate, testSignTime.Add(16*time.Minute).Format(SdkDateFormat))
+			}), []byte(body)
+		}, true},
+		{"malformed date", func(t *testing.T) (*http.Request, []byte) {
+			return newSignedRequest(t, body, func(request *http.Request) {
+				request.Header.Set(HeaderSdkDate, "2021-01-02")
+			}), []byte(body)
+		}, true},
+		{"tampered body", func(t *testing.T) (*http.Request, []byte) {
+			return newSignedRequest(t, body, nil), []byte(`{"device_name":"other"}`)
+		}, true},
+		{"tampered query", func(t *testing.T) (*http.Request, []byte) {
+			request := newSignedRequest(t, body, nil)
+			request.URL.RawQuery = "limit=50&marker=m"
+			return request, []byte(body)
+		}, true},
+		{"tampered path", func(t *testing.T) (*http.Request, []byte) {
+			request := newSignedRequest(t, body, nil)
+			request.URL.Path = "/v5/iot/other/devices"
+			return request, []byte(body)
+		}, true},
+		{"tampered header", func(t *testing.T) (*http.Request, []byte) {
+			request := newSignedRequest(t, body, nil)
+			request.Header.Set("Content-Type", "text/plain")
+			return request, []byte(body)
+		}, true},
+		{"tampered host", func(t *testing.T) (*http.Request, []byte) {
+			request := newSignedRequest(t, body, nil)
+			request.Host = "evil.example.com"
+			return request, []byte(body)
+		}, true},
+		{"unsigned header may change", func(t *testing.T) (*http.Request, []byte) {
+			request := newSignedRequest(t, body, nil)
+			request.Header.Set("User-Agent", "other")
+			return request, []byte(body)
+		}, false},
+		{"unknown access key", func(t *testing.T) (*http.Request, []byte) {
+			request := httptest.NewRequest(http.MethodGet, "https://iotda.example.com/", nil)
+			request.Header.Set(HeaderSdkDate, testSignTime.Format(SdkDateFormat))
+			_ = NewSigner("other", "sk").Sign(request, nil)
+			return request, nil
+		}, true},
+		{"missing authorization", func(t *testing.T) (*http.Request, []byte) {
+			request := newSignedRequest(t, body, nil)
+			request.Header.Del(headerAuthorization)
+			return request, []byte(body)
+		}, true},
+		{"host not signed", func(t *testing.T) (*http.Request, []byte) {
+			request := newSignedRequest(t, body, nil)
+			signWithHeaders(request, []byte(body), []string{"content-type", headerSdkDateLowerCase})
+			return request, []byte(body)
+		}, true},
+		{"date not signed", func(t *testing.T) (*http.Request, []byte) {
+			request := newSignedRequest(t, body, nil)
+			signWithHeaders(request, []byte(body), []string{headerHost})
+			return request, []byte(body)
+		}, true},
+		{"signed unsigned payload ignores body", func(t *testing.T) (*http.Request, []byte) {
+			return newSignedRequest(t, body, func(request *http.Request) {
+				request.Header.Set(HeaderSdkContentSha256, UnsignedPayload)
+			}), []byte(`{"device_name":"other"}`)
+		}, false},
+		{"unsigned payload header not signed", func(t *testing.T) (*http.Request, []byte) {
+			request := newSignedRequest(t, body, nil)
+			request.Header.Set(HeaderSdkContentSha256, UnsignedPayload)
+			signWithHeaders(request, []byte(body), []string{headerHost, headerSdkDateLowerCase})
+			return request, []byte(`{"device_name":"other"}`)
+		}, true},
+		{"unsigned payload header added after signing", func(t *testing.T) (*http.Request, []byte) {
+			request := newSignedRequest(t, body, nil)
+			request.Header.Set(HeaderSdkContentSha256, UnsignedPayload)
+			return request, []byte(`{"device_name":"other"}`)
+		}, true},
+	}
+
+	verifier := newTestVerifier(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, body := tt.request(t)
+			ak, err := verifier.Verify(request, body)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidSignature) {
+					t.Errorf("Verify() error = %v, want ErrInvalidSignature", err)
+				}
+				return
+			}
+			if err != nil || ak != "ak" {
+				t.Errorf("Verify() = %s, %v, want ak", ak, err)
+			}
+		})
+	}
+}
+
+func TestVerifierMiddleware(t *testing.T) {
+	const body = `{"device_name":"test"}`
+
+	verifier := newTestVerifier(t)
+	handler := verifier.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ak, _ := AccessKeyFromContext(r.Context())
+		data := new(bytes.Buffer)
+		_, _ = data.ReadFrom(r.Body)
+		_, _ = fmt.Fprintf(w, "%s %s", ak, data)
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, newSignedRequest(t, body, nil))
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "ak "+body {
+		t.Errorf("response = %d %s", recorder.Code, recorder.Body)
+	}
+
+	tests := []struct {
+		name       string
+		request    *http.Request
+		wantStatus int
+		wantCode   string
+		wantMsg    string
+	}{
+		{"missing authorization", httptest.NewRequest(http.MethodGet, "/", nil),
+			http.StatusUnauthorized, "APIGW.0301", "missing Authorization"},
+		{"tampered body", func() *http.Request {
+			request := newSignedRequest(t, body, nil)
+			request.Body = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}")).Body
+			return request
+		}(), http.StatusUnauthorized, "APIGW.0301", "signature mismatch"},
+		{"expired", newSignedRequest(t, body, func(request *http.Request) {
+			request.Header.Set(HeaderSdkDate, testSignTime.Add(-time.Hour).Format(SdkDateFormat))
+		}), http.StatusUnauthorized, "APIGW.0301", "out of the allowed skew"},
+		{"body too large", httptest.NewRequest(http.MethodPost, "/", strings.NewReader(strings.Repeat("a", 11<<20))),
+			http.StatusRequestEntityTooLarge, "APIGW.0201", "too large"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, tt.request)
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if contentType := recorder.Header().Get("Content-Type"); contentType != "application/json" {
+				t.Errorf("Content-Type = %s", contentType)
+			}
+
+			responseError := &ApplicationResponseError{}
+			if err := json.Unmarshal(recorder.Body.Bytes(), responseError); err != nil {
+				t.Fatal(err)
+			}
+			if responseError.ErrorCode != tt.wantCode || !strings.Contains(responseError.ErrorMsg, tt.wantMsg) {
+				t.Errorf("response = %+v, want %s %s", responseError, tt.wantCode, tt.wantMsg)
+			}
+		})
+	}
+}